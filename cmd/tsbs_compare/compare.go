@@ -0,0 +1,336 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats of the comparison
+const (
+	formatText     = "text"
+	formatMarkdown = "markdown"
+)
+
+// resultsFile is the part of the results file of a load or query run that is compared
+type resultsFile struct {
+	RunnerConfig map[string]interface{} `json:"RunnerConfig"`
+	Totals       map[string]interface{} `json:"Totals"`
+}
+
+// measure is a value of a run compared with the other runs
+type measure struct {
+	// group is the query label or the load configuration the value is of
+	group string
+	name  string
+	value float64
+	// higherIsBetter is set for the throughputs, lower latencies and error rates are better
+	higherIsBetter bool
+}
+
+// key identifies the measure across the runs
+func (m measure) key() string {
+	return m.group + "\x00" + m.name
+}
+
+// readMeasures returns the measures of a results file written by a loader
+// (--results-file) or a query runner (--results-file)
+func readMeasures(r io.Reader) ([]measure, error) {
+	var res resultsFile
+	if err := json.NewDecoder(r).Decode(&res); err != nil {
+		return nil, err
+	}
+	totals := res.Totals
+	switch {
+	case totals["overallQuantiles"] != nil:
+		return queryMeasures(totals), nil
+	case totals["workersSweep"] != nil:
+		return sweepMeasures(totals), nil
+	case totals["steps"] != nil:
+		return saturationMeasures(res.RunnerConfig, totals), nil
+	case totals["load"] != nil && totals["queries"] != nil:
+		return mixedMeasures(res.RunnerConfig, totals), nil
+	case totals["metricRate"] != nil:
+		return loadMeasures(loadGroup(res.RunnerConfig), totals), nil
+	default:
+		return nil, fmt.Errorf("unknown results: no query, workers sweep, load, mixed or saturation totals")
+	}
+}
+
+// queryMeasures returns the throughput, latencies and error rate of every
+// query label of a query run
+func queryMeasures(totals map[string]interface{}) []measure {
+	rates := mapOf(totals["overallQueryRates"])
+	quantiles := mapOf(totals["overallQuantiles"])
+	errorRates := mapOf(totals["errorRates"])
+	var measures []measure
+	for _, label := range sortedKeys(quantiles) {
+		if rate, ok := floatOf(rates[label]); ok {
+			measures = append(measures, measure{group: label, name: "queries/sec", value: rate, higherIsBetter: true})
+		}
+		measures = appendLatencies(measures, label, mapOf(quantiles[label]), "q50", "q95", "q99")
+		if rate, ok := floatOf(errorRates[label]); ok {
+			measures = append(measures, measure{group: label, name: "errors %", value: rate * 100})
+		}
+	}
+	return measures
+}
+
+// sweepMeasures returns the query rate and the latencies of every label at
+// every level of a workers sweep
+func sweepMeasures(totals map[string]interface{}) []measure {
+	levels, _ := totals["workersSweep"].([]interface{})
+	var measures []measure
+	for _, l := range levels {
+		level := mapOf(l)
+		group := fmt.Sprintf("workers=%v", level["workers"])
+		if rate, ok := floatOf(level["queryRate"]); ok {
+			measures = append(measures, measure{group: group, name: "queries/sec", value: rate, higherIsBetter: true})
+		}
+		measures = append(measures, labelMeasures(group+": ", mapOf(level["labels"]))...)
+	}
+	return measures
+}
+
+// loadMeasures returns the throughputs and batch latencies of a load, in the group
+func loadMeasures(group string, totals map[string]interface{}) []measure {
+	var measures []measure
+	if rate, ok := floatOf(totals["metricRate"]); ok {
+		measures = append(measures, measure{group: group, name: "metrics/sec", value: rate, higherIsBetter: true})
+	}
+	if rate, ok := floatOf(totals["rowRate"]); ok && rate > 0 {
+		measures = append(measures, measure{group: group, name: "rows/sec", value: rate, higherIsBetter: true})
+	}
+	return appendLatencies(measures, group, mapOf(totals["batchLatency"]), "p50", "p99")
+}
+
+// mixedMeasures returns the measures of the load and of the queries run under load
+func mixedMeasures(config, totals map[string]interface{}) []measure {
+	measures := loadMeasures(loadGroup(config), mapOf(totals["load"]))
+	queries := mapOf(totals["queries"])
+	if rate, ok := floatOf(queries["queryRate"]); ok {
+		measures = append(measures, measure{group: "under load", name: "queries/sec", value: rate, higherIsBetter: true})
+	}
+	return append(measures, labelMeasures("under load: ", mapOf(queries["labels"]))...)
+}
+
+// labelMeasures returns the latencies and error rate of the label stats of
+// every label, in groups of the label with the prefix
+func labelMeasures(prefix string, labels map[string]interface{}) []measure {
+	var measures []measure
+	for _, label := range sortedKeys(labels) {
+		group := prefix + label
+		stats := mapOf(labels[label])
+		measures = appendLatencies(measures, group, stats, "p50", "p95", "p99")
+		if rate, ok := floatOf(stats["errorRate"]); ok {
+			measures = append(measures, measure{group: group, name: "errors %", value: rate * 100})
+		}
+	}
+	return measures
+}
+
+// saturationMeasures returns the measures of every step of a saturation search
+func saturationMeasures(config, totals map[string]interface{}) []measure {
+	steps, _ := totals["steps"].([]interface{})
+	var measures []measure
+	for _, s := range steps {
+		step := mapOf(s)
+		group := fmt.Sprintf("step workers=%v batch-size=%v", step["workers"], config["batch-size"])
+		if rate, ok := floatOf(step["targetRate"]); ok && rate > 0 {
+			group += fmt.Sprintf(" target-rate=%v", step["targetRate"])
+		}
+		measures = append(measures, loadMeasures(group, step)...)
+	}
+	return measures
+}
+
+// loadGroup describes the configuration of a load, the loads are compared
+// with the loads of the same configuration only
+func loadGroup(config map[string]interface{}) string {
+	group := fmt.Sprintf("load workers=%v batch-size=%v", config["workers"], config["batch-size"])
+	if rate, ok := floatOf(config["target-rate"]); ok && rate > 0 {
+		group += fmt.Sprintf(" target-rate=%v %v", config["target-rate"], config["target-rate-unit"])
+	}
+	return group
+}
+
+// appendLatencies appends the latencies of the quantiles, in milliseconds
+func appendLatencies(measures []measure, group string, latencies map[string]interface{}, quantiles ...string) []measure {
+	for _, q := range quantiles {
+		if value, ok := floatOf(latencies[q]); ok {
+			name := "p" + strings.TrimPrefix(strings.TrimPrefix(q, "q"), "p") + " ms"
+			measures = append(measures, measure{group: group, name: name, value: value})
+		}
+	}
+	return measures
+}
+
+func mapOf(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func floatOf(v interface{}) (float64, bool) {
+	f, ok := v.(float64)
+	return f, ok
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// delta is the value of a measure in a candidate run, compared with the baseline
+type delta struct {
+	value   float64
+	missing bool
+	// change is the relative change from the baseline in percent, NaN when it
+	// cannot be computed (the measure is missing, or 0 in the baseline only)
+	change     float64
+	regression bool
+}
+
+// comparison is a measure in the baseline and in every candidate run
+type comparison struct {
+	measure
+	missing    bool
+	candidates []delta
+}
+
+// compare aligns the measures of the candidates with those of the baseline. A
+// candidate regresses when a measure is worse than in the baseline by more than
+// threshold percent, when a measure that is 0 in the baseline (e.g. the error
+// rate) gets worse, or when a measure of the baseline is missing, so that a
+// candidate whose groups match none of the baseline fails.
+func compare(baseline []measure, candidates [][]measure, threshold float64) []comparison {
+	var comparisons []comparison
+	index := make(map[string]int)
+	add := func(m measure, missing bool) int {
+		if i, ok := index[m.key()]; ok {
+			return i
+		}
+		c := comparison{measure: m, missing: missing, candidates: make([]delta, len(candidates))}
+		for i := range c.candidates {
+			c.candidates[i] = delta{missing: true, change: math.NaN()}
+		}
+		index[m.key()] = len(comparisons)
+		comparisons = append(comparisons, c)
+		return len(comparisons) - 1
+	}
+	for _, m := range baseline {
+		add(m, false)
+	}
+	for i, measures := range candidates {
+		for _, m := range measures {
+			c := &comparisons[add(m, true)]
+			d := delta{value: m.value, change: math.NaN()}
+			if !c.missing {
+				worse := m.value < c.value
+				if !c.higherIsBetter {
+					worse = m.value > c.value
+				}
+				if c.value != 0 {
+					d.change = (m.value - c.value) / math.Abs(c.value) * 100
+					d.regression = worse && math.Abs(d.change) > threshold
+				} else if m.value == 0 {
+					d.change = 0
+				} else {
+					d.regression = worse
+				}
+			}
+			c.candidates[i] = d
+		}
+	}
+	for i := range comparisons {
+		c := &comparisons[i]
+		for j := range c.candidates {
+			if !c.missing && c.candidates[j].missing {
+				c.candidates[j].regression = true
+			}
+		}
+	}
+	return comparisons
+}
+
+// regressions returns the number of regressions of the candidates
+func regressions(comparisons []comparison) int {
+	n := 0
+	for _, c := range comparisons {
+		for _, d := range c.candidates {
+			if d.regression {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// writeComparisons writes the table of the comparisons in the format, names
+// are the names of the baseline and of the candidates
+func writeComparisons(w io.Writer, format string, names []string, comparisons []comparison) error {
+	header := []string{"group", "measure", names[0]}
+	for _, name := range names[1:] {
+		header = append(header, name, "change")
+	}
+	header = append(header, "")
+	rows := [][]string{header}
+	for _, c := range comparisons {
+		row := []string{c.group, c.name, formatValue(c.value, c.missing)}
+		status := ""
+		for _, d := range c.candidates {
+			change := "-"
+			if !math.IsNaN(d.change) {
+				change = fmt.Sprintf("%+0.1f%%", d.change)
+			}
+			row = append(row, formatValue(d.value, d.missing), change)
+			if d.regression {
+				status = "REGRESSION"
+			}
+		}
+		rows = append(rows, append(row, status))
+	}
+
+	switch format {
+	case formatText:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, row := range rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	case formatMarkdown:
+		separator := make([]string, len(header))
+		for i := range separator {
+			separator[i] = "---"
+			if i >= 2 && i < len(header)-1 {
+				separator[i] = "---:"
+			}
+		}
+		rows = append(rows[:1], append([][]string{separator}, rows[1:]...)...)
+		for _, row := range rows {
+			for i := range row {
+				row[i] = strings.Replace(row[i], "|", "\\|", -1)
+			}
+			if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(row, " | ")); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format '%s', must be '%s' or '%s'", format, formatText, formatMarkdown)
+	}
+}
+
+func formatValue(value float64, missing bool) string {
+	if missing {
+		return "-"
+	}
+	return fmt.Sprintf("%0.2f", value)
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const queryResults = `{"ResultFormatVersion":"0.1","RunnerConfig":{"Workers":8},"Totals":{
+	"overallQueryRates":{"cpu-max-all-1":100,"double-groupby-1":20},
+	"overallQuantiles":{"cpu-max-all-1":{"q0":1,"q50":10,"q95":20,"q99":30,"q999":40,"q100":50},
+		"double-groupby-1":{"q0":5,"q50":50,"q95":80,"q99":90,"q999":95,"q100":99}},
+	"errorRates":{"cpu-max-all-1":0,"double-groupby-1":0}}}`
+
+const loadResults = `{"ResultFormatVersion":"0.1","RunnerConfig":{"workers":4,"batch-size":1000,"target-rate":0},"Totals":{
+	"metricRate":50000,"rowRate":5000,"retriedBatches":0,"failedBatches":0,
+	"batchLatency":{"count":100,"min":1,"mean":3,"max":9,"p50":2.5,"p90":5,"p99":8,"p999":9}}}`
+
+func TestReadMeasures(t *testing.T) {
+	cases := []struct {
+		desc    string
+		results string
+		want    []string
+	}{
+		{
+			desc:    "queries",
+			results: queryResults,
+			want: []string{
+				"cpu-max-all-1/queries/sec=100", "cpu-max-all-1/p50 ms=10", "cpu-max-all-1/p95 ms=20", "cpu-max-all-1/p99 ms=30", "cpu-max-all-1/errors %=0",
+				"double-groupby-1/queries/sec=20", "double-groupby-1/p50 ms=50", "double-groupby-1/p95 ms=80", "double-groupby-1/p99 ms=90", "double-groupby-1/errors %=0",
+			},
+		},
+		{
+			desc:    "load",
+			results: loadResults,
+			want: []string{
+				"load workers=4 batch-size=1000/metrics/sec=50000", "load workers=4 batch-size=1000/rows/sec=5000",
+				"load workers=4 batch-size=1000/p50 ms=2.5", "load workers=4 batch-size=1000/p99 ms=8",
+			},
+		},
+		{
+			desc: "mixed",
+			results: `{"RunnerConfig":{"workers":2,"batch-size":100,"target-rate":1000,"target-rate-unit":"rows"},"Totals":{
+				"load":{"metricRate":900,"rowRate":1000,"batchLatency":{"p50":1,"p99":2}},
+				"queries":{"queryRate":5,"labels":{"lastpoint":{"count":10,"p50":3,"p95":4,"p99":5,"errorRate":0.1}}}}}`,
+			want: []string{
+				"load workers=2 batch-size=100 target-rate=1000 rows/metrics/sec=900", "load workers=2 batch-size=100 target-rate=1000 rows/rows/sec=1000",
+				"load workers=2 batch-size=100 target-rate=1000 rows/p50 ms=1", "load workers=2 batch-size=100 target-rate=1000 rows/p99 ms=2",
+				"under load/queries/sec=5", "under load: lastpoint/p50 ms=3", "under load: lastpoint/p95 ms=4", "under load: lastpoint/p99 ms=5",
+				"under load: lastpoint/errors %=10",
+			},
+		},
+		{
+			desc: "workers sweep",
+			results: `{"RunnerConfig":{"Workers":1},"Totals":{"workersSweep":[
+				{"workers":1,"queryRate":10,"labels":{"all queries":{"p50":1,"p95":2,"p99":3,"errorRate":0}}},
+				{"workers":2,"queryRate":18,"labels":{"all queries":{"p50":1.5,"p95":2.5,"p99":4,"errorRate":0}}}]}}`,
+			want: []string{
+				"workers=1/queries/sec=10", "workers=1: all queries/p50 ms=1", "workers=1: all queries/p95 ms=2", "workers=1: all queries/p99 ms=3", "workers=1: all queries/errors %=0",
+				"workers=2/queries/sec=18", "workers=2: all queries/p50 ms=1.5", "workers=2: all queries/p95 ms=2.5", "workers=2: all queries/p99 ms=4", "workers=2: all queries/errors %=0",
+			},
+		},
+		{
+			desc: "saturation",
+			results: `{"RunnerConfig":{"workers":1,"batch-size":100},"Totals":{"saturation":{},"steps":[
+				{"step":1,"workers":1,"metricRate":100,"rowRate":0,"batchLatency":{"p50":1,"p99":2}},
+				{"step":2,"workers":2,"metricRate":150,"rowRate":0,"batchLatency":{"p50":2,"p99":4}}]}}`,
+			want: []string{
+				"step workers=1 batch-size=100/metrics/sec=100", "step workers=1 batch-size=100/p50 ms=1", "step workers=1 batch-size=100/p99 ms=2",
+				"step workers=2 batch-size=100/metrics/sec=150", "step workers=2 batch-size=100/p50 ms=2", "step workers=2 batch-size=100/p99 ms=4",
+			},
+		},
+	}
+	for _, c := range cases {
+		measures, err := readMeasures(strings.NewReader(c.results))
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", c.desc, err)
+		}
+		var got []string
+		for _, m := range measures {
+			got = append(got, m.group+"/"+m.name+"="+formatFloat(m.value))
+			if m.higherIsBetter != strings.HasSuffix(m.name, "/sec") {
+				t.Errorf("%s: wrong direction of %s", c.desc, m.name)
+			}
+		}
+		if strings.Join(got, "\n") != strings.Join(c.want, "\n") {
+			t.Errorf("%s: got\n%s\nwant\n%s", c.desc, strings.Join(got, "\n"), strings.Join(c.want, "\n"))
+		}
+	}
+
+	if _, err := readMeasures(strings.NewReader(`{"Totals":{"foo":1}}`)); err == nil {
+		t.Errorf("unknown results did not error")
+	}
+	if _, err := readMeasures(strings.NewReader(`not json`)); err == nil {
+		t.Errorf("invalid results did not error")
+	}
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func TestCompare(t *testing.T) {
+	baseline := []measure{
+		{group: "q", name: "queries/sec", value: 100, higherIsBetter: true},
+		{group: "q", name: "p99 ms", value: 10},
+		{group: "q", name: "errors %", value: 0},
+		{group: "old", name: "p99 ms", value: 1},
+	}
+	candidates := [][]measure{
+		{
+			{group: "q", name: "queries/sec", value: 95, higherIsBetter: true},
+			{group: "q", name: "p99 ms", value: 12},
+			{group: "q", name: "errors %", value: 0},
+			{group: "new", name: "p99 ms", value: 1},
+		},
+		{
+			{group: "q", name: "queries/sec", value: 80, higherIsBetter: true},
+			{group: "q", name: "p99 ms", value: 5},
+			{group: "q", name: "errors %", value: 1},
+		},
+	}
+	comparisons := compare(baseline, candidates, 10)
+	type want struct {
+		change     float64
+		regression bool
+		missing    bool
+	}
+	wants := [][]want{
+		{{change: -5}, {change: -20, regression: true}},
+		{{change: 20, regression: true}, {change: -50}},
+		{{change: 0}, {change: math.NaN(), regression: true}},
+		{{change: math.NaN(), missing: true, regression: true}, {change: math.NaN(), missing: true, regression: true}},
+		{{change: math.NaN()}, {change: math.NaN(), missing: true}},
+	}
+	if len(comparisons) != len(wants) {
+		t.Fatalf("got %d comparisons want %d", len(comparisons), len(wants))
+	}
+	for i, c := range comparisons {
+		for j, d := range c.candidates {
+			w := wants[i][j]
+			sameChange := d.change == w.change || (math.IsNaN(d.change) && math.IsNaN(w.change))
+			if !sameChange || d.regression != w.regression || d.missing != w.missing {
+				t.Errorf("%s %s candidate %d: got %+v want %+v", c.group, c.name, j, d, w)
+			}
+		}
+	}
+	if !comparisons[4].missing || comparisons[4].group != "new" {
+		t.Errorf("the measure missing from the baseline is not last: %+v", comparisons[4])
+	}
+	if got := regressions(comparisons); got != 5 {
+		t.Errorf("got %d regressions want 5", got)
+	}
+
+	// a candidate without any group of the baseline fails
+	comparisons = compare(baseline, [][]measure{{{group: "other", name: "p99 ms", value: 1}}}, 10)
+	if got := regressions(comparisons); got != len(baseline) {
+		t.Errorf("got %d regressions of the unmatched candidate want %d", got, len(baseline))
+	}
+}
+
+func TestWriteComparisons(t *testing.T) {
+	baseline := []measure{{group: "a|b", name: "p99 ms", value: 10}}
+	candidates := [][]measure{{{group: "a|b", name: "p99 ms", value: 12}}}
+	comparisons := compare(baseline, candidates, 10)
+
+	var buf bytes.Buffer
+	if err := writeComparisons(&buf, formatMarkdown, []string{"base.json", "cand.json"}, comparisons); err != nil {
+		t.Fatal(err)
+	}
+	want := "| group | measure | base.json | cand.json | change |  |\n" +
+		"| --- | --- | ---: | ---: | ---: | --- |\n" +
+		"| a\\|b | p99 ms | 10.00 | 12.00 | +20.0% | REGRESSION |\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := writeComparisons(&buf, formatText, []string{"base.json", "cand.json"}, comparisons); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "group  measure  base.json") ||
+		!strings.Contains(lines[1], "+20.0%  REGRESSION") {
+		t.Errorf("wrong text table:\n%s", buf.String())
+	}
+
+	if err := writeComparisons(&buf, "html", []string{"base.json", "cand.json"}, comparisons); err == nil {
+		t.Errorf("unknown format did not error")
+	}
+}
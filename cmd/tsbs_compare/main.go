@@ -0,0 +1,89 @@
+// tsbs_compare compares the results files written with --results-file by the
+// tsbs_load_* programs or by the tsbs_run_queries_* programs.
+//
+// The first file is the baseline, the others are the candidates. The queries
+// are aligned by label and the loads by configuration (workers, batch size and
+// target rate), then the throughputs and latencies of every candidate are
+// printed with their change from the baseline, as text or as a Markdown table.
+// A regression is a throughput lower or a latency higher than the baseline by
+// more than --threshold percent, or a measure of the baseline missing from a
+// candidate. It exits with 1 on regressions and with 2 on errors, to gate the
+// upgrades of a database on its benchmarks.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/internal/utils"
+)
+
+// Program option vars:
+var (
+	threshold float64
+	format    string
+	files     []string
+)
+
+// Parse args:
+func init() {
+	pflag.Float64("threshold", 10, "Change from the baseline, in percent, beyond which a worse measure is a regression")
+	pflag.String("format", formatText, "Format of the comparison: 'text' or 'markdown'")
+
+	pflag.Parse()
+
+	err := utils.SetupConfigFile()
+
+	if err != nil {
+		panic(fmt.Errorf("fatal error config file: %s", err))
+	}
+
+	threshold = viper.GetFloat64("threshold")
+	format = viper.GetString("format")
+	files = pflag.Args()
+}
+
+func main() {
+	if len(files) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: tsbs_compare [flags] baseline.json candidate.json [candidate.json...]")
+		os.Exit(2)
+	}
+	names := make([]string, len(files))
+	measures := make([][]measure, len(files))
+	for i, fileName := range files {
+		names[i] = filepath.Base(fileName)
+		m, err := readFile(fileName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			os.Exit(2)
+		}
+		measures[i] = m
+	}
+
+	comparisons := compare(measures[0], measures[1:], threshold)
+	if err := writeComparisons(os.Stdout, format, names, comparisons); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(2)
+	}
+	n := regressions(comparisons)
+	fmt.Printf("\n%d measures compared, %d regressions beyond %0.1f%%\n", len(comparisons), n, threshold)
+	if n > 0 {
+		os.Exit(1)
+	}
+}
+
+func readFile(fileName string) ([]measure, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	measures, err := readMeasures(f)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %v", fileName, err)
+	}
+	return measures, nil
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/iot"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/kubernetes"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
 	"github.com/timescale/tsbs/pkg/query"
 )
@@ -64,3 +65,19 @@ func (g *BaseGenerator) NewIoT(start, end time.Time, scale int) (utils.QueryGene
 
 	return devops, nil
 }
+
+// NewKubernetes creates a new kubernetes use case query generator.
+func (g *BaseGenerator) NewKubernetes(start, end time.Time, scale int) (utils.QueryGenerator, error) {
+	core, err := kubernetes.NewCore(start, end, scale)
+
+	if err != nil {
+		return nil, err
+	}
+
+	kubernetes := &Kubernetes{
+		BaseGenerator: g,
+		Core:          core,
+	}
+
+	return kubernetes, nil
+}
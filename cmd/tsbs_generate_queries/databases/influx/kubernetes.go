@@ -0,0 +1,73 @@
+package influx
+
+import (
+	"fmt"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/kubernetes"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// Kubernetes produces Influx-specific queries for all the kubernetes query types.
+type Kubernetes struct {
+	*kubernetes.Core
+	*BaseGenerator
+}
+
+// NamespaceCPU selects the average cpu usage of the pods of a random
+// namespace per minute over a random hour.
+func (k *Kubernetes) NamespaceCPU(qi query.Query) {
+	interval := k.Interval.MustRandWindow(kubernetes.NamespaceCPUDuration)
+	namespace := k.GetRandomNamespace()
+	influxql := fmt.Sprintf(`SELECT mean("usage_percent") 
+		FROM "container_cpu" 
+		WHERE "namespace" = '%s' AND time >= '%s' AND time < '%s' 
+		GROUP BY time(1m)`,
+		namespace,
+		interval.StartString(),
+		interval.EndString())
+
+	humanLabel := fmt.Sprintf("Influx average cpu usage of a namespace, random %s by 1m", kubernetes.NamespaceCPUDuration)
+	humanDesc := fmt.Sprintf("%s: %s %s", humanLabel, namespace, interval.StartString())
+	k.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
+
+// NamespaceMemory selects the average memory working set of the pods of each
+// deployment of a random namespace per hour over random 12 hours.
+func (k *Kubernetes) NamespaceMemory(qi query.Query) {
+	interval := k.Interval.MustRandWindow(kubernetes.NamespaceMemoryDuration)
+	namespace := k.GetRandomNamespace()
+	influxql := fmt.Sprintf(`SELECT mean("working_set_bytes") 
+		FROM "container_memory" 
+		WHERE "namespace" = '%s' AND time >= '%s' AND time < '%s' 
+		GROUP BY time(1h), "deployment"`,
+		namespace,
+		interval.StartString(),
+		interval.EndString())
+
+	humanLabel := fmt.Sprintf("Influx average memory per deployment of a namespace, random %s by 1h", kubernetes.NamespaceMemoryDuration)
+	humanDesc := fmt.Sprintf("%s: %s %s", humanLabel, namespace, interval.StartString())
+	k.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
+
+// PodChurn counts the pods that reported in each namespace per hour over
+// random 12 hours.
+func (k *Kubernetes) PodChurn(qi query.Query) {
+	interval := k.Interval.MustRandWindow(kubernetes.PodChurnDuration)
+	start := interval.StartString()
+	end := interval.EndString()
+	influxql := fmt.Sprintf(`SELECT count("usage") AS "pods" 
+		FROM (SELECT last("usage_percent") AS "usage" 
+		 FROM "container_cpu" 
+		 WHERE time >= '%s' AND time < '%s' 
+		 GROUP BY time(1h), "namespace", "pod") 
+		WHERE time >= '%s' AND time < '%s' 
+		GROUP BY time(1h), "namespace"`,
+		start,
+		end,
+		start,
+		end)
+
+	humanLabel := fmt.Sprintf("Influx pods per namespace, random %s by 1h", kubernetes.PodChurnDuration)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, start)
+	k.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
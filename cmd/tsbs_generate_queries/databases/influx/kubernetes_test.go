@@ -0,0 +1,73 @@
+package influx
+
+import (
+	"fmt"
+	"math/rand"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+func TestNamespaceCPU(t *testing.T) {
+	expectedHumanLabel := "Influx average cpu usage of a namespace, random 1h0m0s by 1m"
+	expectedHumanDesc := "Influx average cpu usage of a namespace, random 1h0m0s by 1m: kube-system 2016-01-01T20:16:22Z"
+	expectedQuery := `SELECT mean("usage_percent") 
+		FROM "container_cpu" 
+		WHERE "namespace" = 'kube-system' AND time >= '2016-01-01T20:16:22Z' AND time < '2016-01-01T21:16:22Z' 
+		GROUP BY time(1m)`
+
+	k := newTestKubernetes(t)
+	q := k.GenerateEmptyQuery()
+	k.NamespaceCPU(q)
+	verifyKubernetesQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedQuery)
+}
+
+func TestNamespaceMemory(t *testing.T) {
+	expectedHumanLabel := "Influx average memory per deployment of a namespace, random 12h0m0s by 1h"
+	expectedHumanDesc := "Influx average memory per deployment of a namespace, random 12h0m0s by 1h: kube-system 2016-01-01T06:16:22Z"
+	expectedQuery := `SELECT mean("working_set_bytes") 
+		FROM "container_memory" 
+		WHERE "namespace" = 'kube-system' AND time >= '2016-01-01T06:16:22Z' AND time < '2016-01-01T18:16:22Z' 
+		GROUP BY time(1h), "deployment"`
+
+	k := newTestKubernetes(t)
+	q := k.GenerateEmptyQuery()
+	k.NamespaceMemory(q)
+	verifyKubernetesQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedQuery)
+}
+
+func TestPodChurn(t *testing.T) {
+	expectedHumanLabel := "Influx pods per namespace, random 12h0m0s by 1h"
+	expectedHumanDesc := "Influx pods per namespace, random 12h0m0s by 1h: 2016-01-01T06:16:22Z"
+	expectedQuery := `SELECT count("usage") AS "pods" 
+		FROM (SELECT last("usage_percent") AS "usage" 
+		 FROM "container_cpu" 
+		 WHERE time >= '2016-01-01T06:16:22Z' AND time < '2016-01-01T18:16:22Z' 
+		 GROUP BY time(1h), "namespace", "pod") 
+		WHERE time >= '2016-01-01T06:16:22Z' AND time < '2016-01-01T18:16:22Z' 
+		GROUP BY time(1h), "namespace"`
+
+	k := newTestKubernetes(t)
+	q := k.GenerateEmptyQuery()
+	k.PodChurn(q)
+	verifyKubernetesQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedQuery)
+}
+
+func newTestKubernetes(t *testing.T) *Kubernetes {
+	rand.Seed(123) // Setting seed for testing purposes.
+	s := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := BaseGenerator{}
+	kq, err := b.NewKubernetes(s, s.Add(24*time.Hour), testScale)
+	if err != nil {
+		t.Fatalf("Error while creating kubernetes generator")
+	}
+	return kq.(*Kubernetes)
+}
+
+func verifyKubernetesQuery(t *testing.T, q query.Query, humanLabel, humanDesc, influxql string) {
+	v := url.Values{}
+	v.Set("q", influxql)
+	verifyQuery(t, q, humanLabel, humanDesc, fmt.Sprintf("/query?%s", v.Encode()))
+}
@@ -1,10 +1,12 @@
 package timescaledb
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/iot"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/kubernetes"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
 	"github.com/timescale/tsbs/pkg/query"
 )
@@ -23,6 +25,13 @@ func (g *BaseGenerator) GenerateEmptyQuery() query.Query {
 	return query.NewTimescaleDB()
 }
 
+func (g *BaseGenerator) getTimeBucket(seconds int) string {
+	if g.UseTimeBucket {
+		return fmt.Sprintf(timeBucketFmt, seconds)
+	}
+	return fmt.Sprintf(nonTimeBucketFmt, seconds, seconds)
+}
+
 // fillInQuery fills the query struct with data.
 func (g *BaseGenerator) fillInQuery(qi query.Query, humanLabel, humanDesc, table, sql string) {
 	q := qi.(*query.TimescaleDB)
@@ -63,3 +72,19 @@ func (g *BaseGenerator) NewIoT(start, end time.Time, scale int) (utils.QueryGene
 
 	return iot, nil
 }
+
+// NewKubernetes creates a new kubernetes use case query generator.
+func (g *BaseGenerator) NewKubernetes(start, end time.Time, scale int) (utils.QueryGenerator, error) {
+	core, err := kubernetes.NewCore(start, end, scale)
+
+	if err != nil {
+		return nil, err
+	}
+
+	kubernetes := &Kubernetes{
+		BaseGenerator: g,
+		Core:          core,
+	}
+
+	return kubernetes, nil
+}
@@ -0,0 +1,104 @@
+package timescaledb
+
+import (
+	"fmt"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/kubernetes"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// Kubernetes produces TimescaleDB-specific queries for all the kubernetes query types.
+type Kubernetes struct {
+	*kubernetes.Core
+	*BaseGenerator
+}
+
+func (k *Kubernetes) columnSelect(column string) string {
+	if k.UseJSON {
+		return fmt.Sprintf("tagset->>'%[1]s'", column)
+	}
+
+	return column
+}
+
+func (k *Kubernetes) withAlias(column string) string {
+	return fmt.Sprintf("%s AS %s", k.columnSelect(column), column)
+}
+
+// getNamespaceWhereString creates a WHERE SQL statement for the series of a
+// namespace, which spans all the pods that ever ran in it.
+func (k *Kubernetes) getNamespaceWhereString(namespace string) string {
+	if k.UseJSON {
+		return fmt.Sprintf("tags_id IN (SELECT id FROM tags WHERE tagset @> '{\"namespace\": \"%s\"}')", namespace)
+	}
+	return fmt.Sprintf("tags_id IN (SELECT id FROM tags WHERE namespace = '%s')", namespace)
+}
+
+// NamespaceCPU selects the average cpu usage of the pods of a random
+// namespace per minute over a random hour, e.g. in pseudo-SQL:
+//
+// SELECT minute, avg(usage_percent)
+// FROM container_cpu
+// WHERE namespace = '$NAMESPACE'
+// AND time >= '$HOUR_START' AND time < '$HOUR_END'
+// GROUP BY minute ORDER BY minute
+func (k *Kubernetes) NamespaceCPU(qi query.Query) {
+	interval := k.Interval.MustRandWindow(kubernetes.NamespaceCPUDuration)
+	namespace := k.GetRandomNamespace()
+
+	sql := fmt.Sprintf(`SELECT %s AS minute, avg(usage_percent) AS avg_usage_percent
+		FROM container_cpu
+		WHERE %s AND time >= '%s' AND time < '%s'
+		GROUP BY minute ORDER BY minute`,
+		k.getTimeBucket(oneMinute),
+		k.getNamespaceWhereString(namespace),
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt))
+
+	humanLabel := fmt.Sprintf("TimescaleDB average cpu usage of a namespace, random %s by 1m", kubernetes.NamespaceCPUDuration)
+	humanDesc := fmt.Sprintf("%s: %s %s", humanLabel, namespace, interval.StartString())
+	k.fillInQuery(qi, humanLabel, humanDesc, kubernetes.ContainerCPUTableName, sql)
+}
+
+// NamespaceMemory selects the average memory working set of the pods of each
+// deployment of a random namespace per hour over random 12 hours.
+func (k *Kubernetes) NamespaceMemory(qi query.Query) {
+	interval := k.Interval.MustRandWindow(kubernetes.NamespaceMemoryDuration)
+	namespace, deployment := "namespace", "deployment"
+	namespaceValue := k.GetRandomNamespace()
+
+	sql := fmt.Sprintf(`SELECT %s AS hour, t.%s, avg(m.working_set_bytes) AS avg_working_set_bytes
+		FROM container_memory m INNER JOIN tags t ON m.tags_id = t.id
+		WHERE t.%s = '%s' AND m.time >= '%s' AND m.time < '%s'
+		GROUP BY hour, deployment ORDER BY hour, deployment`,
+		k.getTimeBucket(oneHour),
+		k.withAlias(deployment),
+		k.columnSelect(namespace),
+		namespaceValue,
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt))
+
+	humanLabel := fmt.Sprintf("TimescaleDB average memory per deployment of a namespace, random %s by 1h", kubernetes.NamespaceMemoryDuration)
+	humanDesc := fmt.Sprintf("%s: %s %s", humanLabel, namespaceValue, interval.StartString())
+	k.fillInQuery(qi, humanLabel, humanDesc, kubernetes.ContainerMemoryTableName, sql)
+}
+
+// PodChurn counts the pods that reported in each namespace per hour over
+// random 12 hours.
+func (k *Kubernetes) PodChurn(qi query.Query) {
+	interval := k.Interval.MustRandWindow(kubernetes.PodChurnDuration)
+	namespace := "namespace"
+
+	sql := fmt.Sprintf(`SELECT %s AS hour, t.%s, count(DISTINCT c.tags_id) AS pods
+		FROM container_cpu c INNER JOIN tags t ON c.tags_id = t.id
+		WHERE c.time >= '%s' AND c.time < '%s'
+		GROUP BY hour, namespace ORDER BY hour, namespace`,
+		k.getTimeBucket(oneHour),
+		k.withAlias(namespace),
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt))
+
+	humanLabel := fmt.Sprintf("TimescaleDB pods per namespace, random %s by 1h", kubernetes.PodChurnDuration)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	k.fillInQuery(qi, humanLabel, humanDesc, kubernetes.ContainerCPUTableName, sql)
+}
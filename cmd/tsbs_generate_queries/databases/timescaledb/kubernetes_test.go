@@ -0,0 +1,137 @@
+package timescaledb
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/kubernetes"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+func TestNamespaceCPU(t *testing.T) {
+	cases := []testCase{
+		{
+			desc: "default to using tags",
+
+			expectedHumanLabel: "TimescaleDB average cpu usage of a namespace, random 1h0m0s by 1m",
+			expectedHumanDesc:  "TimescaleDB average cpu usage of a namespace, random 1h0m0s by 1m: kube-system 2016-01-01T20:16:22Z",
+			expectedHypertable: kubernetes.ContainerCPUTableName,
+			expectedSQLQuery: `SELECT time_bucket('60 seconds', time) AS minute, avg(usage_percent) AS avg_usage_percent
+		FROM container_cpu
+		WHERE tags_id IN (SELECT id FROM tags WHERE namespace = 'kube-system') AND time >= '2016-01-01 20:16:22.646325 +0000' AND time < '2016-01-01 21:16:22.646325 +0000'
+		GROUP BY minute ORDER BY minute`,
+		},
+		{
+			desc:    "use JSON",
+			useJSON: true,
+
+			expectedHumanLabel: "TimescaleDB average cpu usage of a namespace, random 1h0m0s by 1m",
+			expectedHumanDesc:  "TimescaleDB average cpu usage of a namespace, random 1h0m0s by 1m: kube-system 2016-01-01T20:16:22Z",
+			expectedHypertable: kubernetes.ContainerCPUTableName,
+			expectedSQLQuery: `SELECT time_bucket('60 seconds', time) AS minute, avg(usage_percent) AS avg_usage_percent
+		FROM container_cpu
+		WHERE tags_id IN (SELECT id FROM tags WHERE tagset @> '{"namespace": "kube-system"}') AND time >= '2016-01-01 20:16:22.646325 +0000' AND time < '2016-01-01 21:16:22.646325 +0000'
+		GROUP BY minute ORDER BY minute`,
+		},
+	}
+
+	testFunc := func(k *Kubernetes, c testCase) query.Query {
+		q := k.GenerateEmptyQuery()
+		k.NamespaceCPU(q)
+		return q
+	}
+
+	runKubernetesTestCases(t, testFunc, cases)
+}
+
+func TestNamespaceMemory(t *testing.T) {
+	cases := []testCase{
+		{
+			desc: "default to using tags",
+
+			expectedHumanLabel: "TimescaleDB average memory per deployment of a namespace, random 12h0m0s by 1h",
+			expectedHumanDesc:  "TimescaleDB average memory per deployment of a namespace, random 12h0m0s by 1h: kube-system 2016-01-01T06:16:22Z",
+			expectedHypertable: kubernetes.ContainerMemoryTableName,
+			expectedSQLQuery: `SELECT time_bucket('3600 seconds', time) AS hour, t.deployment AS deployment, avg(m.working_set_bytes) AS avg_working_set_bytes
+		FROM container_memory m INNER JOIN tags t ON m.tags_id = t.id
+		WHERE t.namespace = 'kube-system' AND m.time >= '2016-01-01 06:16:22.646325 +0000' AND m.time < '2016-01-01 18:16:22.646325 +0000'
+		GROUP BY hour, deployment ORDER BY hour, deployment`,
+		},
+		{
+			desc:    "use JSON",
+			useJSON: true,
+
+			expectedHumanLabel: "TimescaleDB average memory per deployment of a namespace, random 12h0m0s by 1h",
+			expectedHumanDesc:  "TimescaleDB average memory per deployment of a namespace, random 12h0m0s by 1h: kube-system 2016-01-01T06:16:22Z",
+			expectedHypertable: kubernetes.ContainerMemoryTableName,
+			expectedSQLQuery: `SELECT time_bucket('3600 seconds', time) AS hour, t.tagset->>'deployment' AS deployment, avg(m.working_set_bytes) AS avg_working_set_bytes
+		FROM container_memory m INNER JOIN tags t ON m.tags_id = t.id
+		WHERE t.tagset->>'namespace' = 'kube-system' AND m.time >= '2016-01-01 06:16:22.646325 +0000' AND m.time < '2016-01-01 18:16:22.646325 +0000'
+		GROUP BY hour, deployment ORDER BY hour, deployment`,
+		},
+	}
+
+	testFunc := func(k *Kubernetes, c testCase) query.Query {
+		q := k.GenerateEmptyQuery()
+		k.NamespaceMemory(q)
+		return q
+	}
+
+	runKubernetesTestCases(t, testFunc, cases)
+}
+
+func TestPodChurn(t *testing.T) {
+	cases := []testCase{
+		{
+			desc: "default to using tags",
+
+			expectedHumanLabel: "TimescaleDB pods per namespace, random 12h0m0s by 1h",
+			expectedHumanDesc:  "TimescaleDB pods per namespace, random 12h0m0s by 1h: 2016-01-01T06:16:22Z",
+			expectedHypertable: kubernetes.ContainerCPUTableName,
+			expectedSQLQuery: `SELECT time_bucket('3600 seconds', time) AS hour, t.namespace AS namespace, count(DISTINCT c.tags_id) AS pods
+		FROM container_cpu c INNER JOIN tags t ON c.tags_id = t.id
+		WHERE c.time >= '2016-01-01 06:16:22.646325 +0000' AND c.time < '2016-01-01 18:16:22.646325 +0000'
+		GROUP BY hour, namespace ORDER BY hour, namespace`,
+		},
+		{
+			desc:    "use JSON",
+			useJSON: true,
+
+			expectedHumanLabel: "TimescaleDB pods per namespace, random 12h0m0s by 1h",
+			expectedHumanDesc:  "TimescaleDB pods per namespace, random 12h0m0s by 1h: 2016-01-01T06:16:22Z",
+			expectedHypertable: kubernetes.ContainerCPUTableName,
+			expectedSQLQuery: `SELECT time_bucket('3600 seconds', time) AS hour, t.tagset->>'namespace' AS namespace, count(DISTINCT c.tags_id) AS pods
+		FROM container_cpu c INNER JOIN tags t ON c.tags_id = t.id
+		WHERE c.time >= '2016-01-01 06:16:22.646325 +0000' AND c.time < '2016-01-01 18:16:22.646325 +0000'
+		GROUP BY hour, namespace ORDER BY hour, namespace`,
+		},
+	}
+
+	testFunc := func(k *Kubernetes, c testCase) query.Query {
+		q := k.GenerateEmptyQuery()
+		k.PodChurn(q)
+		return q
+	}
+
+	runKubernetesTestCases(t, testFunc, cases)
+}
+
+func runKubernetesTestCases(t *testing.T, testFunc func(*Kubernetes, testCase) query.Query, cases []testCase) {
+	s := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := s.Add(24 * time.Hour)
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rand.Seed(123) // Setting seed for testing purposes.
+			b := BaseGenerator{UseJSON: c.useJSON, UseTimeBucket: true}
+			kq, err := b.NewKubernetes(s, e, testScale)
+			if err != nil {
+				t.Fatalf("Error while creating kubernetes generator")
+			}
+
+			q := testFunc(kq.(*Kubernetes), c)
+			verifyQuery(t, q, c.expectedHumanLabel, c.expectedHumanDesc, c.expectedHypertable, c.expectedSQLQuery)
+		})
+	}
+}
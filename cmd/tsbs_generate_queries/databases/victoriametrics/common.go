@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/kubernetes"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
 	iutils "github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/pkg/query"
@@ -31,6 +32,18 @@ func (g *BaseGenerator) NewDevops(start, end time.Time, scale int) (utils.QueryG
 	}, nil
 }
 
+// NewKubernetes creates a new kubernetes use case query generator.
+func (g *BaseGenerator) NewKubernetes(start, end time.Time, scale int) (utils.QueryGenerator, error) {
+	core, err := kubernetes.NewCore(start, end, scale)
+	if err != nil {
+		return nil, err
+	}
+	return &Kubernetes{
+		BaseGenerator: g,
+		Core:          core,
+	}, nil
+}
+
 type queryInfo struct {
 	// prometheus query
 	query string
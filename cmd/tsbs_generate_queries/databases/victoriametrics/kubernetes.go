@@ -0,0 +1,59 @@
+package victoriametrics
+
+import (
+	"fmt"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/kubernetes"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// Kubernetes produces PromQL queries for all the kubernetes query types.
+type Kubernetes struct {
+	*BaseGenerator
+	*kubernetes.Core
+}
+
+// NamespaceCPU selects the average cpu usage of the pods of a random
+// namespace per minute over a random hour, e.g. in pseudo-PromQL:
+//
+//	avg(avg_over_time(container_cpu_usage_percent{namespace='$NAMESPACE'}[1m]))
+func (k *Kubernetes) NamespaceCPU(qq query.Query) {
+	interval := k.Interval.MustRandWindow(kubernetes.NamespaceCPUDuration)
+	qi := &queryInfo{
+		query:    fmt.Sprintf("avg(avg_over_time(container_cpu_usage_percent{namespace='%s'}[1m]))", k.GetRandomNamespace()),
+		label:    fmt.Sprintf("VictoriaMetrics average cpu usage of a namespace, random %s by 1m", kubernetes.NamespaceCPUDuration),
+		interval: interval,
+		step:     "60",
+	}
+	k.fillInQuery(qq, qi)
+}
+
+// NamespaceMemory selects the average memory working set of the pods of each
+// deployment of a random namespace per hour over random 12 hours, e.g. in
+// pseudo-PromQL:
+//
+//	avg(avg_over_time(container_memory_working_set_bytes{namespace='$NAMESPACE'}[1h])) by (deployment)
+func (k *Kubernetes) NamespaceMemory(qq query.Query) {
+	interval := k.Interval.MustRandWindow(kubernetes.NamespaceMemoryDuration)
+	qi := &queryInfo{
+		query:    fmt.Sprintf("avg(avg_over_time(container_memory_working_set_bytes{namespace='%s'}[1h])) by (deployment)", k.GetRandomNamespace()),
+		label:    fmt.Sprintf("VictoriaMetrics average memory per deployment of a namespace, random %s by 1h", kubernetes.NamespaceMemoryDuration),
+		interval: interval,
+		step:     "3600",
+	}
+	k.fillInQuery(qq, qi)
+}
+
+// PodChurn counts the pods that reported in each namespace per hour over
+// random 12 hours. Every pod is a series of its own, e.g. in pseudo-PromQL:
+//
+//	count(count_over_time(container_cpu_usage_percent[1h])) by (namespace)
+func (k *Kubernetes) PodChurn(qq query.Query) {
+	qi := &queryInfo{
+		query:    "count(count_over_time(container_cpu_usage_percent[1h])) by (namespace)",
+		label:    fmt.Sprintf("VictoriaMetrics pods per namespace, random %s by 1h", kubernetes.PodChurnDuration),
+		interval: k.Interval.MustRandWindow(kubernetes.PodChurnDuration),
+		step:     "3600",
+	}
+	k.fillInQuery(qq, qi)
+}
@@ -0,0 +1,65 @@
+package victoriametrics
+
+import (
+	"math/rand"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+func TestKubernetes(t *testing.T) {
+	testCases := map[string]struct {
+		fn       func(k *Kubernetes, q *query.HTTP)
+		expLabel string
+		expQuery string
+		expStep  string
+	}{
+		"NamespaceCPU": {
+			fn: func(k *Kubernetes, q *query.HTTP) {
+				k.NamespaceCPU(q)
+			},
+			expLabel: "VictoriaMetrics average cpu usage of a namespace, random 1h0m0s by 1m",
+			expQuery: "avg(avg_over_time(container_cpu_usage_percent{namespace='kube-system'}[1m]))",
+			expStep:  "60",
+		},
+		"NamespaceMemory": {
+			fn: func(k *Kubernetes, q *query.HTTP) {
+				k.NamespaceMemory(q)
+			},
+			expLabel: "VictoriaMetrics average memory per deployment of a namespace, random 12h0m0s by 1h",
+			expQuery: "avg(avg_over_time(container_memory_working_set_bytes{namespace='kube-system'}[1h])) by (deployment)",
+			expStep:  "3600",
+		},
+		"PodChurn": {
+			fn: func(k *Kubernetes, q *query.HTTP) {
+				k.PodChurn(q)
+			},
+			expLabel: "VictoriaMetrics pods per namespace, random 12h0m0s by 1h",
+			expQuery: "count(count_over_time(container_cpu_usage_percent[1h])) by (namespace)",
+			expStep:  "3600",
+		},
+	}
+	b := &BaseGenerator{}
+	s := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	g, err := b.NewKubernetes(s, s.Add(24*time.Hour), 10)
+	if err != nil {
+		t.Fatalf("Error while creating kubernetes generator")
+	}
+	k := g.(*Kubernetes)
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			rand.Seed(123) // Setting seed for testing purposes.
+			q := k.GenerateEmptyQuery().(*query.HTTP)
+			tc.fn(k, q)
+			vals, err := url.ParseQuery(string(q.Path))
+			if err != nil {
+				t.Fatalf("unexpected err while parsing query: %s", err)
+			}
+			checkEqual(t, "label", tc.expLabel, string(q.HumanLabel))
+			checkEqual(t, "query", tc.expQuery, vals.Get("query"))
+			checkEqual(t, "step", tc.expStep, vals.Get("step"))
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/iot"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/kubernetes"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
 	"github.com/timescale/tsbs/internal/inputs"
 	internalUtils "github.com/timescale/tsbs/internal/utils"
@@ -51,6 +52,11 @@ var useCaseMatrix = map[string]map[string]utils.QueryFillerMaker{
 		iot.LabelDailyActivity:                 iot.NewDailyTruckActivity,
 		iot.LabelBreakdownFrequency:            iot.NewTruckBreakdownFrequency,
 	},
+	"kubernetes": {
+		kubernetes.LabelNamespaceCPU:    kubernetes.NewNamespaceCPU,
+		kubernetes.LabelNamespaceMemory: kubernetes.NewNamespaceMemory,
+		kubernetes.LabelPodChurn:        kubernetes.NewPodChurn,
+	},
 }
 
 var conf = &config.QueryGeneratorConfig{}
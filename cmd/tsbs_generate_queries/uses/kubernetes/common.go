@@ -0,0 +1,64 @@
+package kubernetes
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/pkg/data/usecases/kubernetes"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+const (
+	// ContainerCPUTableName is the name of the table where the cpu usage
+	// of the containers is stored.
+	ContainerCPUTableName = "container_cpu"
+	// ContainerMemoryTableName is the name of the table where the memory
+	// usage of the containers is stored.
+	ContainerMemoryTableName = "container_memory"
+
+	// NamespaceCPUDuration is the time range of the namespace cpu query.
+	NamespaceCPUDuration = time.Hour
+	// NamespaceMemoryDuration is the time range of the namespace memory query.
+	NamespaceMemoryDuration = 12 * time.Hour
+	// PodChurnDuration is the time range of the pod churn query.
+	PodChurnDuration = 12 * time.Hour
+
+	// LabelNamespaceCPU is the label for the namespace cpu query.
+	LabelNamespaceCPU = "namespace-cpu"
+	// LabelNamespaceMemory is the label for the namespace memory query.
+	LabelNamespaceMemory = "namespace-memory"
+	// LabelPodChurn is the label for the pod churn query.
+	LabelPodChurn = "pod-churn"
+)
+
+// Core is the common component of all generators for all systems.
+type Core struct {
+	*common.Core
+}
+
+// GetRandomNamespace returns one of the namespace choices by random.
+func (c Core) GetRandomNamespace() string {
+	return kubernetes.NamespaceChoices[rand.Intn(len(kubernetes.NamespaceChoices))]
+}
+
+// NewCore returns a new Core for the given time range and cardinality
+func NewCore(start, end time.Time, scale int) (*Core, error) {
+	c, err := common.NewCore(start, end, scale)
+	return &Core{Core: c}, err
+}
+
+// NamespaceCPUFiller is a type that can fill in a namespace cpu query.
+type NamespaceCPUFiller interface {
+	NamespaceCPU(query.Query)
+}
+
+// NamespaceMemoryFiller is a type that can fill in a namespace memory query.
+type NamespaceMemoryFiller interface {
+	NamespaceMemory(query.Query)
+}
+
+// PodChurnFiller is a type that can fill in a pod churn query.
+type PodChurnFiller interface {
+	PodChurn(query.Query)
+}
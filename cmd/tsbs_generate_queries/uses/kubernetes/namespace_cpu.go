@@ -0,0 +1,29 @@
+package kubernetes
+
+import (
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// NamespaceCPU contains info for filling in namespace cpu queries.
+type NamespaceCPU struct {
+	core utils.QueryGenerator
+}
+
+// NewNamespaceCPU creates a new namespace cpu query filler.
+func NewNamespaceCPU(core utils.QueryGenerator) utils.QueryFiller {
+	return &NamespaceCPU{
+		core: core,
+	}
+}
+
+// Fill fills in the query.Query with query details.
+func (i *NamespaceCPU) Fill(q query.Query) query.Query {
+	fc, ok := i.core.(NamespaceCPUFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(i.core)
+	}
+	fc.NamespaceCPU(q)
+	return q
+}
@@ -0,0 +1,29 @@
+package kubernetes
+
+import (
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// PodChurn contains info for filling in pod churn queries.
+type PodChurn struct {
+	core utils.QueryGenerator
+}
+
+// NewPodChurn creates a new pod churn query filler.
+func NewPodChurn(core utils.QueryGenerator) utils.QueryFiller {
+	return &PodChurn{
+		core: core,
+	}
+}
+
+// Fill fills in the query.Query with query details.
+func (i *PodChurn) Fill(q query.Query) query.Query {
+	fc, ok := i.core.(PodChurnFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(i.core)
+	}
+	fc.PodChurn(q)
+	return q
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
 	"time"
 )
 
@@ -12,23 +13,52 @@ type LoadConfig struct {
 type LoaderConfig struct {
 	Target     string
 	Runner     *RunnerConfig
-	DBSpecific interface{} `yaml:"db-specific" mapstructure:"db-specific"`
+	DBSpecific interface{}       `yaml:"db-specific" mapstructure:"db-specific"`
+	Saturation *SaturationConfig `yaml:"saturation,omitempty" mapstructure:"saturation"`
+}
+
+// SaturationConfig holds the settings of the saturate command, the steps
+// are comma separated lists of workers and target rates
+type SaturationConfig struct {
+	Workers                 string
+	TargetRates             string        `yaml:"target-rates" mapstructure:"target-rates"`
+	StepDuration            time.Duration `yaml:"step-duration" mapstructure:"step-duration"`
+	MinThroughputGain       float64       `yaml:"min-throughput-gain" mapstructure:"min-throughput-gain"`
+	RateTolerance           float64       `yaml:"rate-tolerance" mapstructure:"rate-tolerance"`
+	MaxP99Latency           time.Duration `yaml:"max-p99-latency" mapstructure:"max-p99-latency"`
+	ContinueAfterSaturation bool          `yaml:"continue-after-saturation" mapstructure:"continue-after-saturation"`
 }
 
 type RunnerConfig struct {
-	DBName          string `yaml:"db-name" mapstructure:"db-name"`
-	BatchSize       uint   `yaml:"batch-size" mapstructure:"batch-size"`
-	Workers         uint
-	Limit           uint64
-	DoLoad          bool          `yaml:"do-load" mapstructure:"do-load"`
-	DoCreateDB      bool          `yaml:"do-create-db" mapstructure:"do-create-db"`
-	DoAbortOnExist  bool          `yaml:"do-abort-on-exist" mapstructure:"do-abort-on-exist"`
-	ReportingPeriod time.Duration `yaml:"reporting-period" mapstructure:"reporting-period"`
-	Seed            int64
-	HashWorkers     bool   `yaml:"hash-workers" mapstructure:"hash-workers"`
-	InsertIntervals string `yaml:"insert-intervals" mapstructure:"insert-intervals"`
-	FlowControl     bool   `yaml:"flow-control" mapstructure:"flow-control"`
-	ChannelCapacity uint   `yaml:"channel-capacity" mapstructure:"channel-capacity"`
+	DBName             string `yaml:"db-name" mapstructure:"db-name"`
+	BatchSize          uint   `yaml:"batch-size" mapstructure:"batch-size"`
+	Workers            uint
+	Limit              uint64
+	DoLoad             bool          `yaml:"do-load" mapstructure:"do-load"`
+	DoCreateDB         bool          `yaml:"do-create-db" mapstructure:"do-create-db"`
+	DoAbortOnExist     bool          `yaml:"do-abort-on-exist" mapstructure:"do-abort-on-exist"`
+	ReportingPeriod    time.Duration `yaml:"reporting-period" mapstructure:"reporting-period"`
+	Seed               int64
+	HashWorkers        bool          `yaml:"hash-workers" mapstructure:"hash-workers"`
+	InsertIntervals    string        `yaml:"insert-intervals" mapstructure:"insert-intervals"`
+	FlowControl        bool          `yaml:"flow-control" mapstructure:"flow-control"`
+	ChannelCapacity    uint          `yaml:"channel-capacity" mapstructure:"channel-capacity"`
+	MaxRetries         uint          `yaml:"max-retries" mapstructure:"max-retries"`
+	RetryBackoff       time.Duration `yaml:"retry-backoff" mapstructure:"retry-backoff"`
+	RetryMaxBackoff    time.Duration `yaml:"retry-max-backoff" mapstructure:"retry-max-backoff"`
+	ErrorBudget        uint64        `yaml:"error-budget" mapstructure:"error-budget"`
+	ResultsFile        string        `yaml:"results-file" mapstructure:"results-file"`
+	HDRLatencies       string        `yaml:"hdr-latencies" mapstructure:"hdr-latencies"`
+	TargetRate         float64       `yaml:"target-rate" mapstructure:"target-rate"`
+	TargetRateUnit     string        `yaml:"target-rate-unit" mapstructure:"target-rate-unit"`
+	Duration           time.Duration `yaml:"duration" mapstructure:"duration"`
+	Warmup             time.Duration `yaml:"warmup" mapstructure:"warmup"`
+	MetricsListenAddr  string        `yaml:"metrics-listen-addr" mapstructure:"metrics-listen-addr"`
+	ReportFormat       string        `yaml:"report-format" mapstructure:"report-format"`
+	ReportFile         string        `yaml:"report-file" mapstructure:"report-file"`
+	CheckpointFile     string        `yaml:"checkpoint-file" mapstructure:"checkpoint-file"`
+	CheckpointInterval time.Duration `yaml:"checkpoint-interval" mapstructure:"checkpoint-interval"`
+	Resume             bool          `yaml:"resume" mapstructure:"resume"`
 }
 
 type DataSourceConfig struct {
@@ -51,4 +81,13 @@ type SimulatorDataSourceConfig struct {
 	Limit                 uint64        `yaml:"max-data-points" mapstructure:"max-data-points"`
 	LogInterval           time.Duration `yaml:"log-interval" mapstructure:"log-interval"`
 	MaxMetricCountPerHost uint64        `yaml:"max-metric-count" mapstructure:"max-metric-count"`
+	UseCaseFile           string        `yaml:"use-case-file,omitempty" mapstructure:"use-case-file"`
+	RealTime              bool          `yaml:"real-time,omitempty" mapstructure:"real-time"`
+	PodLifetime           time.Duration `yaml:"pod-lifetime,omitempty" mapstructure:"pod-lifetime"`
+	ChurnRate             float64       `yaml:"churn-rate,omitempty" mapstructure:"churn-rate"`
+	LateChance            float64       `yaml:"late-chance,omitempty" mapstructure:"late-chance"`
+	MaxLateness           time.Duration `yaml:"max-lateness,omitempty" mapstructure:"max-lateness"`
+	DuplicateChance       float64       `yaml:"duplicate-chance,omitempty" mapstructure:"duplicate-chance"`
+	GapChance             float64       `yaml:"gap-chance,omitempty" mapstructure:"gap-chance"`
+	common.IoTChances     `yaml:",inline" mapstructure:",squash"`
 }
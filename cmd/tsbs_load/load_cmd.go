@@ -5,6 +5,7 @@ import (
 	"github.com/blagojts/viper"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/load"
 	"github.com/timescale/tsbs/pkg/targets"
 	"github.com/timescale/tsbs/pkg/targets/constants"
 	"github.com/timescale/tsbs/pkg/targets/initializers"
@@ -28,7 +29,7 @@ func initLoadCMD() (*cobra.Command, error) {
 		return nil, fmt.Errorf("could not bind flags to configuration: %v", err)
 	}
 
-	subCommands := initLoadSubCommands()
+	subCommands := initTargetSubCommands("Load data into %s as a target db", createRunLoad, nil)
 	cmd.AddCommand(subCommands...)
 	return cmd, nil
 }
@@ -40,19 +41,24 @@ func loadCmdFlags() *pflag.FlagSet {
 	return fs
 }
 
-func initLoadSubCommands() []*cobra.Command {
-	allFormats := constants.SupportedFormats()
-	commands := make([]*cobra.Command, len(allFormats))
-	for i, format := range allFormats {
+// initTargetSubCommands creates a sub-command for every supported target accepted
+// by filter (all of them if nil), shortFmt is the short description with a
+// placeholder for the target name
+func initTargetSubCommands(shortFmt string, createRun func(targets.ImplementedTarget) cmdRunner, filter func(targets.ImplementedTarget) bool) []*cobra.Command {
+	var commands []*cobra.Command
+	for _, format := range constants.SupportedFormats() {
 		target := initializers.GetTarget(format)
+		if filter != nil && !filter(target) {
+			continue
+		}
 		cmd := &cobra.Command{
 			Use:   format,
-			Short: "Load data into " + format + " as a target db",
-			Run:   createRunLoad(target),
+			Short: fmt.Sprintf(shortFmt, format),
+			Run:   createRun(target),
 		}
 
 		target.TargetSpecificFlags("loader.db-specific.", cmd.PersistentFlags())
-		commands[i] = cmd
+		commands = append(commands, cmd)
 	}
 
 	return commands
@@ -66,11 +72,11 @@ func createRunLoad(target targets.ImplementedTarget) cmdRunner {
 		if err := viper.BindPFlags(cmd.PersistentFlags()); err != nil {
 			panic(fmt.Errorf("could not bind db-specific flags for %s: %v", target.TargetName(), err))
 		}
-		bench, runner, err := parseConfig(target, viper.GetViper())
+		bench, runnerConfig, err := parseConfig(target, viper.GetViper())
 		if err != nil {
 			panic(err)
 		}
-		runner.RunBenchmark(bench)
+		load.GetBenchmarkRunner(*runnerConfig).RunBenchmark(bench)
 	}
 }
 
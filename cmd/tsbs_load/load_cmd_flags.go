@@ -5,6 +5,7 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/load"
 	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
 	"strings"
 	"time"
 )
@@ -14,6 +15,9 @@ const (
 	defaultTimeEnd     = "2020-01-02T00:00:00Z"
 	defaultLogInterval = 10 * time.Second
 	defaultScale       = 1
+	defaultPodLifetime = 2 * time.Hour
+	defaultChurnRate   = 0.25
+	defaultMaxLateness = 5 * time.Minute
 )
 
 func addLoaderRunnerFlags(fs *pflag.FlagSet) {
@@ -67,6 +71,78 @@ func addLoaderRunnerFlags(fs *pflag.FlagSet) {
 			"Default 0 means that:\n\tif hash-workers=false then capacity = 5 * number of workers\n\t"+
 			"if hash-workers=true, then capacity = 5 for each worker",
 	)
+	fs.Uint(
+		"loader.runner.max-retries",
+		0,
+		"Number of times a failed batch is retried before it counts as failed (only for targets that report insert errors)",
+	)
+	fs.Duration(
+		"loader.runner.retry-backoff",
+		100*time.Millisecond,
+		"Time to wait before the first retry of a failed batch, doubled on every following retry",
+	)
+	fs.Duration("loader.runner.retry-max-backoff", 10*time.Second, "Maximum time to wait between two retries of a failed batch")
+	fs.Uint64(
+		"loader.runner.error-budget",
+		0,
+		"Number of failed batches tolerated before the load is aborted (0 = abort on the first failed batch)",
+	)
+	fs.String("loader.runner.results-file", "", "Write the test results summary json to this file")
+	fs.String(
+		"loader.runner.hdr-latencies",
+		"",
+		"Write the High Dynamic Range (HDR) Histogram of batch insert latencies to this file",
+	)
+	fs.Float64(
+		"loader.runner.target-rate",
+		0,
+		"Insert at this constant rate, independent of how long inserts take (0 = as fast as possible).\n"+
+			"Latencies are measured from the scheduled start of each batch. Can't be used with insert-intervals",
+	)
+	fs.String(
+		"loader.runner.target-rate-unit",
+		load.TargetRateUnitRows,
+		"Unit of target-rate: 'rows' (data points read) or 'metrics' per second",
+	)
+	fs.Duration(
+		"loader.runner.duration",
+		0,
+		"Stop loading after this wall-clock duration, warm-up included (0 = no time limit)",
+	)
+	fs.Duration(
+		"loader.runner.warmup",
+		0,
+		"Exclude the first part of the load of this duration from the reported rates and latencies",
+	)
+	fs.String(
+		"loader.runner.report-format",
+		load.ReportFormatText,
+		"Format of the periodic report: 'text' (human readable), 'csv' or 'jsonl' (one JSON object per line)",
+	)
+	fs.String("loader.runner.report-file", "", "Write the periodic report to this file instead of stdout")
+	fs.String(
+		"loader.runner.checkpoint-file",
+		"",
+		"Periodically save the number of items loaded to this file, so an interrupted load can be resumed",
+	)
+	fs.Duration("loader.runner.checkpoint-interval", 10*time.Second, "Period to save the checkpoint-file")
+	fs.Bool(
+		"loader.runner.resume",
+		false,
+		"Resume the load from the checkpoint-file: skip the items already loaded and don't (re)create the database",
+	)
+	fs.String(
+		"loader.runner.metrics-listen-addr",
+		"",
+		"Serve live Prometheus metrics of the load on this address (e.g. ':9090'), on the /metrics path",
+	)
+	fs.StringSlice(
+		"loader.runner.assert",
+		nil,
+		"Assertion on the final stats, e.g. 'metricRate>500000', 'p99<100ms' or 'errors==0', repeated or comma separated.\n"+
+			"The load exits with 3 if one fails. Stats: metricRate, rowRate, metrics, rows, retriedBatches,\n"+
+			"failedBatches (or errors) and the batch latency: pN (e.g. p99.9), min, mean and max (in ms)",
+	)
 }
 
 func addDataSourceFlags(fs *pflag.FlagSet) {
@@ -96,9 +172,39 @@ func addDataSourceFlags(fs *pflag.FlagSet) {
 		100,
 		"Max number of metric fields to generate per host. Used only in devops-generic use-case",
 	)
+	fs.String(
+		"data-source.simulator.use-case-file",
+		"",
+		"YAML file declaring the entities, measurements and fields of the custom use case",
+	)
+	fs.Duration(
+		"data-source.simulator.pod-lifetime",
+		defaultPodLifetime,
+		"Mean lifetime of a pod before it is replaced, 0 = until its deployment is rolled out. Used only in kubernetes use-case",
+	)
+	fs.Float64(
+		"data-source.simulator.churn-rate",
+		defaultChurnRate,
+		"Number of rollouts per deployment per hour. Used only in kubernetes use-case",
+	)
+	fs.Float64(
+		"data-source.simulator.late-chance",
+		0,
+		"Chance that a point arrives late, after the points up to a random delay of at most max-lateness. Not used in iot use-case",
+	)
+	fs.Duration("data-source.simulator.max-lateness", defaultMaxLateness, "Max delay of the late points")
+	fs.Float64("data-source.simulator.duplicate-chance", 0, "Chance that a point is written twice. Not used in iot use-case")
+	fs.Float64("data-source.simulator.gap-chance", 0, "Chance that a point is missing. Not used in iot use-case")
+	(&common.IoTChances{}).AddToFlagSet(fs, "data-source.simulator.")
 	fs.Uint64(
 		"data-source.simulator.scale",
 		defaultScale,
 		"Scaling value specific to use case (e.g., devices in 'devops', trucks in iot).")
 	fs.Duration("data-source.simulator.log-interval", defaultLogInterval, "Duration between data points")
+	fs.Bool(
+		"data-source.simulator.real-time",
+		false,
+		"Stamp the points with the wall-clock time and load each log interval when it is due,\n"+
+			"for as long as the time between timestamp-start and timestamp-end",
+	)
 }
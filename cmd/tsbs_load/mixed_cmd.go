@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/load"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+	"github.com/timescale/tsbs/pkg/targets/initializers"
+)
+
+const mixedQueriesPrefix = "mixed.queries."
+
+func initMixedCMD() (*cobra.Command, error) {
+	cmd := &cobra.Command{
+		Use:              "mixed",
+		Short:            "Run queries against a target database while data is loaded into it",
+		PersistentPreRun: initViperConfig,
+	}
+	fs := loadCmdFlags()
+	addMixedFlags(fs)
+	cmd.PersistentFlags().AddFlagSet(fs)
+	err := viper.BindPFlags(cmd.PersistentFlags())
+	// don't bind --config which specifies the file from where to read config
+	cmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ./config.yaml)")
+
+	if err != nil {
+		return nil, fmt.Errorf("could not bind flags to configuration: %v", err)
+	}
+
+	// all targets are sub-commands, so the ones that can't run queries fail with
+	// an error listing the supported ones instead of an unknown command
+	subCommands := initTargetSubCommands("Query %s while data is loaded into it", createRunMixed, nil)
+	cmd.AddCommand(subCommands...)
+	return cmd, nil
+}
+
+// addMixedFlags adds the flags of the query runner, prefixed with mixed.queries.,
+// and the ones of the mixed workload itself
+func addMixedFlags(fs *pflag.FlagSet) {
+	queryFlags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	query.BenchmarkRunnerConfig{}.AddToFlagSet(queryFlags)
+	queryFlags.VisitAll(func(f *pflag.Flag) {
+		// the queries run against the database the data is loaded into, and
+		// their results change while it's loaded so there's nothing to capture.
+		// A mixed workload runs the queries once, without assertions.
+		if f.Name == "db-name" || f.Name == "capture-results" || f.Name == "capture-precision" ||
+			f.Name == "workers-sweep" || f.Name == "assert" {
+			return
+		}
+		fs.AddFlag(&pflag.Flag{Name: mixedQueriesPrefix + f.Name, Usage: f.Usage, Value: f.Value, DefValue: f.DefValue})
+	})
+	fs.Bool("mixed.baseline", false, "Run the queries again without writes once the load finished, to compare the latencies under load with")
+	fs.String("mixed.results-file", "", "Write the combined results of the load and the queries to this file")
+}
+
+func createRunMixed(target targets.ImplementedTarget) cmdRunner {
+	return func(cmd *cobra.Command, args []string) {
+		// bind only the flags of the executed sub-command, see createRunLoad
+		if err := viper.BindPFlags(cmd.PersistentFlags()); err != nil {
+			panic(fmt.Errorf("could not bind db-specific flags for %s: %v", target.TargetName(), err))
+		}
+		// check before parseConfig, the targets that can't run queries can't load data from tsbs_load either
+		if _, err := queryTarget(target); err != nil {
+			panic(err)
+		}
+		bench, runnerConfig, err := parseConfig(target, viper.GetViper())
+		if err != nil {
+			panic(err)
+		}
+		mixedConfig, err := parseMixedConfig(target, runnerConfig.DBName, viper.GetViper())
+		if err != nil {
+			panic(err)
+		}
+		load.RunMixed(*runnerConfig, bench, *mixedConfig)
+	}
+}
+
+// queryTarget returns target as a targets.QueryTarget, or an error listing the
+// targets that can run queries from tsbs_load
+func queryTarget(target targets.ImplementedTarget) (targets.QueryTarget, error) {
+	if qt, ok := target.(targets.QueryTarget); ok {
+		return qt, nil
+	}
+	var supported []string
+	for _, format := range constants.SupportedFormats() {
+		if _, ok := initializers.GetTarget(format).(targets.QueryTarget); ok {
+			supported = append(supported, format)
+		}
+	}
+	return nil, fmt.Errorf("mixed workloads can't run queries against %s, they are supported for: %s",
+		target.TargetName(), strings.Join(supported, ", "))
+}
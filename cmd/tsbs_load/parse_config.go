@@ -7,10 +7,13 @@ import (
 	"github.com/timescale/tsbs/load"
 	"github.com/timescale/tsbs/pkg/data/source"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/query"
 	"github.com/timescale/tsbs/pkg/targets"
+	"strconv"
+	"strings"
 )
 
-func parseConfig(target targets.ImplementedTarget, v *viper.Viper) (targets.Benchmark, load.BenchmarkRunner, error) {
+func parseConfig(target targets.ImplementedTarget, v *viper.Viper) (targets.Benchmark, *load.BenchmarkRunnerConfig, error) {
 	dataSourceViper := v.Sub("data-source")
 	if dataSourceViper == nil {
 		return nil, nil, fmt.Errorf("config file didn't have a top-level 'data-source' object")
@@ -37,6 +40,7 @@ func parseConfig(target targets.ImplementedTarget, v *viper.Viper) (targets.Benc
 	}
 
 	loaderConfigInternal := convertRunnerConfigToInternalRep(loaderConfig)
+	loaderConfigInternal.DataSource = dataSourceInternal
 
 	dbSpecificViper := loaderViper.Sub("db-specific")
 	if dbSpecificViper == nil {
@@ -48,7 +52,40 @@ func parseConfig(target targets.ImplementedTarget, v *viper.Viper) (targets.Benc
 		return nil, nil, err
 	}
 
-	return benchmark, load.GetBenchmarkRunner(*loaderConfigInternal), nil
+	return benchmark, loaderConfigInternal, nil
+}
+
+// parseSaturationConfig reads the loader.saturation settings of the saturate command
+func parseSaturationConfig(v *viper.Viper) (*load.SaturationConfig, error) {
+	var c SaturationConfig
+	if err := subConfig(v, "loader.saturation").Unmarshal(&c); err != nil {
+		return nil, err
+	}
+	var workers []uint
+	for _, w := range splitList(c.Workers) {
+		n, err := strconv.ParseUint(w, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid saturation workers '%s': %v", c.Workers, err)
+		}
+		workers = append(workers, uint(n))
+	}
+	var rates []float64
+	for _, r := range splitList(c.TargetRates) {
+		rate, err := strconv.ParseFloat(r, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid saturation target-rates '%s': %v", c.TargetRates, err)
+		}
+		rates = append(rates, rate)
+	}
+	return &load.SaturationConfig{
+		Workers:                 workers,
+		TargetRates:             rates,
+		StepDuration:            c.StepDuration,
+		MinThroughputGain:       c.MinThroughputGain,
+		RateTolerance:           c.RateTolerance,
+		MaxP99Latency:           c.MaxP99Latency,
+		ContinueAfterSaturation: c.ContinueAfterSaturation,
+	}, nil
 }
 
 func parseRunnerConfig(v *viper.Viper) (*RunnerConfig, error) {
@@ -61,19 +98,35 @@ func parseRunnerConfig(v *viper.Viper) (*RunnerConfig, error) {
 
 func convertRunnerConfigToInternalRep(r *RunnerConfig) *load.BenchmarkRunnerConfig {
 	return &load.BenchmarkRunnerConfig{
-		DBName:          r.DBName,
-		BatchSize:       r.BatchSize,
-		Workers:         r.Workers,
-		Limit:           r.Limit,
-		DoLoad:          r.DoLoad,
-		DoCreateDB:      r.DoCreateDB,
-		DoAbortOnExist:  r.DoAbortOnExist,
-		ReportingPeriod: r.ReportingPeriod,
-		Seed:            r.Seed,
-		HashWorkers:     r.HashWorkers,
-		InsertIntervals: r.InsertIntervals,
-		NoFlowControl:   !r.FlowControl,
-		ChannelCapacity: r.ChannelCapacity,
+		DBName:             r.DBName,
+		BatchSize:          r.BatchSize,
+		Workers:            r.Workers,
+		Limit:              r.Limit,
+		DoLoad:             r.DoLoad,
+		DoCreateDB:         r.DoCreateDB,
+		DoAbortOnExist:     r.DoAbortOnExist,
+		ReportingPeriod:    r.ReportingPeriod,
+		Seed:               r.Seed,
+		HashWorkers:        r.HashWorkers,
+		InsertIntervals:    r.InsertIntervals,
+		NoFlowControl:      !r.FlowControl,
+		ChannelCapacity:    r.ChannelCapacity,
+		MaxRetries:         r.MaxRetries,
+		RetryBackoff:       r.RetryBackoff,
+		RetryMaxBackoff:    r.RetryMaxBackoff,
+		ErrorBudget:        r.ErrorBudget,
+		ResultsFile:        r.ResultsFile,
+		HDRLatencies:       r.HDRLatencies,
+		TargetRate:         r.TargetRate,
+		TargetRateUnit:     r.TargetRateUnit,
+		Duration:           r.Duration,
+		Warmup:             r.Warmup,
+		MetricsListenAddr:  r.MetricsListenAddr,
+		ReportFormat:       r.ReportFormat,
+		ReportFile:         r.ReportFile,
+		CheckpointFile:     r.CheckpointFile,
+		CheckpointInterval: r.CheckpointInterval,
+		Resume:             r.Resume,
 	}
 }
 
@@ -137,7 +190,18 @@ func convertDataSourceConfigToInternalRepresentation(format string, d *DataSourc
 			Limit:                 d.Simulator.Limit,
 			LogInterval:           d.Simulator.LogInterval,
 			MaxMetricCountPerHost: d.Simulator.MaxMetricCountPerHost,
+			UseCaseFile:           d.Simulator.UseCaseFile,
+			RealTime:              d.Simulator.RealTime,
+			PodLifetime:           d.Simulator.PodLifetime,
+			ChurnRate:             d.Simulator.ChurnRate,
 			InterleavedNumGroups:  1,
+			IoTChances:            d.Simulator.IoTChances,
+			Disorder: common.Disorder{
+				LateChance:      d.Simulator.LateChance,
+				MaxLateness:     d.Simulator.MaxLateness,
+				DuplicateChance: d.Simulator.DuplicateChance,
+				GapChance:       d.Simulator.GapChance,
+			},
 		}
 	}
 	return &source.DataSourceConfig{
@@ -146,3 +210,53 @@ func convertDataSourceConfigToInternalRepresentation(format string, d *DataSourc
 		Simulator: simulator,
 	}
 }
+
+// subConfig returns the settings under key from both the config file and the flags,
+// unlike v.Sub which returns nil when the config file doesn't have the key
+func subConfig(v *viper.Viper, key string) *viper.Viper {
+	sub := viper.New()
+	prefix := key + "."
+	for _, k := range v.AllKeys() {
+		if strings.HasPrefix(k, prefix) {
+			sub.Set(strings.TrimPrefix(k, prefix), v.Get(k))
+		}
+	}
+	return sub
+}
+
+// splitList splits a comma separated list, ignoring spaces and empty values
+func splitList(list string) []string {
+	var values []string
+	for _, v := range strings.Split(list, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// parseMixedConfig reads the mixed settings of the mixed command and creates the
+// query processors of target, connecting to dbName like the loader does
+func parseMixedConfig(target targets.ImplementedTarget, dbName string, v *viper.Viper) (*load.MixedConfig, error) {
+	qt, err := queryTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	var queries query.BenchmarkRunnerConfig
+	if err := subConfig(v, "mixed.queries").Unmarshal(&queries); err != nil {
+		return nil, err
+	}
+	queries.DBName = dbName
+
+	createProcessor, err := qt.QueryProcessor(queries, subConfig(v, "loader.db-specific"))
+	if err != nil {
+		return nil, err
+	}
+	return &load.MixedConfig{
+		Queries:              queries,
+		QueryPool:            qt.QueryPool(),
+		CreateQueryProcessor: createProcessor,
+		Baseline:             v.GetBool("mixed.baseline"),
+		ResultsFile:          v.GetString("mixed.results-file"),
+	}, nil
+}
@@ -18,6 +18,16 @@ func init() {
 		panic(err)
 	}
 	rootCmd.AddCommand(loadCmd)
+	saturateCmd, err := initSaturateCMD()
+	if err != nil {
+		panic(err)
+	}
+	rootCmd.AddCommand(saturateCmd)
+	mixedCmd, err := initMixedCMD()
+	if err != nil {
+		panic(err)
+	}
+	rootCmd.AddCommand(mixedCmd)
 	configCmd := initConfigCMD()
 	rootCmd.AddCommand(configCmd)
 }
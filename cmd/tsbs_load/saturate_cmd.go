@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/load"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+func initSaturateCMD() (*cobra.Command, error) {
+	cmd := &cobra.Command{
+		Use:              "saturate",
+		Short:            "Find the maximum sustainable ingest rate of a target database with a step load",
+		PersistentPreRun: initViperConfig,
+	}
+	fs := loadCmdFlags()
+	addSaturationFlags(fs)
+	cmd.PersistentFlags().AddFlagSet(fs)
+	err := viper.BindPFlags(cmd.PersistentFlags())
+	// don't bind --config which specifies the file from where to read config
+	cmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ./config.yaml)")
+
+	if err != nil {
+		return nil, fmt.Errorf("could not bind flags to configuration: %v", err)
+	}
+
+	subCommands := initTargetSubCommands("Find the maximum sustainable ingest rate of %s", createRunSaturate, nil)
+	cmd.AddCommand(subCommands...)
+	return cmd, nil
+}
+
+func addSaturationFlags(fs *pflag.FlagSet) {
+	fs.String(
+		"loader.saturation.workers",
+		"",
+		"Comma separated number of workers of each step, e.g. '1,2,4,8,16' (default: loader.runner.workers for every step)",
+	)
+	fs.String(
+		"loader.saturation.target-rates",
+		"",
+		"Comma separated target rate of each step, in loader.runner.target-rate-unit per second (default: loader.runner.target-rate)",
+	)
+	fs.Duration("loader.saturation.step-duration", time.Minute, "How long each step is held, loader.runner.warmup included")
+	fs.Float64(
+		"loader.saturation.min-throughput-gain",
+		0.05,
+		"A step is saturated if its throughput is not at least this fraction higher than the one of the previous step",
+	)
+	fs.Float64(
+		"loader.saturation.rate-tolerance",
+		0.95,
+		"A step with a target rate is saturated if it achieves less than this fraction of it",
+	)
+	fs.Duration(
+		"loader.saturation.max-p99-latency",
+		0,
+		"A step is saturated if its p99 batch latency is above this (0 = no latency limit)",
+	)
+	fs.Bool(
+		"loader.saturation.continue-after-saturation",
+		false,
+		"Run all the steps instead of stopping at the first saturated one",
+	)
+}
+
+func createRunSaturate(target targets.ImplementedTarget) cmdRunner {
+	return func(cmd *cobra.Command, args []string) {
+		// bind only the flags of the executed sub-command, see createRunLoad
+		if err := viper.BindPFlags(cmd.PersistentFlags()); err != nil {
+			panic(fmt.Errorf("could not bind db-specific flags for %s: %v", target.TargetName(), err))
+		}
+		bench, runnerConfig, err := parseConfig(target, viper.GetViper())
+		if err != nil {
+			panic(err)
+		}
+		saturationConfig, err := parseSaturationConfig(viper.GetViper())
+		if err != nil {
+			panic(err)
+		}
+		load.RunSaturationSearch(*runnerConfig, *saturationConfig, bench)
+	}
+}
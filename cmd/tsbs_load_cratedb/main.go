@@ -1,7 +1,6 @@
 package main
 
 import (
-	"bufio"
 	"flag"
 	"fmt"
 	"log"
@@ -90,7 +89,8 @@ func main() {
 	}
 
 	// TODO implement or check if anything has to be done to support WorkerPerQueue mode
-	ds := &fileDataSource{scanner: bufio.NewScanner(load.GetBufferedReader(config.FileName))}
+	file, scanner := load.OpenDataFile(config.FileName)
+	ds := &fileDataSource{file: file, scanner: scanner}
 	loader.RunBenchmark(&benchmark{
 		dbc: &dbCreator{
 			cfg:         connConfig,
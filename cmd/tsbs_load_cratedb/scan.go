@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/timescale/tsbs/load"
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
 	"github.com/timescale/tsbs/pkg/targets"
@@ -53,6 +54,7 @@ func (f *factory) New() targets.Batch {
 
 // source.DataSource interface implementation
 type fileDataSource struct {
+	file    *load.DataFile
 	scanner *bufio.Scanner
 	headers *common.GeneratedDataHeaders
 }
@@ -185,3 +187,18 @@ func parseMetrics(values []string) (row, error) {
 	}
 	return metrics, nil
 }
+
+// Offset returns the byte offset of the next item in the data file
+func (d *fileDataSource) Offset() int64 {
+	return d.file.Offset()
+}
+
+// SeekTo makes the item at offset in the data file the next one
+func (d *fileDataSource) SeekTo(offset int64) error {
+	scanner, err := d.file.SeekTo(offset)
+	if err != nil {
+		return err
+	}
+	d.scanner = scanner
+	return nil
+}
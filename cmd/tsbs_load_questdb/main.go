@@ -5,7 +5,6 @@
 package main
 
 import (
-	"bufio"
 	"bytes"
 	"fmt"
 	"log"
@@ -76,7 +75,8 @@ func init() {
 type benchmark struct{}
 
 func (b *benchmark) GetDataSource() targets.DataSource {
-	return &fileDataSource{scanner: bufio.NewScanner(load.GetBufferedReader(config.FileName))}
+	file, scanner := load.OpenDataFile(config.FileName)
+	return &fileDataSource{file: file, scanner: scanner}
 }
 
 func (b *benchmark) GetBatchFactory() targets.BatchFactory {
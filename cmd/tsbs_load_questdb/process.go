@@ -30,14 +30,22 @@ func (p *processor) Close(_ bool) {
 }
 
 func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64) {
+	metricCnt, rowCnt, err := p.ProcessBatchWithError(b, doLoad)
+	if err != nil {
+		fatal("Error writing: %s\n", err.Error())
+	}
+	return metricCnt, rowCnt
+}
+
+// ProcessBatchWithError writes the batch over the ILP connection. Write errors are
+// reported as not retryable, since part of the batch may already have been sent.
+func (p *processor) ProcessBatchWithError(b targets.Batch, doLoad bool) (uint64, uint64, error) {
 	batch := b.(*batch)
 
-	// Write the batch: try until backoff is not needed.
 	if doLoad {
-		var err error
-		_, err = p.ilpConn.Write(batch.buf.Bytes())
+		_, err := p.ilpConn.Write(batch.buf.Bytes())
 		if err != nil {
-			fatal("Error writing: %s\n", err.Error())
+			return 0, 0, err
 		}
 	}
 
@@ -47,5 +55,5 @@ func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64)
 	// Return the batch buffer to the pool.
 	batch.buf.Reset()
 	bufPool.Put(batch.buf)
-	return metricCnt, uint64(rowCnt)
+	return metricCnt, uint64(rowCnt), nil
 }
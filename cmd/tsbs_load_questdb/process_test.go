@@ -49,7 +49,7 @@ func mockServerStart() *mockServer {
 					rc, err := conn.Read(data)
 					if err != nil {
 						if err != io.EOF {
-							fatal("failed to read from connection: ", err.Error())
+							fatal("failed to read from connection: %s\n", err.Error())
 						}
 						return
 					}
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"strings"
 
+	"github.com/timescale/tsbs/load"
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
 	"github.com/timescale/tsbs/pkg/targets"
@@ -15,6 +16,7 @@ const errNotThreeTuplesFmt = "parse error: line does not have 3 tuples, has %d"
 var newLine = []byte("\n")
 
 type fileDataSource struct {
+	file    *load.DataFile
 	scanner *bufio.Scanner
 }
 
@@ -63,3 +65,18 @@ type factory struct{}
 func (f *factory) New() targets.Batch {
 	return &batch{buf: bufPool.Get().(*bytes.Buffer)}
 }
+
+// Offset returns the byte offset of the next item in the data file
+func (d *fileDataSource) Offset() int64 {
+	return d.file.Offset()
+}
+
+// SeekTo makes the item at offset in the data file the next one
+func (d *fileDataSource) SeekTo(offset int64) error {
+	scanner, err := d.file.SeekTo(offset)
+	if err != nil {
+		return err
+	}
+	d.scanner = scanner
+	return nil
+}
@@ -0,0 +1,146 @@
+// tsbs_query_convert converts query files between the gob format written by
+// tsbs_generate_queries and the JSON-lines format, one JSON object per query.
+//
+// The JSON-lines format can be inspected, grepped and edited by hand, e.g. to add
+// a regression query to an existing workload before converting it back to gob.
+// The query runners also read it directly with --input-format=jsonl.
+//
+// The queries are read from the files given as arguments, one after the other,
+// or from stdin, and written to stdout or to --output.
+package main
+
+import (
+	"bufio"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/blagojts/viper"
+	"github.com/globalsign/mgo/bson"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/internal/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// Program option vars:
+var (
+	queryType  string
+	fromFormat string
+	toFormat   string
+	outputFile string
+	inputFiles []string
+)
+
+// Parse args:
+func init() {
+	// needed for (de)serializing the mongo queries from gob
+	gob.Register([]interface{}{})
+	gob.Register(map[string]interface{}{})
+	gob.Register([]map[string]interface{}{})
+	gob.Register(bson.M{})
+	gob.Register([]bson.M{})
+
+	pflag.String("query-type", "", "Type of the queries: "+strings.Join(query.QueryTypes(), ", ")+" (influx, akumuli, questdb and victoriametrics use http)")
+	pflag.String("from", query.FormatGob, "Format of the queries read: 'gob' or 'jsonl'")
+	pflag.String("to", query.FormatJSONL, "Format of the queries written: 'gob' or 'jsonl'")
+	pflag.String("output", "", "File to write the queries to, stdout if empty")
+
+	pflag.Parse()
+
+	err := utils.SetupConfigFile()
+
+	if err != nil {
+		panic(fmt.Errorf("fatal error config file: %s", err))
+	}
+
+	queryType = viper.GetString("query-type")
+	fromFormat = viper.GetString("from")
+	toFormat = viper.GetString("to")
+	outputFile = viper.GetString("output")
+	inputFiles = pflag.Args()
+}
+
+func main() {
+	if err := convert(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func convert() error {
+	pool, err := query.QueryPool(queryType)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if outputFile != "" {
+		f, err := os.Create(outputFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	bw := bufio.NewWriter(out)
+	enc, err := query.NewEncoder(toFormat, bw)
+	if err != nil {
+		return err
+	}
+
+	n := 0
+	if len(inputFiles) == 0 {
+		if n, err = convertFile(os.Stdin, "stdin", pool, enc); err != nil {
+			return err
+		}
+	}
+	for _, fileName := range inputFiles {
+		f, err := os.Open(fileName)
+		if err != nil {
+			return err
+		}
+		converted, err := convertFile(f, fileName, pool, enc)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		n += converted
+	}
+	if err = bw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "converted %d queries from %s to %s\n", n, fromFormat, toFormat)
+	return nil
+}
+
+// convertFile writes the queries of a file with enc, decoding each one into a
+// query of pool. It returns the number of queries converted.
+func convertFile(r io.Reader, name string, pool *sync.Pool, enc query.Encoder) (int, error) {
+	dec, err := query.NewDecoder(fromFormat, bufio.NewReader(r))
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for {
+		// the decoders leave alone the fields missing or zero in the input,
+		// so every query is decoded into a released one
+		q := pool.Get().(query.Query)
+		err = dec.Decode(q)
+		if err == io.EOF {
+			q.Release()
+			return n, nil
+		}
+		if err != nil {
+			return n, fmt.Errorf("cannot read query %d of %s: %v", n+1, name, err)
+		}
+		err = enc.Encode(q)
+		q.Release()
+		if err != nil {
+			return n, fmt.Errorf("cannot write query %d of %s: %v", n+1, name, err)
+		}
+		n++
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// convertString converts the queries of input from the format from to the
+// format to.
+func convertString(t *testing.T, input, from, to string) string {
+	fromFormat = from
+	var buf bytes.Buffer
+	enc, err := query.NewEncoder(to, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = convertFile(strings.NewReader(input), "input", &query.HTTPPool, enc); err != nil {
+		t.Fatalf("unexpected error converting from %s to %s: %v", from, to, err)
+	}
+	return buf.String()
+}
+
+func TestConvertFileRoundTrip(t *testing.T) {
+	// the fields of the queries differ, so a query decoded into the previous
+	// one would keep some of its fields
+	input := `{"type":"http","HumanLabel":"a","HumanDescription":"","Method":"POST","Path":"/a","Body":"payload","RawQuery":"","StartTimestamp":5,"EndTimestamp":6}
+{"type":"http","HumanLabel":"b","HumanDescription":"","Method":"GET","Path":"/b","Body":"","RawQuery":"","StartTimestamp":0,"EndTimestamp":0}
+`
+	gob := convertString(t, input, query.FormatJSONL, query.FormatGob)
+	if got := convertString(t, gob, query.FormatGob, query.FormatJSONL); got != input {
+		t.Errorf("the queries changed in the round trip:\ngot\n%s\nwant\n%s", got, input)
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -25,6 +26,8 @@ type HTTPClient struct {
 type HTTPClientDoOptions struct {
 	Debug          int
 	PrintResponses bool
+	// KeepResponse makes Do return the response body, it's discarded otherwise
+	KeepResponse bool
 }
 
 // NewHTTPClient creates a new HTTPClient.
@@ -37,16 +40,21 @@ func NewHTTPClient(host string) *HTTPClient {
 	}
 }
 
+// CloseIdleConnections closes the connections of the client not in use.
+func (w *HTTPClient) CloseIdleConnections() {
+	w.client.CloseIdleConnections()
+}
+
 // Do performs the action specified by the given Query. It uses fasthttp, and
 // tries to minimize heap allocations.
-func (w *HTTPClient) Do(q *query.HTTP, opts *HTTPClientDoOptions) (lag float64, err error) {
+func (w *HTTPClient) Do(ctx context.Context, q *query.HTTP, opts *HTTPClientDoOptions) (lag float64, body []byte, err error) {
 	// populate uri from the reusable byte slice:
 	w.uri = w.uri[:0]
 	w.uri = append(w.uri, w.Host...)
 	w.uri = append(w.uri, q.Path...)
 
 	// populate a request with data from the Query:
-	req, err := http.NewRequest(string(q.Method), string(w.uri), bytes.NewReader(q.Body))
+	req, err := http.NewRequestWithContext(ctx, string(q.Method), string(w.uri), bytes.NewReader(q.Body))
 	if err != nil {
 		panic(err)
 	}
@@ -55,22 +63,25 @@ func (w *HTTPClient) Do(q *query.HTTP, opts *HTTPClientDoOptions) (lag float64,
 	start := time.Now()
 	resp, err := w.client.Do(req)
 	if err != nil {
-		panic(err)
+		return 0, nil, fmt.Errorf("query execution error: %s", err)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		panic("http request did not return status 200 OK")
+		return 0, nil, fmt.Errorf("non-200 statuscode received: %d", resp.StatusCode)
 	}
 
+	var response bytes.Buffer
 	reader := bufio.NewReader(resp.Body)
 	buf := make([]byte, 8192)
 	for {
-		_, err = reader.Read(buf)
+		n, err := reader.Read(buf)
+		if opts != nil && (opts.KeepResponse || opts.PrintResponses) {
+			response.Write(buf[:n])
+		}
 		if err == io.EOF {
-			err = nil
 			break
 		} else if err != nil {
-			panic(err)
+			return 0, nil, fmt.Errorf("error while reading response body: %s", err)
 		}
 	}
 	lag = float64(time.Since(start).Nanoseconds()) / 1e6 // milliseconds
@@ -94,12 +105,15 @@ func (w *HTTPClient) Do(q *query.HTTP, opts *HTTPClientDoOptions) (lag float64,
 
 		// Pretty print JSON responses, if applicable:
 		if opts.PrintResponses {
-			_, err = io.Copy(os.Stderr, resp.Body)
+			_, err = os.Stderr.Write(response.Bytes())
 			if err != nil {
 				return
 			}
 		}
+		if opts.KeepResponse {
+			body = response.Bytes()
+		}
 	}
 
-	return lag, err
+	return lag, body, err
 }
@@ -6,7 +6,11 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/csv"
 	"fmt"
+	"strings"
 
 	"github.com/blagojts/viper"
 	"github.com/spf13/pflag"
@@ -63,18 +67,48 @@ func (p *processor) Init(workerNumber int) {
 	p.opts = &HTTPClientDoOptions{
 		Debug:          runner.DebugLevel(),
 		PrintResponses: runner.DoPrintResponses(),
+		KeepResponse:   runner.CaptureResults(),
 	}
 	url := endpoint
 	p.w = NewHTTPClient(url)
 }
 
-func (p *processor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
+// Close closes the idle connections left by the worker
+func (p *processor) Close() {
+	p.w.CloseIdleConnections()
+}
+
+func (p *processor) ProcessQuery(ctx context.Context, q query.Query, isWarm bool) ([]*query.Stat, error) {
 	hq := q.(*query.HTTP)
-	lag, err := p.w.Do(hq, p.opts)
+	lag, body, err := p.w.Do(ctx, hq, p.opts)
 	if err != nil {
 		return nil, err
 	}
+	if p.opts.KeepResponse && !isWarm {
+		rows, err := responseRows(body)
+		if err != nil {
+			return nil, err
+		}
+		runner.CaptureResult(q, rows)
+	}
 	stat := query.GetStat()
 	stat.Init(q.HumanLabelName(), lag)
 	return []*query.Stat{stat}, nil
 }
+
+// responseRows reads the rows of a response, the queries ask for the csv output format
+func responseRows(body []byte) ([][]interface{}, error) {
+	records, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the response: %v", err)
+	}
+	rows := make([][]interface{}, len(records))
+	for i, record := range records {
+		row := make([]interface{}, len(record))
+		for j, v := range record {
+			row[j] = strings.TrimSpace(v)
+		}
+		rows[i] = row
+	}
+	return rows, nil
+}
@@ -8,6 +8,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
@@ -123,7 +124,7 @@ func (p *processor) Init(workerNumber int) {
 	p.qe = NewHLQueryExecutor(session, csi, runner.DebugLevel())
 }
 
-func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+func (p *processor) ProcessQuery(ctx context.Context, q query.Query, isWarm bool) ([]*query.Stat, error) {
 	cq := q.(*query.Cassandra)
 	hlq := &HLQuery{*cq}
 	hlq.ForceUTC()
@@ -137,10 +138,21 @@ func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, err
 			labels[i] = append(l, " (warm)"...)
 		}
 	}
-	qpLagMs, reqLagMs, err := p.qe.Do(hlq, *p.opts)
+	qpLagMs, reqLagMs, results, err := p.qe.Do(ctx, hlq, *p.opts)
 	if err != nil {
 		return nil, err
 	}
+	if runner.CaptureResults() && !isWarm {
+		rows := make([][]interface{}, len(results))
+		for i, r := range results {
+			row := []interface{}{r.TimeInterval.Start()}
+			for _, v := range r.Values {
+				row = append(row, v)
+			}
+			rows[i] = row
+		}
+		runner.CaptureResult(q, rows)
+	}
 	// total stat
 	totalMs := qpLagMs + reqLagMs
 	stats := []*query.Stat{
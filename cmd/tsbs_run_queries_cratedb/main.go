@@ -68,7 +68,9 @@ func main() {
 		panic(err)
 	}
 	runner.Run(&query.CrateDBPool, func() query.Processor {
-		return processor
+		// every worker has its own connection
+		p := *processor
+		return &p
 	})
 }
 
@@ -84,7 +86,7 @@ type executorOptions struct {
 	printResponse bool
 }
 
-func newProcessor() (query.Processor, error) {
+func newProcessor() (*processor, error) {
 	connStr := fmt.Sprintf("host=%s port=%d user=%s password='%s' dbname=%s", hosts, port, user, pass, runner.DatabaseName())
 	connConfig, err := pgx.ParseConfig(connStr)
 	if err != nil {
@@ -108,11 +110,24 @@ func (p *processor) Init(workerNumber int) {
 	p.conn = conn
 }
 
-func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+// Close closes the connection of the worker
+func (p *processor) Close() {
+	p.conn.Close(context.Background())
+}
+
+func (p *processor) ProcessQuery(ctx context.Context, q query.Query, isWarm bool) ([]*query.Stat, error) {
 	// No need to run again for EXPLAIN
 	if isWarm && p.opts.showExplain {
 		return nil, nil
 	}
+	// pgx closes the connection when a query is cancelled
+	if p.conn.IsClosed() {
+		conn, err := pgx.ConnectConfig(ctx, p.connCfg)
+		if err != nil {
+			return nil, err
+		}
+		p.conn = conn
+	}
 	tq := q.(*query.CrateDB)
 
 	start := time.Now()
@@ -120,7 +135,7 @@ func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, err
 	if showExplain {
 		qry = "EXPLAIN ANALYZE " + qry
 	}
-	rows, err := p.conn.Query(context.Background(), qry)
+	rows, err := p.conn.Query(ctx, qry)
 	if err != nil {
 		return nil, err
 	}
@@ -128,14 +143,28 @@ func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, err
 	if p.opts.debug {
 		fmt.Println(qry)
 	}
-	if showExplain {
-		fmt.Printf("Explian Query:\n")
-		prettyPrintResponse(rows, tq)
-		fmt.Printf("\n-----------\n\n")
-	} else if p.opts.printResponse {
-		prettyPrintResponse(rows, tq)
-	}
 	defer rows.Close()
+	if capture := runner.CaptureResults() && !isWarm; capture || showExplain || p.opts.printResponse {
+		cols, result, err := scanRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		if showExplain {
+			fmt.Printf("Explian Query:\n")
+			prettyPrintResponse(tq, cols, result)
+			fmt.Printf("\n-----------\n\n")
+		} else if p.opts.printResponse {
+			prettyPrintResponse(tq, cols, result)
+		}
+		if capture {
+			runner.CaptureResult(q, result)
+		}
+	}
+	// the rows left are read by Close, a cancelled query fails there
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
 	took := float64(time.Since(start).Nanoseconds()) / 1e6
 	stat := query.GetStat()
@@ -147,10 +176,18 @@ func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, err
 // prettyPrintResponse prints a Query and its response in JSON format with two
 // keys: 'query' which has a value of the SQL used to generate the second key
 // 'results' which is an array of each row in the return set.
-func prettyPrintResponse(rows pgx.Rows, q *query.CrateDB) {
+func prettyPrintResponse(q *query.CrateDB, cols []string, rows [][]interface{}) {
 	resp := make(map[string]interface{})
 	resp["query"] = string(q.SqlQuery)
-	resp["results"] = mapRows(rows)
+	var results []map[string]interface{}
+	for _, values := range rows {
+		row := make(map[string]interface{})
+		for i, column := range cols {
+			row[column] = values[i]
+		}
+		results = append(results, row)
+	}
+	resp["results"] = results
 
 	line, err := json.MarshalIndent(resp, "", "  ")
 	if err != nil {
@@ -160,25 +197,28 @@ func prettyPrintResponse(rows pgx.Rows, q *query.CrateDB) {
 	fmt.Println(string(line) + "\n")
 }
 
-func mapRows(r pgx.Rows) []map[string]interface{} {
-	var rows []map[string]interface{}
-	cols := r.FieldDescriptions()
+// scanRows reads the column names and all the rows of a query response
+func scanRows(r pgx.Rows) ([]string, [][]interface{}, error) {
+	var cols []string
+	for _, field := range r.FieldDescriptions() {
+		cols = append(cols, string(field.Name))
+	}
+	var rows [][]interface{}
 	for r.Next() {
-		row := make(map[string]interface{})
 		values := make([]interface{}, len(cols))
 		for i := range values {
 			values[i] = new(interface{})
 		}
 
-		err := r.Scan(values...)
-		if err != nil {
-			panic(errors.Wrap(err, "error while reading values"))
+		if err := r.Scan(values...); err != nil {
+			return nil, nil, errors.Wrap(err, "error while reading values")
 		}
 
-		for i, column := range cols {
-			row[string(column.Name)] = *values[i].(*interface{})
+		row := make([]interface{}, len(cols))
+		for i := range values {
+			row[i] = *values[i].(*interface{})
 		}
 		rows = append(rows, row)
 	}
-	return rows
+	return cols, rows, r.Err()
 }
@@ -6,6 +6,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"strings"
@@ -81,12 +82,24 @@ func (p *processor) Init(workerNumber int) {
 	p.w = NewHTTPClient(url)
 }
 
-func (p *processor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
+// Close closes the idle connections left by the worker
+func (p *processor) Close() {
+	p.w.CloseIdleConnections()
+}
+
+func (p *processor) ProcessQuery(ctx context.Context, q query.Query, isWarm bool) ([]*query.Stat, error) {
 	hq := q.(*query.HTTP)
-	lag, err := p.w.Do(hq, p.opts)
+	lag, body, err := p.w.Do(ctx, hq, p.opts)
 	if err != nil {
 		return nil, err
 	}
+	if runner.CaptureResults() && !isWarm {
+		rows, err := responseRows(body)
+		if err != nil {
+			return nil, err
+		}
+		runner.CaptureResult(q, rows)
+	}
 	stat := query.GetStat()
 	stat.Init(q.HumanLabelName(), lag)
 	return []*query.Stat{stat}, nil
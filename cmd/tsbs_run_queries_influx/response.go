@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// response is a response of the InfluxDB query API, chunked responses are
+// a sequence of them
+type response struct {
+	Results []struct {
+		Series []struct {
+			Tags   map[string]string `json:"tags"`
+			Values [][]interface{}   `json:"values"`
+		} `json:"series"`
+		Error string `json:"error"`
+	} `json:"results"`
+	Error string `json:"error"`
+}
+
+// responseRows returns the rows of all the series in the response body,
+// each row starts with the values of the tags of its series, sorted by tag key
+func responseRows(body []byte) ([][]interface{}, error) {
+	var rows [][]interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	for {
+		var resp response
+		err := dec.Decode(&resp)
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid response: %v", err)
+		}
+		if resp.Error != "" {
+			return nil, fmt.Errorf("query error: %s", resp.Error)
+		}
+		for _, result := range resp.Results {
+			if result.Error != "" {
+				return nil, fmt.Errorf("query error: %s", result.Error)
+			}
+			for _, series := range result.Series {
+				keys := make([]string, 0, len(series.Tags))
+				for k := range series.Tags {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				for _, values := range series.Values {
+					row := make([]interface{}, 0, len(keys)+len(values))
+					for _, k := range keys {
+						row = append(row, series.Tags[k])
+					}
+					rows = append(rows, append(row, values...))
+				}
+			}
+		}
+	}
+}
@@ -5,9 +5,11 @@
 package main
 
 import (
+	"context"
 	"encoding/gob"
 	"fmt"
 	"log"
+	"sort"
 	"time"
 
 	"github.com/blagojts/viper"
@@ -18,6 +20,9 @@ import (
 	"github.com/timescale/tsbs/pkg/query"
 )
 
+// errCodeExceededTimeLimit is the error code of the queries stopped by the max time
+const errCodeExceededTimeLimit = 50
+
 // Program option vars:
 var (
 	daemonURL string
@@ -73,37 +78,58 @@ func main() {
 }
 
 type processor struct {
+	session    *mgo.Session
 	collection *mgo.Collection
 }
 
 func newProcessor() query.Processor { return &processor{} }
 
 func (p *processor) Init(workerNumber int) {
-	sess := session.Copy()
-	db := sess.DB(runner.DatabaseName())
+	p.session = session.Copy()
+	db := p.session.DB(runner.DatabaseName())
 	p.collection = db.C("point_data")
 }
 
-func (p *processor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
+// Close closes the copy of the session of the worker
+func (p *processor) Close() {
+	p.session.Close()
+}
+
+func (p *processor) ProcessQuery(ctx context.Context, q query.Query, isWarm bool) ([]*query.Stat, error) {
 	mq := q.(*query.Mongo)
 	start := time.Now().UnixNano()
 	pipe := p.collection.Pipe(mq.BsonDoc).AllowDiskUse()
+	// mgo has no context support, the server stops the query at the deadline
+	if deadline, ok := ctx.Deadline(); ok {
+		pipe = pipe.SetMaxTime(time.Until(deadline))
+	}
 	iter := pipe.Iter()
 	if runner.DebugLevel() > 0 {
 		fmt.Println(mq.BsonDoc)
 	}
 	var result map[string]interface{}
+	var rows [][]interface{}
+	capture := runner.CaptureResults() && !isWarm
 	cnt := 0
 	for iter.Next(&result) {
 		if runner.DoPrintResponses() {
 			fmt.Printf("ID %d: %v\n", q.GetID(), result)
 		}
+		if capture {
+			rows = append(rows, documentRow(nil, result))
+		}
 		cnt++
 	}
 	if runner.DebugLevel() > 0 {
 		fmt.Println(cnt)
 	}
 	err := iter.Close()
+	if qerr, ok := err.(*mgo.QueryError); ok && qerr.Code == errCodeExceededTimeLimit {
+		err = fmt.Errorf("%v: %w", err, context.DeadlineExceeded)
+	}
+	if capture && err == nil {
+		runner.CaptureResult(q, rows)
+	}
 
 	took := time.Now().UnixNano() - start
 	lag := float64(took) / 1e6 // milliseconds
@@ -111,3 +137,24 @@ func (p *processor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
 	stat.Init(q.HumanLabelName(), lag)
 	return []*query.Stat{stat}, err
 }
+
+// documentRow appends the values of a result document to row, sorted by key.
+// The values of embedded documents (e.g. a compound _id) are appended in place.
+func documentRow(row []interface{}, doc map[string]interface{}) []interface{} {
+	keys := make([]string, 0, len(doc))
+	for k := range doc {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		switch v := doc[k].(type) {
+		case bson.M:
+			row = documentRow(row, v)
+		case map[string]interface{}:
+			row = documentRow(row, v)
+		default:
+			row = append(row, v)
+		}
+	}
+	return row
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -58,16 +59,21 @@ func NewHTTPClient(host string) *HTTPClient {
 	}
 }
 
+// CloseIdleConnections closes the connections of the client not in use.
+func (w *HTTPClient) CloseIdleConnections() {
+	w.client.CloseIdleConnections()
+}
+
 // Do performs the action specified by the given Query. It uses fasthttp, and
-// tries to minimize heap allocations.
-func (w *HTTPClient) Do(q *query.HTTP, opts *HTTPClientDoOptions) (lag float64, err error) {
+// tries to minimize heap allocations. The body of the response is returned too.
+func (w *HTTPClient) Do(ctx context.Context, q *query.HTTP, opts *HTTPClientDoOptions) (lag float64, body []byte, err error) {
 	// populate uri from the reusable byte slice:
 	w.uri = w.uri[:0]
 	w.uri = append(w.uri, w.Host...)
 	w.uri = append(w.uri, q.Path...)
 
 	// populate a request with data from the Query:
-	req, err := http.NewRequest(string(q.Method), string(w.uri), nil)
+	req, err := http.NewRequestWithContext(ctx, string(q.Method), string(w.uri), nil)
 	if err != nil {
 		panic(err)
 	}
@@ -76,18 +82,17 @@ func (w *HTTPClient) Do(q *query.HTTP, opts *HTTPClientDoOptions) (lag float64,
 	start := time.Now()
 	resp, err := w.client.Do(req)
 	if err != nil {
-		panic(err)
+		return 0, nil, fmt.Errorf("query execution error: %s", err)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		panic("http request did not return status 200 OK")
+		return 0, nil, fmt.Errorf("non-200 statuscode received: %d", resp.StatusCode)
 	}
 
-	var body []byte
 	body, err = ioutil.ReadAll(resp.Body)
 
 	if err != nil {
-		panic(err)
+		return 0, nil, fmt.Errorf("error while reading response body: %s", err)
 	}
 
 	lag = float64(time.Since(start).Nanoseconds()) / 1e6 // milliseconds
@@ -129,5 +134,5 @@ func (w *HTTPClient) Do(q *query.HTTP, opts *HTTPClientDoOptions) (lag float64,
 		}
 	}
 
-	return lag, err
+	return lag, body, err
 }
@@ -6,6 +6,8 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -93,12 +95,24 @@ func (p *processor) Init(workerNumber int) {
 	p.w = NewHTTPClient(url)
 }
 
-func (p *processor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
+// Close closes the idle connections left by the worker
+func (p *processor) Close() {
+	p.w.CloseIdleConnections()
+}
+
+func (p *processor) ProcessQuery(ctx context.Context, q query.Query, isWarm bool) ([]*query.Stat, error) {
 	hq := q.(*query.HTTP)
-	lag, err := p.w.Do(hq, p.opts)
+	lag, body, err := p.w.Do(ctx, hq, p.opts)
 	if err != nil {
 		return nil, err
 	}
+	if runner.CaptureResults() && !isWarm {
+		rows, err := responseRows(body)
+		if err != nil {
+			return nil, err
+		}
+		runner.CaptureResult(q, rows)
+	}
 	stat := query.GetStat()
 	stat.Init(q.HumanLabelName(), lag)
 	return []*query.Stat{stat}, nil
@@ -117,6 +131,28 @@ type QueryResponse struct {
 	Error   string
 }
 
+// responseRows returns the rows of the dataset of a query response
+func responseRows(body []byte) ([][]interface{}, error) {
+	var resp QueryResponse
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&resp); err != nil {
+		return nil, fmt.Errorf("invalid response: %v", err)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("query error: %s", resp.Error)
+	}
+	rows := make([][]interface{}, 0, len(resp.Dataset))
+	for _, r := range resp.Dataset {
+		row, ok := r.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid row %v", r)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
 func execQuery(uriRoot string, query string) (QueryResponse, error) {
 	var qr QueryResponse
 	if strings.HasSuffix(uriRoot, "/") {
@@ -5,8 +5,10 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -175,7 +177,7 @@ func (p *processor) Init(numWorker int) {
 	}
 }
 
-func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+func (p *processor) ProcessQuery(ctx context.Context, q query.Query, isWarm bool) ([]*query.Stat, error) {
 
 	// No need to run again for EXPLAIN
 	if isWarm && p.opts.showExplain {
@@ -186,16 +188,13 @@ func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, err
 	start := time.Now()
 	qry := string(tq.SqlQuery)
 
-	var res interface{}
-	var err error
-
-	if siridbConnector.IsConnected() {
-		if res, err = siridbConnector.Query(qry, uint16(writeTimeout)); err != nil {
-			log.Fatal(err)
-		}
-	} else {
+	if !siridbConnector.IsConnected() {
 		log.Fatal("not even a single server is connected...")
 	}
+	res, err := runQuery(ctx, qry)
+	if err != nil {
+		return nil, err
+	}
 
 	if p.opts.debug {
 		fmt.Println(qry)
@@ -205,9 +204,77 @@ func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, err
 		fmt.Println("\n", res)
 	}
 
+	if runner.CaptureResults() && !isWarm {
+		runner.CaptureResult(q, resultRows(res))
+	}
+
 	took := float64(time.Since(start).Nanoseconds()) / 1e6
 	stat := query.GetStat()
 	stat.Init(q.HumanLabelName(), took)
 
 	return []*query.Stat{stat}, err
 }
+
+// runQuery runs qry until it returns or ctx is done. The connector has no
+// context support, a cancelled query keeps running in the background.
+func runQuery(ctx context.Context, qry string) (interface{}, error) {
+	type result struct {
+		res interface{}
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		res, err := siridbConnector.Query(qry, uint16(writeTimeout))
+		done <- result{res: res, err: err}
+	}()
+	select {
+	case r := <-done:
+		return r.res, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// resultRows flattens the points of every series of a query result to rows of
+// series name, time and value. The time precision of the database is ns.
+func resultRows(res interface{}) [][]interface{} {
+	series := make(map[string]interface{})
+	switch res := res.(type) {
+	case map[string]interface{}:
+		series = res
+	case map[interface{}]interface{}:
+		for k, v := range res {
+			series[fmt.Sprint(k)] = v
+		}
+	default:
+		return [][]interface{}{{res}}
+	}
+	names := make([]string, 0, len(series))
+	for name := range series {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var rows [][]interface{}
+	for _, name := range names {
+		points, ok := series[name].([]interface{})
+		if !ok {
+			rows = append(rows, []interface{}{name, series[name]})
+			continue
+		}
+		for _, point := range points {
+			p, ok := point.([]interface{})
+			if !ok || len(p) != 2 {
+				rows = append(rows, []interface{}{name, point})
+				continue
+			}
+			ts, err := strconv.ParseInt(fmt.Sprint(p[0]), 10, 64)
+			if err != nil {
+				rows = append(rows, []interface{}{name, p[0], p[1]})
+				continue
+			}
+			rows = append(rows, []interface{}{name, time.Unix(0, ts), p[1]})
+		}
+	}
+	return rows
+}
@@ -7,10 +7,12 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/aws/aws-sdk-go/service/timestreamquery"
 	"github.com/timescale/tsbs/pkg/targets/timestream"
+	"log"
 	"time"
 
 	"github.com/blagojts/viper"
@@ -23,8 +25,8 @@ import (
 
 // Program option vars:
 var (
-	awsRegion    string
-	queryTimeout time.Duration
+	awsRegion   string
+	dialTimeout time.Duration
 )
 
 // Global vars:
@@ -38,7 +40,7 @@ func init() {
 	config.AddToFlagSet(pflag.CommandLine)
 
 	pflag.String("aws-region", "us-east-1", "Region where the database is")
-	pflag.Duration("query-timeout", time.Minute, "Configuration for aws sdk client to timeout after")
+	pflag.Duration("dial-timeout", time.Minute, "Timeout of the aws sdk client when connecting to the database")
 	pflag.Parse()
 
 	err := utils.SetupConfigFile()
@@ -51,10 +53,22 @@ func init() {
 	}
 
 	awsRegion = viper.GetString("aws-region")
-	queryTimeout = viper.GetDuration("query-timeout")
+	dialTimeout = viper.GetDuration("dial-timeout")
+	// --query-timeout used to be the timeout of the aws sdk client, it keeps
+	// setting it unless --dial-timeout is set
+	if isSet("query-timeout") && !isSet("dial-timeout") {
+		log.Println("warning: --query-timeout as the timeout of the aws sdk client is deprecated, use --dial-timeout. " +
+			"--query-timeout now also cancels the queries running for longer")
+		dialTimeout = config.QueryTimeout
+	}
 	runner = query.NewBenchmarkRunner(config)
 }
 
+// isSet returns whether the flag name is set on the command line or in the config file
+func isSet(name string) bool {
+	return pflag.CommandLine.Changed(name) || viper.InConfig(name)
+}
+
 func main() {
 	runner.Run(&query.TimestreamPool, newProcessor)
 }
@@ -91,6 +105,21 @@ func mapRows(page *timestreamquery.QueryOutput) []map[string]string {
 	return rows
 }
 
+// pageRows returns the scalar values of the rows of a page, nil for null values
+func pageRows(page *timestreamquery.QueryOutput) [][]interface{} {
+	rows := make([][]interface{}, len(page.Rows))
+	for i, row := range page.Rows {
+		values := make([]interface{}, len(row.Data))
+		for j, val := range row.Data {
+			if val.ScalarValue != nil {
+				values[j] = *val.ScalarValue
+			}
+		}
+		rows[i] = values
+	}
+	return rows
+}
+
 type queryExecutorOptions struct {
 	showExplain   bool
 	debug         bool
@@ -107,7 +136,7 @@ func newProcessor() query.Processor {
 }
 
 func (p *processor) Init(_ int) {
-	awsSession, err := timestream.OpenAWSSession(&awsRegion, queryTimeout)
+	awsSession, err := timestream.OpenAWSSession(&awsRegion, dialTimeout)
 	if err != nil {
 		panic("could not open aws session")
 	}
@@ -118,7 +147,7 @@ func (p *processor) Init(_ int) {
 	}
 }
 
-func (p *processor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
+func (p *processor) ProcessQuery(ctx context.Context, q query.Query, isWarm bool) ([]*query.Stat, error) {
 	tq := q.(*query.Timestream)
 
 	start := time.Now()
@@ -133,7 +162,9 @@ func (p *processor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
 	}
 	totalRows := 0
 	pageNum := 1
-	err := p._readSvc.QueryPages(queryInput,
+	capture := runner.CaptureResults() && !isWarm
+	var rows [][]interface{}
+	err := p._readSvc.QueryPagesWithContext(ctx, queryInput,
 		func(page *timestreamquery.QueryOutput, lastPage bool) bool {
 			// process query response
 			// making sure all the returned data is read
@@ -141,6 +172,9 @@ func (p *processor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
 			if p._opts.printResponse {
 				prettyPrintResponse(qry, page, pageNum)
 			}
+			if capture {
+				rows = append(rows, pageRows(page)...)
+			}
 			pageNum++
 			// return true to continue to next page
 			return true
@@ -151,6 +185,9 @@ func (p *processor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
 	if p._opts.debug {
 		fmt.Printf("Total rows: %d\n", totalRows)
 	}
+	if capture {
+		runner.CaptureResult(q, rows)
+	}
 	took := float64(time.Since(start).Nanoseconds()) / 1e6
 	stat := query.GetStat()
 	stat.Init(q.HumanLabelName(), took)
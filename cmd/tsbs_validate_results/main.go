@@ -0,0 +1,92 @@
+// tsbs_validate_results compares the query results captured by the
+// tsbs_run_queries_* programs with --capture-results.
+//
+// The expected file is either the capture of another database, run with the
+// same query file, or a golden file checked in earlier. The queries are matched
+// by ID. It exits with 1 when the results differ and with 2 on errors.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/internal/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// Program option vars:
+var (
+	expectedFile string
+	actualFile   string
+	precision    int
+	strict       bool
+	maxDiffs     int
+)
+
+// Parse args:
+func init() {
+	pflag.String("expected", "", "File with the expected results (golden file or capture of the reference database)")
+	pflag.String("actual", "", "File with the results to check")
+	pflag.Int("precision", 0, "Significant digits to compare numbers with, 0 compares them as captured")
+	pflag.Bool("strict", false, "Whether results of queries missing from the expected file are differences")
+	pflag.Int("max-diffs", 10, "Maximum number of differences to print, 0 prints all of them")
+
+	pflag.Parse()
+
+	err := utils.SetupConfigFile()
+
+	if err != nil {
+		panic(fmt.Errorf("fatal error config file: %s", err))
+	}
+
+	expectedFile = viper.GetString("expected")
+	actualFile = viper.GetString("actual")
+	precision = viper.GetInt("precision")
+	strict = viper.GetBool("strict")
+	maxDiffs = viper.GetInt("max-diffs")
+}
+
+func main() {
+	if expectedFile == "" || actualFile == "" {
+		fmt.Fprintln(os.Stderr, "both --expected and --actual are required")
+		os.Exit(2)
+	}
+	expected, err := readResults(expectedFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(2)
+	}
+	actual, err := readResults(actualFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(2)
+	}
+
+	diffs := query.DiffResults(expected, actual, precision, strict)
+	for i, d := range diffs {
+		if maxDiffs > 0 && i == maxDiffs {
+			fmt.Printf("... and %d more\n", len(diffs)-maxDiffs)
+			break
+		}
+		fmt.Printf("query %d (%s): %s\n", d.ID, d.Label, d.Reason)
+	}
+	fmt.Printf("%d queries compared, %d differ\n", len(expected), len(diffs))
+	if len(diffs) > 0 {
+		os.Exit(1)
+	}
+}
+
+func readResults(fileName string) (map[uint64]*query.Result, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	results, err := query.ReadResults(f)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %v", fileName, err)
+	}
+	return results, nil
+}
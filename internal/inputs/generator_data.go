@@ -18,8 +18,9 @@ import (
 
 // Error messages when using a DataGenerator
 const (
-	ErrNoConfig          = "no GeneratorConfig provided"
-	ErrInvalidDataConfig = "invalid config: DataGenerator needs a DataGeneratorConfig"
+	ErrNoConfig            = "no GeneratorConfig provided"
+	ErrInvalidDataConfig   = "invalid config: DataGenerator needs a DataGeneratorConfig"
+	errGeneratorWorkersFmt = "use case '%s' can not be generated with more than one generator worker"
 )
 
 // DataGenerator is a type of Generator for creating data that will be consumed
@@ -78,7 +79,15 @@ func (g *DataGenerator) Generate(config common.GeneratorConfig, target targets.I
 		return err
 	}
 
+	if g.config.GeneratorWorkers > 1 {
+		return g.generateShards(scfg, target)
+	}
+
 	sim := scfg.NewSimulator(g.config.LogInterval, g.config.Limit)
+	if g.config.RealTime {
+		// flush what is due before waiting, for the readers of the output
+		sim = common.NewRealTimeSimulator(sim, func() { g.bufOut.Flush() })
+	}
 	serializer, err := g.getSerializer(sim, target)
 	if err != nil {
 		return err
@@ -98,7 +107,11 @@ func (g *DataGenerator) CreateSimulator(config *common.DataGeneratorConfig) (com
 		return nil, err
 	}
 
-	return scfg.NewSimulator(g.config.LogInterval, g.config.Limit), nil
+	sim := scfg.NewSimulator(g.config.LogInterval, g.config.Limit)
+	if g.config.RealTime {
+		sim = common.NewRealTimeSimulator(sim, nil)
+	}
+	return sim, nil
 }
 
 func (g *DataGenerator) runSimulator(sim common.Simulator, serializer serialize.PointSerializer, dgc *common.DataGeneratorConfig) error {
@@ -128,15 +141,21 @@ func (g *DataGenerator) runSimulator(sim common.Simulator, serializer serialize.
 }
 
 func (g *DataGenerator) getSerializer(sim common.Simulator, target targets.ImplementedTarget) (serialize.PointSerializer, error) {
+	g.writeTargetHeader(target, sim.Headers)
+	return target.Serializer(), nil
+}
+
+// writeTargetHeader writes the headers of the points for the formats that
+// start with them
+func (g *DataGenerator) writeTargetHeader(target targets.ImplementedTarget, headers func() *common.GeneratedDataHeaders) {
 	switch target.TargetName() {
 	case constants.FormatCrateDB:
 		fallthrough
 	case constants.FormatClickhouse:
 		fallthrough
 	case constants.FormatTimescaleDB:
-		g.writeHeader(sim.Headers())
+		g.writeHeader(headers())
 	}
-	return target.Serializer(), nil
 }
 
 //TODO should be implemented in targets package
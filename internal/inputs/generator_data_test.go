@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -373,3 +374,62 @@ func (m *mockTarget) TargetSpecificFlags(string, *pflag.FlagSet) {
 func (m *mockTarget) TargetName() string {
 	return m.name
 }
+
+func TestDataGeneratorGenerateRealTime(t *testing.T) {
+	c := &common.DataGeneratorConfig{
+		BaseConfig: common.BaseConfig{
+			Seed:      123,
+			Format:    constants.FormatInflux,
+			Use:       common.UseCaseCPUOnly,
+			Scale:     2,
+			TimeStart: defaultTimeStart,
+			TimeEnd:   "2016-01-01T00:00:01Z",
+		},
+		LogInterval:          200 * time.Millisecond,
+		InterleavedNumGroups: 1,
+		RealTime:             true,
+	}
+	dg := &DataGenerator{Out: &bytes.Buffer{}}
+	serializer := &timestampSerializer{}
+	start := time.Now()
+	err := dg.Generate(c, &mockTarget{name: constants.FormatInflux, serializer: serializer})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 5 epochs, the last one is due 4 log intervals after the start
+	if took := time.Since(start); took < 800*time.Millisecond {
+		t.Errorf("real-time generation took %v, less than the 800ms of data", took)
+	}
+	if len(serializer.timestamps) != 10 {
+		t.Fatalf("got %d points want 10", len(serializer.timestamps))
+	}
+	first := serializer.timestamps[0]
+	if first.Before(start) || first.After(start.Add(time.Second)) {
+		t.Errorf("first point not stamped with the wall-clock time: %v", first)
+	}
+
+	// the merged points of several workers are paced the same way
+	c.GeneratorWorkers = 2
+	var buf bytes.Buffer
+	dg = &DataGenerator{Out: &buf}
+	start = time.Now()
+	if err = dg.Generate(c, &mockTarget{name: constants.FormatInflux, serializer: &textSerializer{}}); err != nil {
+		t.Fatalf("unexpected error with several workers: %v", err)
+	}
+	if took := time.Since(start); took < 800*time.Millisecond {
+		t.Errorf("real-time generation with several workers took %v, less than the 800ms of data", took)
+	}
+	if got := strings.Count(buf.String(), "\n"); got != 10 {
+		t.Errorf("got %d points with several workers want 10", got)
+	}
+}
+
+// timestampSerializer keeps the timestamps of the points
+type timestampSerializer struct {
+	timestamps []time.Time
+}
+
+func (s *timestampSerializer) Serialize(p *data.Point, w io.Writer) error {
+	s.timestamps = append(s.timestamps, *p.Timestamp())
+	return nil
+}
@@ -2,7 +2,6 @@ package inputs
 
 import (
 	"bufio"
-	"encoding/gob"
 	"fmt"
 	"io"
 	"math/rand"
@@ -13,6 +12,7 @@ import (
 	queryUtils "github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
 	internalUtils "github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/query"
 	"github.com/timescale/tsbs/pkg/query/config"
 	"github.com/timescale/tsbs/pkg/query/factories"
 )
@@ -40,6 +40,11 @@ type IoTGeneratorMaker interface {
 	NewIoT(start, end time.Time, scale int) (queryUtils.QueryGenerator, error)
 }
 
+// KubernetesGeneratorMaker creates a query generator for kubernetes use case
+type KubernetesGeneratorMaker interface {
+	NewKubernetes(start, end time.Time, scale int) (queryUtils.QueryGenerator, error)
+}
+
 // QueryGenerator is a type of Generator for creating queries to test against a
 // database. The output is specific to the type of database (due to each using
 // different querying techniques, e.g. SQL or REST), but is consumed by TSBS
@@ -158,7 +163,7 @@ func (g *QueryGenerator) addFactory(database string, factory interface{}) error
 	validFactory := false
 
 	switch factory.(type) {
-	case DevopsGeneratorMaker, IoTGeneratorMaker:
+	case DevopsGeneratorMaker, IoTGeneratorMaker, KubernetesGeneratorMaker:
 		validFactory = true
 	}
 
@@ -196,6 +201,13 @@ func (g *QueryGenerator) getUseCaseGenerator(c *config.QueryGeneratorConfig) (qu
 		}
 
 		return devopsFactory.NewDevops(g.tsStart, g.tsEnd, scale)
+	case common.UseCaseKubernetes:
+		kubernetesFactory, ok := factory.(KubernetesGeneratorMaker)
+		if !ok {
+			return nil, fmt.Errorf(errUseCaseNotImplementedFmt, c.Use, c.Format)
+		}
+
+		return kubernetesFactory.NewKubernetes(g.tsStart, g.tsEnd, scale)
 	default:
 		return nil, fmt.Errorf(errUnknownUseCaseFmt, c.Use)
 	}
@@ -204,7 +216,14 @@ func (g *QueryGenerator) getUseCaseGenerator(c *config.QueryGeneratorConfig) (qu
 func (g *QueryGenerator) runQueryGeneration(useGen queryUtils.QueryGenerator, filler queryUtils.QueryFiller, c *config.QueryGeneratorConfig) error {
 	stats := make(map[string]int64)
 	currentGroup := uint(0)
-	enc := gob.NewEncoder(g.bufOut)
+	format := c.OutputFormat
+	if format == "" {
+		format = query.FormatGob
+	}
+	enc, err := query.NewEncoder(format, g.bufOut)
+	if err != nil {
+		return err
+	}
 	defer g.bufOut.Flush()
 
 	rand.Seed(g.conf.Seed)
@@ -21,6 +21,7 @@ import (
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases/questdb"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases/siridb"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases/timescaledb"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases/victoriametrics"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
 	queryUtils "github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
 	internalUtils "github.com/timescale/tsbs/internal/utils"
@@ -373,6 +374,51 @@ func TestGetUseCaseGenerator(t *testing.T) {
 	}
 }
 
+func TestGetUseCaseGeneratorKubernetes(t *testing.T) {
+	tsStart, _ := internalUtils.ParseUTCTime(defaultTimeStart)
+	tsEnd, _ := internalUtils.ParseUTCTime(defaultTimeEnd)
+	c := &config.QueryGeneratorConfig{
+		BaseConfig: common.BaseConfig{
+			Use:       common.UseCaseKubernetes,
+			Scale:     10,
+			TimeStart: defaultTimeStart,
+			TimeEnd:   defaultTimeEnd,
+		},
+	}
+	g := &QueryGenerator{
+		conf:    c,
+		tsStart: tsStart,
+		tsEnd:   tsEnd,
+		factories: map[string]interface{}{
+			constants.FormatInflux:          &influx.BaseGenerator{},
+			constants.FormatTimescaleDB:     &timescaledb.BaseGenerator{},
+			constants.FormatVictoriaMetrics: &victoriametrics.BaseGenerator{},
+			constants.FormatClickhouse:      &clickhouse.BaseGenerator{},
+		},
+	}
+
+	for format, want := range map[string]queryUtils.QueryGenerator{
+		constants.FormatInflux:          &influx.Kubernetes{},
+		constants.FormatTimescaleDB:     &timescaledb.Kubernetes{},
+		constants.FormatVictoriaMetrics: &victoriametrics.Kubernetes{},
+	} {
+		c.Format = format
+		useGen, err := g.getUseCaseGenerator(c)
+		if err != nil {
+			t.Errorf("unexpected error with format '%s': %v", format, err)
+		}
+		if got, wantType := reflect.TypeOf(useGen), reflect.TypeOf(want); got != wantType {
+			t.Errorf("format '%s' does not give right use case gen: got %v want %v", format, got, wantType)
+		}
+	}
+
+	c.Format = constants.FormatClickhouse
+	_, err := g.getUseCaseGenerator(c)
+	if want := fmt.Sprintf(errUseCaseNotImplementedFmt, c.Use, c.Format); err == nil || err.Error() != want {
+		t.Errorf("incorrect error for a format without kubernetes queries: got %v want %s", err, want)
+	}
+}
+
 // Decoded previously
 var wantQueries = []query.TimescaleDB{
 	{
@@ -543,6 +589,50 @@ func TestQueryGeneratorRunQueryGeneration(t *testing.T) {
 	}
 }
 
+func TestQueryGeneratorRunQueryGenerationJSONL(t *testing.T) {
+	config, g := getTestConfigAndGenerator()
+	config.OutputFormat = query.FormatJSONL
+	err := g.init(config)
+	if err != nil {
+		t.Fatalf("Error initializing query generator: %s", err)
+	}
+
+	var buf bytes.Buffer
+	g.bufOut = bufio.NewWriter(&buf)
+	g.DebugOut = ioutil.Discard
+
+	useGen, err := g.getUseCaseGenerator(config)
+	if err != nil {
+		t.Fatalf("could not get use case gen: %v", err)
+	}
+	filler := g.useCaseMatrix[config.Use][config.QueryType](useGen)
+	if err = g.runQueryGeneration(useGen, filler, config); err != nil {
+		t.Fatalf("unexpected error: got %v", err)
+	}
+
+	if lines := strings.Count(buf.String(), "\n"); lines != len(wantQueries) {
+		t.Errorf("incorrect number of lines: got %d want %d", lines, len(wantQueries))
+	}
+	decoder, err := query.NewDecoder(query.FormatJSONL, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range wantQueries {
+		var q query.TimescaleDB
+		if err = decoder.Decode(&q); err != nil {
+			t.Fatalf("unexpected error while decoding: got %v", err)
+		}
+		if got, want := string(q.SqlQuery), string(wantQueries[i].SqlQuery); got != want {
+			t.Errorf("incorrect query:\ngot\n%s\nwant\n%s", got, want)
+		}
+	}
+
+	config.OutputFormat = "xml"
+	if err = g.runQueryGeneration(useGen, filler, config); err == nil {
+		t.Errorf("unknown output format did not error")
+	}
+}
+
 type badWriter struct {
 	when  int
 	count int
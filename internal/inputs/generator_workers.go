@@ -0,0 +1,269 @@
+package inputs
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/serialize"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// chunkSize is the number of points a generator worker simulates and
+// serializes before handing them over to be merged
+const chunkSize = 1000
+
+// chunksPerWorker is the number of chunks a generator worker can fill ahead
+// of the merge
+const chunksPerWorker = 2
+
+// pointChunk is a run of consecutive points of a shard serialized into buf,
+// point i ends at ends[i] in buf
+type pointChunk struct {
+	times []time.Time
+	ends  []int
+	// written tells whether the simulator returned the point to be written,
+	// the points that are not only count towards the limit
+	written []bool
+	buf     bytes.Buffer
+}
+
+func newPointChunk() *pointChunk {
+	return &pointChunk{
+		times:   make([]time.Time, 0, chunkSize),
+		ends:    make([]int, 0, chunkSize),
+		written: make([]bool, 0, chunkSize),
+	}
+}
+
+func (c *pointChunk) reset() {
+	c.times = c.times[:0]
+	c.ends = c.ends[:0]
+	c.written = c.written[:0]
+	c.buf.Reset()
+}
+
+// generatorWorker simulates and serializes a shard of the generators
+type generatorWorker struct {
+	sim        common.Simulator
+	serializer serialize.PointSerializer
+	chunks     chan *pointChunk
+	free       chan *pointChunk
+	// err is the error the worker stopped with, set before chunks is closed
+	err error
+
+	// the next point of the shard to merge is point i of chunk c, which
+	// starts at start in its buffer. c is nil once the worker is done.
+	c     *pointChunk
+	i     int
+	start int
+}
+
+func newGeneratorWorker(sim common.Simulator, serializer serialize.PointSerializer) *generatorWorker {
+	w := &generatorWorker{
+		sim:        sim,
+		serializer: serializer,
+		chunks:     make(chan *pointChunk, chunksPerWorker),
+		free:       make(chan *pointChunk, chunksPerWorker),
+	}
+	for i := 0; i < chunksPerWorker; i++ {
+		w.free <- newPointChunk()
+	}
+	return w
+}
+
+// run fills the chunks with the points of the shard until the simulator is
+// finished or stop is closed.
+func (w *generatorWorker) run(stop <-chan struct{}) {
+	defer close(w.chunks)
+
+	p := data.NewPoint()
+	// the points returned not to be written can lack a timestamp, they are
+	// merged at the time of the previous point
+	var last time.Time
+	for !w.sim.Finished() {
+		var c *pointChunk
+		select {
+		case c = <-w.free:
+		case <-stop:
+			return
+		}
+		c.reset()
+		for len(c.ends) < chunkSize && !w.sim.Finished() {
+			p.Reset()
+			write := w.sim.Next(p)
+			if ts := p.Timestamp(); ts != nil {
+				last = *ts
+			}
+			if write {
+				if err := w.serializer.Serialize(p, &c.buf); err != nil {
+					w.err = fmt.Errorf("can not serialize point: %s", err)
+					return
+				}
+			}
+			c.times = append(c.times, last)
+			c.ends = append(c.ends, c.buf.Len())
+			c.written = append(c.written, write)
+		}
+		select {
+		case w.chunks <- c:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// receive moves to the first point of the next chunk of the worker.
+func (w *generatorWorker) receive() error {
+	for {
+		c, ok := <-w.chunks
+		if !ok {
+			w.c = nil
+			return w.err
+		}
+		if len(c.ends) > 0 {
+			w.c, w.i, w.start = c, 0, 0
+			return nil
+		}
+		w.free <- c
+	}
+}
+
+// advance moves to the next point of the worker, handing the chunk back once
+// all its points are merged.
+func (w *generatorWorker) advance() error {
+	w.start = w.c.ends[w.i]
+	w.i++
+	if w.i < len(w.c.ends) {
+		return nil
+	}
+	// the worker holds the other chunks, so this never blocks
+	w.free <- w.c
+	return w.receive()
+}
+
+// shardRands returns the Rands of the shards of a seed, so the output of a
+// seed and number of generator workers is always the same.
+func shardRands(seed int64, shards int) []common.Rand {
+	rands := make([]common.Rand, shards)
+	for i := range rands {
+		rands[i] = rand.New(rand.NewSource(seed + int64(i)))
+	}
+	return rands
+}
+
+// shardHeaders returns the headers of the points of all the shards: the tags
+// of the first one and the longest list of fields of every measurement, since
+// the devops-generic hosts have a varying number of them.
+func shardHeaders(sims []common.Simulator) *common.GeneratedDataHeaders {
+	headers := sims[0].Headers()
+	for _, sim := range sims[1:] {
+		for m, fields := range sim.Fields() {
+			if len(fields) > len(headers.FieldKeys[m]) {
+				headers.FieldKeys[m] = fields
+			}
+		}
+	}
+	return headers
+}
+
+// generateShards generates the data with the generators of the config split
+// between dgc.GeneratorWorkers shards, see runGeneratorWorkers.
+func (g *DataGenerator) generateShards(scfg common.SimulatorConfig, target targets.ImplementedTarget) error {
+	var sims []common.Simulator
+	if sc, ok := scfg.(common.ShardableSimulatorConfig); ok {
+		rands := shardRands(g.config.Seed, int(g.config.GeneratorWorkers))
+		sims = sc.NewShardSimulators(g.config.LogInterval, rands)
+	}
+	if len(sims) == 0 {
+		return fmt.Errorf(errGeneratorWorkersFmt, g.config.Use)
+	}
+	g.writeTargetHeader(target, func() *common.GeneratedDataHeaders { return shardHeaders(sims) })
+	return g.runGeneratorWorkers(sims, target.Serializer, g.config)
+}
+
+// runGeneratorWorkers is runSimulator with the generators split between
+// shards, each one simulated and serialized by a generator worker with its
+// own Rand and serializer. The serialized points of the shards are merged
+// in the order of their timestamps, the lowest shard first at the same time,
+// so the output is in time order like with a single worker, and always the
+// same for a seed and number of workers. The formats whose serializers keep
+// state between points implement serialize.ShardedPointSerializer.
+//
+// The limit, the interleaved groups and the real-time pacing apply to the
+// merged points.
+func (g *DataGenerator) runGeneratorWorkers(sims []common.Simulator, newSerializer func() serialize.PointSerializer, dgc *common.DataGeneratorConfig) error {
+	defer g.bufOut.Flush()
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer close(stop)
+
+	workers := make([]*generatorWorker, len(sims))
+	for i, sim := range sims {
+		serializer := newSerializer()
+		if s, ok := serializer.(serialize.ShardedPointSerializer); ok {
+			header := s.Shard(i, len(sims))
+			if i == 0 {
+				if _, err := g.bufOut.Write(header); err != nil {
+					return err
+				}
+			}
+		}
+		workers[i] = newGeneratorWorker(sim, serializer)
+	}
+	for _, w := range workers {
+		wg.Add(1)
+		go func(w *generatorWorker) {
+			defer wg.Done()
+			w.run(stop)
+		}(w)
+	}
+	for _, w := range workers {
+		if err := w.receive(); err != nil {
+			return err
+		}
+	}
+
+	var pacer *common.RealTimePacer
+	if dgc.RealTime {
+		// flush what is due before waiting, for the readers of the output
+		pacer = common.NewRealTimePacer(func() { g.bufOut.Flush() })
+	}
+
+	currGroupID := uint(0)
+	for made := uint64(0); dgc.Limit == 0 || made < dgc.Limit; made++ {
+		var next *generatorWorker
+		for _, w := range workers {
+			if w.c != nil && (next == nil || w.c.times[w.i].Before(next.c.times[next.i])) {
+				next = w
+			}
+		}
+		if next == nil {
+			return nil
+		}
+
+		c, i := next.c, next.i
+		if c.written[i] {
+			// in the default case this is always true
+			if currGroupID == dgc.InterleavedGroupID {
+				if pacer != nil {
+					pacer.Wait(c.times[i])
+				}
+				if _, err := g.bufOut.Write(c.buf.Bytes()[next.start:c.ends[i]]); err != nil {
+					return err
+				}
+			}
+			currGroupID = (currGroupID + 1) % dgc.InterleavedNumGroups
+		}
+		if err := next.advance(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
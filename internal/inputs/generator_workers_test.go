@@ -0,0 +1,200 @@
+package inputs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/serialize"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+)
+
+// textSerializer writes every part of a point, so outputs that differ in any
+// value, tag or timestamp differ
+type textSerializer struct{}
+
+func (s *textSerializer) Serialize(p *data.Point, w io.Writer) error {
+	_, err := fmt.Fprintf(w, "%s %v %v %v %v %d\n", p.MeasurementName(), p.TagKeys(), p.TagValues(),
+		p.FieldKeys(), p.FieldValues(), p.Timestamp().UnixNano())
+	return err
+}
+
+// shardSerializer is a textSerializer of a stateful format, which prefixes
+// the points with its shard
+type shardSerializer struct {
+	textSerializer
+	shard int
+}
+
+func (s *shardSerializer) Shard(shard, shards int) []byte {
+	s.shard = shard
+	return []byte(fmt.Sprintf("header of %d shards\n", shards))
+}
+
+func (s *shardSerializer) Serialize(p *data.Point, w io.Writer) error {
+	fmt.Fprintf(w, "%d ", s.shard)
+	return s.textSerializer.Serialize(p, w)
+}
+
+// newSerializerTarget is a mockTarget creating a new serializer for each
+// generator worker
+type newSerializerTarget struct {
+	mockTarget
+	newSerializer func() serialize.PointSerializer
+}
+
+func (t *newSerializerTarget) Serializer() serialize.PointSerializer {
+	return t.newSerializer()
+}
+
+func workersConfig(useCase string, workers uint) *common.DataGeneratorConfig {
+	return &common.DataGeneratorConfig{
+		BaseConfig: common.BaseConfig{
+			Seed:      123,
+			Format:    constants.FormatInflux,
+			Use:       useCase,
+			Scale:     9,
+			TimeStart: defaultTimeStart,
+			TimeEnd:   "2016-01-01T00:10:00Z",
+		},
+		InitialScale:          9,
+		LogInterval:           10 * time.Second,
+		InterleavedNumGroups:  1,
+		MaxMetricCountPerHost: 20,
+		GeneratorWorkers:      workers,
+	}
+}
+
+func generateWorkers(t *testing.T, c *common.DataGeneratorConfig) string {
+	var buf bytes.Buffer
+	dg := &DataGenerator{Out: &buf}
+	if err := dg.Generate(c, &mockTarget{name: c.Format, serializer: &textSerializer{}}); err != nil {
+		t.Fatalf("%s: unexpected error: %v", c.Use, err)
+	}
+	return buf.String()
+}
+
+// timestamps returns the timestamps of the lines of a textSerializer output
+func timestamps(t *testing.T, out string) []int64 {
+	var ts []int64
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		v, err := strconv.ParseInt(line[strings.LastIndex(line, " ")+1:], 10, 64)
+		if err != nil {
+			t.Fatalf("cannot parse timestamp of %q: %v", line, err)
+		}
+		ts = append(ts, v)
+	}
+	return ts
+}
+
+func TestDataGeneratorGenerateWorkers(t *testing.T) {
+	useCases := []string{
+		common.UseCaseDevops,
+		common.UseCaseCPUOnly,
+		common.UseCaseDevopsGeneric,
+		common.UseCaseIoT,
+		common.UseCaseKubernetes,
+	}
+	for _, useCase := range useCases {
+		want := generateWorkers(t, workersConfig(useCase, 4))
+		if got := generateWorkers(t, workersConfig(useCase, 4)); got != want {
+			t.Errorf("%s: output of the same seed and workers differs", useCase)
+		}
+		if got := generateWorkers(t, workersConfig(useCase, 1)); got == want {
+			t.Errorf("%s: output of 4 workers is the same as one worker", useCase)
+		}
+		ts := timestamps(t, want)
+		for i := 1; i < len(ts) && useCase != common.UseCaseIoT; i++ {
+			if ts[i] < ts[i-1] {
+				t.Errorf("%s: point %d is before the previous one", useCase, i)
+				break
+			}
+		}
+	}
+
+	// without disorder every point of a single worker is there
+	for _, useCase := range []string{common.UseCaseDevops, common.UseCaseCPUOnly, common.UseCaseKubernetes} {
+		one := strings.Count(generateWorkers(t, workersConfig(useCase, 1)), "\n")
+		if got := strings.Count(generateWorkers(t, workersConfig(useCase, 4)), "\n"); got != one {
+			t.Errorf("%s: got %d points with 4 workers want %d", useCase, got, one)
+		}
+	}
+
+	// more workers than hosts
+	c := workersConfig(common.UseCaseCPUOnly, 20)
+	if got, want := strings.Count(generateWorkers(t, c), "\n"), 9*60; got != want {
+		t.Errorf("got %d points with more workers than hosts want %d", got, want)
+	}
+
+	c = workersConfig(common.UseCaseDevops, 3)
+	c.Disorder = common.Disorder{LateChance: 0.1, MaxLateness: time.Minute, DuplicateChance: 0.1}
+	if generateWorkers(t, c) != generateWorkers(t, c) {
+		t.Errorf("output of the same seed and workers with disorder differs")
+	}
+}
+
+func TestDataGeneratorGenerateWorkersLimitAndGroups(t *testing.T) {
+	c := workersConfig(common.UseCaseCPUOnly, 3)
+	c.Limit = 100
+	all := generateWorkers(t, c)
+	if got := strings.Count(all, "\n"); got != 100 {
+		t.Errorf("got %d points want 100", got)
+	}
+
+	var groups string
+	c.InterleavedNumGroups = 3
+	for id := uint(0); id < 3; id++ {
+		c.InterleavedGroupID = id
+		out := generateWorkers(t, c)
+		if got := strings.Count(out, "\n"); got != 34-int(id+2)/3 {
+			t.Errorf("group %d: got %d points want %d", id, got, 34-int(id+2)/3)
+		}
+		groups += out
+	}
+	sorted := func(s string) string {
+		lines := strings.Split(s, "\n")
+		sort.Strings(lines)
+		return strings.Join(lines, "\n")
+	}
+	if sorted(groups) != sorted(all) {
+		t.Errorf("the points of the groups differ from the points of all of them")
+	}
+}
+
+func TestDataGeneratorGenerateWorkersShardedSerializer(t *testing.T) {
+	c := workersConfig(common.UseCaseCPUOnly, 3)
+	var buf bytes.Buffer
+	dg := &DataGenerator{Out: &buf}
+	target := &newSerializerTarget{
+		mockTarget:    mockTarget{name: constants.FormatAkumuli},
+		newSerializer: func() serialize.PointSerializer { return &shardSerializer{} },
+	}
+	if err := dg.Generate(c, target); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "header of 3 shards\n") || strings.Count(out, "header") != 1 {
+		t.Errorf("the header is not written once at the start")
+	}
+	for shard := 0; shard < 3; shard++ {
+		if !strings.Contains(out, fmt.Sprintf("\n%d cpu", shard)) {
+			t.Errorf("no points of shard %d", shard)
+		}
+	}
+}
+
+func TestDataGeneratorGenerateWorkersError(t *testing.T) {
+	c := workersConfig(common.UseCaseDevops, 3)
+	dg := &DataGenerator{Out: &bytes.Buffer{}}
+	err := dg.Generate(c, &mockTarget{name: constants.FormatInflux, serializer: &testSerializer{shouldError: true}})
+	if err == nil {
+		t.Errorf("unexpected lack of error")
+	}
+}
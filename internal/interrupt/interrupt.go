@@ -0,0 +1,51 @@
+// Package interrupt turns SIGINT and SIGTERM into a graceful stop of a
+// benchmark run, so an interrupted run still prints its summary and writes
+// its results for the work done so far.
+package interrupt
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// ExitCode is the exit code used when a second signal forces the process to quit
+const ExitCode = 130
+
+// change for testing
+var exitFn = os.Exit
+
+// Watch calls onInterrupt when the first SIGINT or SIGTERM is received, so the
+// run can stop reading input and drain the work in flight. A second signal exits
+// right away. The returned function stops watching and must be called once the run ended.
+func Watch(onInterrupt func()) (stop func()) {
+	signals := make(chan os.Signal, 2)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		defer close(finished)
+		interrupted := false
+		for {
+			select {
+			case sig := <-signals:
+				if interrupted {
+					_, _ = fmt.Fprintf(os.Stderr, "received %v again, exiting without waiting\n", sig)
+					exitFn(ExitCode)
+					return
+				}
+				interrupted = true
+				_, _ = fmt.Fprintf(os.Stderr, "received %v, finishing the work in flight (repeat to exit right away)\n", sig)
+				onInterrupt()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+		<-finished
+	}
+}
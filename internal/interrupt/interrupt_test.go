@@ -0,0 +1,45 @@
+package interrupt
+
+import (
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	var interrupted, exitCode int32
+	exitFn = func(code int) { atomic.StoreInt32(&exitCode, int32(code)) }
+	defer func() { exitFn = os.Exit }()
+
+	stop := Watch(func() { atomic.StoreInt32(&interrupted, 1) })
+	defer stop()
+
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Signal(os.Interrupt); err != nil {
+		t.Skipf("can't send signals on this platform: %v", err)
+	}
+	waitFor(t, func() bool { return atomic.LoadInt32(&interrupted) == 1 })
+	if got := atomic.LoadInt32(&exitCode); got != 0 {
+		t.Errorf("first signal should not exit, got exit code %d", got)
+	}
+
+	if err := p.Signal(os.Interrupt); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return atomic.LoadInt32(&exitCode) == ExitCode })
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("condition not met in time")
+}
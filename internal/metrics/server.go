@@ -0,0 +1,35 @@
+// Package metrics exposes the live progress of a benchmark run in the
+// Prometheus/OpenMetrics text format, so long runs can be observed by a
+// Prometheus server or with curl while they are still in progress.
+package metrics
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Path is the path on which the metrics are served
+const Path = "/metrics"
+
+// LatencyBuckets are the histogram buckets, in seconds, used for
+// batch and query latencies: from 100us up to ~100s
+var LatencyBuckets = prometheus.ExponentialBuckets(0.0001, 2, 21)
+
+// Serve starts an HTTP server on addr that serves the metrics collected by reg
+// on Path. The listener is opened before Serve returns, so an invalid or busy
+// address is reported right away; requests are then handled in the background.
+func Serve(addr string, reg *prometheus.Registry) (net.Listener, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle(Path, promhttp.HandlerFor(reg, promhttp.HandlerOpts{EnableOpenMetrics: true}))
+	go func() {
+		_ = http.Serve(ln, mux)
+	}()
+	return ln, nil
+}
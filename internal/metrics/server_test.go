@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestServe(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	c := prometheus.NewCounter(prometheus.CounterOpts{Name: "tsbs_test_total", Help: "test counter"})
+	reg.MustRegister(c)
+	c.Add(3)
+
+	ln, err := Serve("127.0.0.1:0", reg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer ln.Close()
+
+	resp, err := http.Get("http://" + ln.Addr().String() + Path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(body), "tsbs_test_total 3") {
+		t.Errorf("counter missing from the response:\n%s", body)
+	}
+
+	if _, err := Serve(ln.Addr().String(), reg); err == nil {
+		t.Errorf("expected an error when the address is already in use")
+	}
+}
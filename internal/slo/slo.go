@@ -0,0 +1,229 @@
+// Package slo checks the final stats of a benchmark run against assertions
+// given with --assert, e.g. "p99(cpu-max-all-1)<50ms" or "metricRate>500000",
+// so a CI job can fail on the exit code of the run instead of parsing its output.
+package slo
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// ExitCode is the exit code of a run with a failed assertion
+const ExitCode = 3
+
+// change for testing
+var exitFn = os.Exit
+
+// operators are the comparison operators, the two characters ones first
+var operators = []string{"<=", ">=", "==", "!=", "<", ">"}
+
+// Assertion compares a stat of a run with a threshold
+type Assertion struct {
+	// Expr is the assertion as written
+	Expr string
+	// Stat is the name of the stat, e.g. p99 or metricRate
+	Stat string
+	// Arg is the argument of the stat, e.g. the query label of p99(label),
+	// empty for the stat of the whole run
+	Arg string
+	Op  string
+	// Threshold is in the unit of the stat, the durations are converted to milliseconds
+	Threshold float64
+}
+
+// Parse parses an assertion: a stat, with an optional argument in parentheses,
+// an operator (<, <=, >, >=, == or !=) and a threshold. The threshold is a
+// number, a duration (e.g. 50ms or 1.5s, compared in milliseconds) or a
+// percentage (e.g. 1%, compared as a fraction).
+func Parse(expr string) (Assertion, error) {
+	a := Assertion{Expr: strings.TrimSpace(expr)}
+	rest := a.Expr
+	// the argument, if any, ends at the last parenthesis: labels can have some
+	if open := strings.Index(rest, "("); open >= 0 {
+		end := strings.LastIndex(rest, ")")
+		if end < open {
+			return a, fmt.Errorf("invalid assertion '%s': missing closing parenthesis", expr)
+		}
+		a.Stat = strings.TrimSpace(rest[:open])
+		a.Arg = strings.TrimSpace(rest[open+1 : end])
+		if a.Arg == "" {
+			return a, fmt.Errorf("invalid assertion '%s': empty argument", expr)
+		}
+		rest = rest[end+1:]
+	}
+	i, op := indexOperator(rest)
+	if i < 0 {
+		return a, fmt.Errorf("invalid assertion '%s': no operator, must be one of %s", expr, strings.Join(operators, " "))
+	}
+	a.Op = op
+	if a.Stat == "" {
+		a.Stat = strings.TrimSpace(rest[:i])
+	} else if strings.TrimSpace(rest[:i]) != "" {
+		return a, fmt.Errorf("invalid assertion '%s': unexpected '%s' after the argument", expr, strings.TrimSpace(rest[:i]))
+	}
+	if a.Stat == "" {
+		return a, fmt.Errorf("invalid assertion '%s': no stat", expr)
+	}
+	threshold, err := parseThreshold(strings.TrimSpace(rest[i+len(op):]))
+	if err != nil {
+		return a, fmt.Errorf("invalid assertion '%s': %v", expr, err)
+	}
+	a.Threshold = threshold
+	return a, nil
+}
+
+// ParseAll parses the assertions, empty ones are skipped
+func ParseAll(exprs []string) ([]Assertion, error) {
+	var assertions []Assertion
+	for _, expr := range exprs {
+		if strings.TrimSpace(expr) == "" {
+			continue
+		}
+		a, err := Parse(expr)
+		if err != nil {
+			return nil, err
+		}
+		assertions = append(assertions, a)
+	}
+	return assertions, nil
+}
+
+// indexOperator returns the position and the operator of the first operator of s
+func indexOperator(s string) (int, string) {
+	for i := range s {
+		for _, op := range operators {
+			if strings.HasPrefix(s[i:], op) {
+				return i, op
+			}
+		}
+	}
+	return -1, ""
+}
+
+func parseThreshold(s string) (float64, error) {
+	if s == "" {
+		return 0, fmt.Errorf("no threshold")
+	}
+	if strings.HasSuffix(s, "%") {
+		v, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid percentage '%s'", s)
+		}
+		return v / 100, nil
+	}
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return v, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid threshold '%s', must be a number, a duration or a percentage", s)
+	}
+	return float64(d.Nanoseconds()) / 1e6, nil
+}
+
+// Check reports whether the value of the stat satisfies the assertion
+func (a Assertion) Check(value float64) bool {
+	switch a.Op {
+	case "<":
+		return value < a.Threshold
+	case "<=":
+		return value <= a.Threshold
+	case ">":
+		return value > a.Threshold
+	case ">=":
+		return value >= a.Threshold
+	case "==":
+		return value == a.Threshold
+	case "!=":
+		return value != a.Threshold
+	}
+	return false
+}
+
+// Percentile returns the percentile (between 0 and 100) of a stat named pN,
+// e.g. 99.9 for p99.9. p999 is 99.9 as in the results of the loaders.
+func Percentile(stat string) (float64, bool) {
+	if !strings.HasPrefix(stat, "p") {
+		return 0, false
+	}
+	if stat == "p999" {
+		return 99.9, true
+	}
+	p, err := strconv.ParseFloat(stat[1:], 64)
+	if err != nil || p < 0 || p > 100 {
+		return 0, false
+	}
+	return p, true
+}
+
+// Resolver returns the value of the stat of an assertion at the end of a run
+type Resolver func(a Assertion) (float64, error)
+
+// Result is the outcome of an assertion, as saved in the results file
+type Result struct {
+	Assertion string  `json:"assertion"`
+	Value     float64 `json:"value"`
+	Passed    bool    `json:"passed"`
+	// Error is why the stat has no value (e.g. an unknown label), the assertion fails
+	Error string `json:"error,omitempty"`
+}
+
+// Evaluate checks the assertions with the values of their stats
+func Evaluate(assertions []Assertion, resolve Resolver) []Result {
+	results := make([]Result, 0, len(assertions))
+	for _, a := range assertions {
+		r := Result{Assertion: a.Expr}
+		value, err := resolve(a)
+		if err != nil {
+			r.Error = err.Error()
+		} else {
+			r.Value = value
+			r.Passed = a.Check(value)
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// Failed returns the number of assertions that failed
+func Failed(results []Result) int {
+	n := 0
+	for _, r := range results {
+		if !r.Passed {
+			n++
+		}
+	}
+	return n
+}
+
+// Print writes the results as a table of the assertions with their value and outcome
+func Print(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "assertion\tvalue\tresult")
+	for _, r := range results {
+		value, result := strconv.FormatFloat(r.Value, 'f', 2, 64), "pass"
+		if r.Error != "" {
+			value, result = "-", "FAIL: "+r.Error
+		} else if !r.Passed {
+			result = "FAIL"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Assertion, value, result)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "%d of %d assertions failed\n", Failed(results), len(results))
+	return err
+}
+
+// ExitOnFailure exits with ExitCode if an assertion failed
+func ExitOnFailure(results []Result) {
+	if Failed(results) > 0 {
+		exitFn(ExitCode)
+	}
+}
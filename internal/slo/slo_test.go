@@ -0,0 +1,140 @@
+package slo
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		expr string
+		want Assertion
+	}{
+		{expr: "p99(single-groupby-1-1-1)<50ms", want: Assertion{Stat: "p99", Arg: "single-groupby-1-1-1", Op: "<", Threshold: 50}},
+		{expr: " metricRate > 500000 ", want: Assertion{Stat: "metricRate", Op: ">", Threshold: 500000}},
+		{expr: "errors==0", want: Assertion{Stat: "errors", Op: "==", Threshold: 0}},
+		{expr: "errorRate<=1%", want: Assertion{Stat: "errorRate", Op: "<=", Threshold: 0.01}},
+		{expr: "p99.9>=1.5s", want: Assertion{Stat: "p99.9", Op: ">=", Threshold: 1500}},
+		{expr: "timeouts!=2", want: Assertion{Stat: "timeouts", Op: "!=", Threshold: 2}},
+		{
+			expr: "mean(TimescaleDB 1 cpu metric(s), random 8 hosts) < 10",
+			want: Assertion{Stat: "mean", Arg: "TimescaleDB 1 cpu metric(s), random 8 hosts", Op: "<", Threshold: 10},
+		},
+	}
+	for _, c := range cases {
+		got, err := Parse(c.expr)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.expr, err)
+			continue
+		}
+		c.want.Expr = strings.TrimSpace(c.expr)
+		if got != c.want {
+			t.Errorf("%s: got %+v want %+v", c.expr, got, c.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		expr    string
+		wantErr string
+	}{
+		{expr: "p99", wantErr: "no operator"},
+		{expr: "<50ms", wantErr: "no stat"},
+		{expr: "p99(a<50ms", wantErr: "missing closing parenthesis"},
+		{expr: "p99()<50ms", wantErr: "empty argument"},
+		{expr: "p99(a)b<50ms", wantErr: "unexpected 'b'"},
+		{expr: "p99(a)<", wantErr: "no threshold"},
+		{expr: "p99(a)<fast", wantErr: "invalid threshold 'fast'"},
+		{expr: "errorRate<x%", wantErr: "invalid percentage"},
+	}
+	for _, c := range cases {
+		_, err := Parse(c.expr)
+		if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+			t.Errorf("%s: got error %v want %s", c.expr, err, c.wantErr)
+		}
+	}
+	assertions, err := ParseAll([]string{"errors==0", " ", "p50<1"})
+	if err != nil || len(assertions) != 2 {
+		t.Errorf("got %v (%v) want 2 assertions", assertions, err)
+	}
+	if _, err = ParseAll([]string{"errors==0", "p50"}); err == nil {
+		t.Errorf("invalid assertion did not error")
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	cases := map[string]float64{"p50": 50, "p99": 99, "p99.9": 99.9, "p999": 99.9, "p100": 100, "p0": 0}
+	for stat, want := range cases {
+		if got, ok := Percentile(stat); !ok || got != want {
+			t.Errorf("%s: got %v (%t) want %v", stat, got, ok, want)
+		}
+	}
+	for _, stat := range []string{"p", "p101", "max", "pmax", "p-1"} {
+		if _, ok := Percentile(stat); ok {
+			t.Errorf("%s is a percentile", stat)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	assertions, err := ParseAll([]string{"p99(q1)<50ms", "p99(q2)<50ms", "errors==0", "p99(unknown)<1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]float64{"p99:q1": 42, "p99:q2": 60, "errors:": 0}
+	results := Evaluate(assertions, func(a Assertion) (float64, error) {
+		v, ok := values[a.Stat+":"+a.Arg]
+		if !ok {
+			return 0, fmt.Errorf("unknown label '%s'", a.Arg)
+		}
+		return v, nil
+	})
+	want := []Result{
+		{Assertion: "p99(q1)<50ms", Value: 42, Passed: true},
+		{Assertion: "p99(q2)<50ms", Value: 60},
+		{Assertion: "errors==0", Value: 0, Passed: true},
+		{Assertion: "p99(unknown)<1", Error: "unknown label 'unknown'"},
+	}
+	for i := range want {
+		if results[i] != want[i] {
+			t.Errorf("result %d: got %+v want %+v", i, results[i], want[i])
+		}
+	}
+	if got := Failed(results); got != 2 {
+		t.Errorf("got %d failed want 2", got)
+	}
+
+	var buf bytes.Buffer
+	if err = Print(&buf, results); err != nil {
+		t.Fatal(err)
+	}
+	wantTable := `assertion       value  result
+p99(q1)<50ms    42.00  pass
+p99(q2)<50ms    60.00  FAIL
+errors==0       0.00   pass
+p99(unknown)<1  -      FAIL: unknown label 'unknown'
+2 of 4 assertions failed
+`
+	if buf.String() != wantTable {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), wantTable)
+	}
+}
+
+func TestExitOnFailure(t *testing.T) {
+	exitCode := 0
+	exitFn = func(code int) { exitCode = code }
+	defer func() { exitFn = os.Exit }()
+
+	ExitOnFailure([]Result{{Assertion: "errors==0", Passed: true}})
+	if exitCode != 0 {
+		t.Errorf("exited with %d when all assertions passed", exitCode)
+	}
+	ExitOnFailure([]Result{{Assertion: "errors==0", Passed: true}, {Assertion: "p99<1"}})
+	if exitCode != ExitCode {
+		t.Errorf("got exit code %d want %d", exitCode, ExitCode)
+	}
+}
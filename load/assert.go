@@ -0,0 +1,89 @@
+package load
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/timescale/tsbs/internal/slo"
+)
+
+// loadStats are the stats of the assertions of a load, besides the
+// percentiles of the batch latency
+var loadStats = map[string]bool{
+	"metricRate": true, "rowRate": true, "metrics": true, "rows": true,
+	"retriedBatches": true, "failedBatches": true, "errors": true, "errorRate": true,
+	"min": true, "mean": true, "max": true,
+}
+
+// parseAssertions parses the assertions of a load and checks their stats exist
+func parseAssertions(exprs []string) ([]slo.Assertion, error) {
+	assertions, err := slo.ParseAll(exprs)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range assertions {
+		if _, ok := slo.Percentile(a.Stat); !ok && !loadStats[a.Stat] {
+			return nil, fmt.Errorf("unknown stat '%s' in assertion '%s', must be metricRate, rowRate, metrics, rows, retriedBatches, failedBatches, errors, errorRate or a batch latency: pN (e.g. p99), min, mean or max", a.Stat, a.Expr)
+		}
+		if a.Arg != "" {
+			return nil, fmt.Errorf("the stats of a load have no argument, in assertion '%s'", a.Expr)
+		}
+	}
+	return assertions, nil
+}
+
+// checkAssertions evaluates the assertions against the stats of the load that
+// took the given time and prints the results
+func (l *CommonBenchmarkRunner) checkAssertions(took time.Duration) []slo.Result {
+	results := slo.Evaluate(l.assertions, func(a slo.Assertion) (float64, error) {
+		return l.loadStat(a.Stat, took)
+	})
+	printFn("\nAssertions:\n")
+	if err := slo.Print(os.Stdout, results); err != nil {
+		panic(err)
+	}
+	return results
+}
+
+// loadStat returns the value of a stat of the load, the rates and counts
+// exclude the warm-up and the latencies are in milliseconds
+func (l *CommonBenchmarkRunner) loadStat(stat string, took time.Duration) (float64, error) {
+	metricCnt, rowCnt, measuredTook := l.measured(took)
+	switch stat {
+	case "metricRate":
+		return float64(metricCnt) / measuredTook.Seconds(), nil
+	case "rowRate":
+		return float64(rowCnt) / measuredTook.Seconds(), nil
+	case "metrics":
+		return float64(metricCnt), nil
+	case "rows":
+		return float64(rowCnt), nil
+	case "retriedBatches":
+		return float64(l.retriedBatchCnt), nil
+	case "failedBatches", "errors":
+		return float64(l.failedBatchCnt), nil
+	case "errorRate":
+		// a fraction, like the threshold of errorRate<1%
+		if l.batchCnt == 0 {
+			return 0, fmt.Errorf("no batches")
+		}
+		return float64(l.failedBatchCnt) / float64(l.batchCnt), nil
+	}
+	h := l.latencies.overall()
+	if h.TotalCount() == 0 {
+		return 0, fmt.Errorf("no batch latencies")
+	}
+	if p, ok := slo.Percentile(stat); ok {
+		return float64(h.ValueAtQuantile(p)) / latencyScaleFactor, nil
+	}
+	switch stat {
+	case "min":
+		return float64(h.Min()) / latencyScaleFactor, nil
+	case "mean":
+		return h.Mean() / latencyScaleFactor, nil
+	case "max":
+		return float64(h.Max()) / latencyScaleFactor, nil
+	}
+	return 0, fmt.Errorf("unknown stat '%s'", stat)
+}
@@ -0,0 +1,113 @@
+package load
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/internal/slo"
+)
+
+func TestParseAssertions(t *testing.T) {
+	assertions, err := parseAssertions([]string{"metricRate>500000", "p999<1s", "errors==0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assertions) != 3 || assertions[1].Threshold != 1000 {
+		t.Errorf("wrong assertions: %+v", assertions)
+	}
+	cases := []struct {
+		expr    string
+		wantErr string
+	}{
+		{expr: "qps>1", wantErr: "unknown stat 'qps'"},
+		{expr: "p99(worker 1)<1s", wantErr: "no argument"},
+		{expr: "metricRate", wantErr: "no operator"},
+	}
+	for _, c := range cases {
+		if _, err := parseAssertions([]string{c.expr}); err == nil || !strings.Contains(err.Error(), c.wantErr) {
+			t.Errorf("%s: got error %v want %s", c.expr, err, c.wantErr)
+		}
+	}
+}
+
+func TestLoadStatErrors(t *testing.T) {
+	l := &CommonBenchmarkRunner{batchCnt: 200, failedBatchCnt: 3}
+	cases := []struct {
+		expr string
+		want bool
+	}{
+		{expr: "errors<=3", want: true},
+		{expr: "errorRate<1%", want: false},
+		{expr: "errorRate<2%", want: true},
+	}
+	for _, c := range cases {
+		a, err := slo.Parse(c.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := l.loadStat(a.Stat, time.Second)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", c.expr, err)
+		}
+		if got := a.Check(v); got != c.want {
+			t.Errorf("%s: got %v for %v want %v", c.expr, got, v, c.want)
+		}
+	}
+	if _, err := (&CommonBenchmarkRunner{}).loadStat("errorRate", time.Second); err == nil {
+		t.Errorf("expected error for the error rate of no batches")
+	}
+}
+
+func TestRunBenchmarkAssertions(t *testing.T) {
+	printFn = func(s string, args ...interface{}) (n int, err error) { return 0, nil }
+	dir, err := ioutil.TempDir("", "assert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := BenchmarkRunnerConfig{
+		BatchSize:   1,
+		Workers:     2,
+		DoLoad:      true,
+		Duration:    100 * time.Millisecond,
+		ResultsFile: filepath.Join(dir, "results.json"),
+		Asserts:     []string{"metrics>0", "errors==0", "p99<1s", "metricRate>=1"},
+	}
+	GetBenchmarkRunner(c).RunBenchmark(&saturationBenchmark{creator: &testCreator{}})
+
+	data, err := ioutil.ReadFile(c.ResultsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result struct {
+		Totals struct {
+			Assertions []slo.Result `json:"assertions"`
+		}
+	}
+	if err = json.Unmarshal(data, &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Totals.Assertions) != 4 {
+		t.Fatalf("wrong assertions in the results: %+v", result.Totals.Assertions)
+	}
+	for _, r := range result.Totals.Assertions {
+		if !r.Passed {
+			t.Errorf("assertion failed: %+v", r)
+		}
+	}
+}
+
+func TestGetBenchmarkRunnerPanicOnInvalidAssertion(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("the code did not panic")
+		}
+	}()
+	GetBenchmarkRunner(BenchmarkRunnerConfig{Workers: 1, Asserts: []string{"p99(x)<1s"}})
+}
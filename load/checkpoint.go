@@ -0,0 +1,341 @@
+package load
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+const (
+	checkpointVersion         = 2
+	defaultCheckpointInterval = 10 * time.Second
+)
+
+// checkpoint is the progress of a load persisted to --checkpoint-file.
+// All the items of the data source before Items were processed, so a resumed
+// load continues from there. For a data file Offset is the byte offset of
+// item Items, 0 if it's unknown. The items of the batches that failed and
+// were skipped under the error budget are counted in FailedItems, they are
+// not loaded and won't be loaded when resuming.
+type checkpoint struct {
+	Version       int       `json:"version"`
+	ConfigHash    string    `json:"configHash"`
+	Items         uint64    `json:"items"`
+	Offset        int64     `json:"offset,omitempty"`
+	FailedBatches uint64    `json:"failedBatches,omitempty"`
+	FailedItems   uint64    `json:"failedItems,omitempty"`
+	Updated       time.Time `json:"updated"`
+}
+
+// configHash identifies the data a load reads, a checkpoint can only be
+// resumed with the same database, input data and batch size
+func configHash(c *BenchmarkRunnerConfig) (string, error) {
+	dataSource, err := json.Marshal(c.DataSource)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "%s\x00%s\x00%d\x00%d\x00%s", c.DBName, c.FileName, c.Seed, c.BatchSize, dataSource)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// readCheckpoint reads the checkpoint from fileName and checks it belongs to the same load
+func readCheckpoint(fileName, hash string) (*checkpoint, error) {
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	cp := &checkpoint{}
+	if err = json.Unmarshal(content, cp); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint file %s: %v", fileName, err)
+	}
+	if cp.Version != checkpointVersion {
+		return nil, fmt.Errorf("unsupported checkpoint version %d in %s", cp.Version, fileName)
+	}
+	if cp.ConfigHash != hash {
+		return nil, fmt.Errorf("checkpoint %s was written by a load with a different database, input data or batch size", fileName)
+	}
+	return cp, nil
+}
+
+// writeCheckpoint replaces the checkpoint in fileName, so that a crash
+// while writing never leaves a truncated checkpoint behind
+func writeCheckpoint(fileName string, cp *checkpoint) error {
+	content, err := json.MarshalIndent(cp, "", " ")
+	if err != nil {
+		return err
+	}
+	tmpName := fileName + ".tmp"
+	if err = ioutil.WriteFile(tmpName, content, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpName, fileName)
+}
+
+// position is the position of an item in the data source, its offset is -1 if unknown
+type position struct {
+	item   uint64
+	offset int64
+}
+
+// checkpointTracker finds the number of items of the data source of which every
+// item is processed. Batches are filled and processed out of order (with several
+// channels or workers), so it keeps the position of the first item of every
+// batch that isn't processed yet; everything before the lowest one is processed.
+type checkpointTracker struct {
+	mu sync.Mutex
+	// skipped is the number of items skipped when resuming, positions of the scanner start after them
+	skipped       uint64
+	skippedOffset int64
+	// read is the number of items read by the scanner
+	read uint64
+	// itemOffset is the offset of the last item read, nextOffset of the one after it
+	itemOffset int64
+	nextOffset int64
+	// filling has the first item of the batches still in the scanner, inFlight of those handed to a worker
+	filling  map[targets.Batch]position
+	inFlight map[uint64]int64
+	// failedBatches and failedItems count the batches skipped under the error budget
+	failedBatches uint64
+	failedItems   uint64
+}
+
+func newCheckpointTracker(skipped uint64) *checkpointTracker {
+	return &checkpointTracker{
+		skipped:    skipped,
+		itemOffset: -1,
+		nextOffset: -1,
+		filling:    make(map[targets.Batch]position),
+		inFlight:   make(map[uint64]int64),
+	}
+}
+
+// readAt is called when an item was read from a data file at offset, next is
+// the offset of the item after it
+func (t *checkpointTracker) readAt(offset, next int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.itemOffset = offset
+	t.nextOffset = next
+}
+
+// appended is called by the scanner after an item was appended to batch b
+func (t *checkpointTracker) appended(b targets.Batch) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.filling[b]; !ok {
+		t.filling[b] = position{item: t.skipped + t.read, offset: t.itemOffset}
+	}
+	t.read++
+}
+
+// claim is called by a worker before it processes batch b. The returned position
+// must be passed to done afterwards. Batches are pooled by some targets, so the
+// batch itself can't identify the work once it's processed.
+func (t *checkpointTracker) claim(b targets.Batch) uint64 {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	first, ok := t.filling[b]
+	if !ok {
+		// not appended through the scanner, nothing to track
+		first = position{item: t.skipped + t.read, offset: -1}
+	}
+	delete(t.filling, b)
+	t.inFlight[first.item] = first.offset
+	return first.item
+}
+
+// done is called by a worker when the batch of items claimed at first is processed,
+// failed tells whether it was skipped under the error budget
+func (t *checkpointTracker) done(first uint64, failed bool, items uint) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.inFlight, first)
+	if failed {
+		t.failedBatches++
+		t.failedItems += uint64(items)
+	}
+}
+
+// loaded returns the position of the first item of the data source that isn't
+// processed, all the items before it are
+func (t *checkpointTracker) loaded() position {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	loaded := position{item: t.skipped + t.read, offset: t.nextOffset}
+	if t.read == 0 && t.nextOffset < 0 {
+		loaded.offset = t.skippedOffset
+	}
+	for _, first := range t.filling {
+		if first.item < loaded.item {
+			loaded = first
+		}
+	}
+	for first, offset := range t.inFlight {
+		if first < loaded.item {
+			loaded = position{item: first, offset: offset}
+		}
+	}
+	return loaded
+}
+
+// failed returns the number of batches and items skipped under the error budget
+func (t *checkpointTracker) failed() (batches, items uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.failedBatches, t.failedItems
+}
+
+// saveCheckpoint persists the current progress to the checkpoint file
+func (l *CommonBenchmarkRunner) saveCheckpoint() error {
+	hash, err := configHash(&l.BenchmarkRunnerConfig)
+	if err != nil {
+		return err
+	}
+	loaded := l.checkpoints.loaded()
+	cp := &checkpoint{
+		Version:    checkpointVersion,
+		ConfigHash: hash,
+		Items:      loaded.item,
+		Updated:    time.Now().UTC(),
+	}
+	if loaded.offset > 0 {
+		cp.Offset = loaded.offset
+	}
+	cp.FailedBatches, cp.FailedItems = l.checkpoints.failed()
+	return writeCheckpoint(l.CheckpointFile, cp)
+}
+
+// startCheckpoints sets up the tracking of the loaded items if a checkpoint
+// file is configured and, when resuming, reads the items to skip from it.
+// The checkpoint is saved every CheckpointInterval until the returned function is called.
+func (l *CommonBenchmarkRunner) startCheckpoints() (stop func()) {
+	if l.CheckpointFile == "" {
+		if l.Resume {
+			fatal("can't resume without a checkpoint-file")
+		}
+		return func() {}
+	}
+	hash, err := configHash(&l.BenchmarkRunnerConfig)
+	if err != nil {
+		fatal("could not identify the load for its checkpoint: %v", err)
+		return func() {}
+	}
+	cp := &checkpoint{}
+	if l.Resume {
+		cp, err = readCheckpoint(l.CheckpointFile, hash)
+		if err != nil {
+			fatal("could not resume: %v", err)
+			return func() {}
+		}
+		printFn("resuming load from checkpoint %s, skipping %d items already processed\n", l.CheckpointFile, cp.Items)
+		if cp.FailedBatches > 0 {
+			printFn("%d items of %d failed batches before the checkpoint were not loaded\n", cp.FailedItems, cp.FailedBatches)
+		}
+	}
+	l.checkpoints = newCheckpointTracker(cp.Items)
+	l.checkpoints.skippedOffset = cp.Offset
+	l.checkpoints.failedBatches = cp.FailedBatches
+	l.checkpoints.failedItems = cp.FailedItems
+
+	interval := l.CheckpointInterval
+	if interval <= 0 {
+		interval = defaultCheckpointInterval
+	}
+	ticker := time.NewTicker(interval)
+	stopCh := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		for {
+			select {
+			case <-ticker.C:
+				if err := l.saveCheckpoint(); err != nil {
+					printFn("could not save checkpoint: %v\n", err)
+				}
+			case <-stopCh:
+				return
+			}
+		}
+	}()
+	return func() {
+		ticker.Stop()
+		close(stopCh)
+		<-finished
+		if err := l.saveCheckpoint(); err != nil {
+			fatal("could not save checkpoint: %v", err)
+		}
+	}
+}
+
+// scanLimit returns the limit of the scanner once the items loaded before
+// resuming are skipped, since Limit is the total number of items to load
+func (l *CommonBenchmarkRunner) scanLimit() uint64 {
+	if l.Limit == 0 || l.checkpoints == nil || l.checkpoints.skipped >= l.Limit {
+		return l.Limit
+	}
+	return l.Limit - l.checkpoints.skipped
+}
+
+// offsetDataSource wraps a SeekableDataSource and reports the offsets of the
+// items read to the checkpointTracker
+type offsetDataSource struct {
+	targets.SeekableDataSource
+	tracker *checkpointTracker
+}
+
+// NextItem returns the next item of the wrapped DataSource
+func (d *offsetDataSource) NextItem() data.LoadedPoint {
+	offset := d.Offset()
+	item := d.SeekableDataSource.NextItem()
+	d.tracker.readAt(offset, d.Offset())
+	return item
+}
+
+// skipDataSource wraps a DataSource and discards the first items, the ones
+// loaded before a load is resumed. A SeekableDataSource seeks to offset, the
+// position of the first item not to skip if it's greater than 0, instead of
+// reading the items.
+type skipDataSource struct {
+	targets.DataSource
+	skip   uint64
+	offset int64
+}
+
+// NextItem returns the next item of the wrapped DataSource once the items to skip are read
+func (s *skipDataSource) NextItem() data.LoadedPoint {
+	if s.skip > 0 && s.offset > 0 {
+		if sds, ok := s.DataSource.(targets.SeekableDataSource); ok {
+			if err := sds.SeekTo(s.offset); err != nil {
+				printFn("could not seek to offset %d, reading the items to skip instead: %v\n", s.offset, err)
+			} else {
+				s.skip = 0
+			}
+		}
+		s.offset = 0
+	}
+	for ; s.skip > 0; s.skip-- {
+		if item := s.DataSource.NextItem(); item.Data == nil {
+			s.skip = 0
+			return item
+		}
+	}
+	return s.DataSource.NextItem()
+}
@@ -0,0 +1,222 @@
+package load
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+func TestCheckpointTracker(t *testing.T) {
+	tr := newCheckpointTracker(10)
+	b1, b2 := &testBatch{id: 1}, &testBatch{id: 2}
+	// items 10 and 12 go to b1, item 11 to b2
+	tr.appended(b1)
+	tr.appended(b2)
+	tr.appended(b1)
+	if got := tr.loaded().item; got != 10 {
+		t.Errorf("nothing processed, loaded should be the skipped items: got %d want 10", got)
+	}
+
+	first2 := tr.claim(b2)
+	first1 := tr.claim(b1)
+	if first1 != 10 || first2 != 11 {
+		t.Errorf("wrong first items: got %d, %d want 10, 11", first1, first2)
+	}
+	tr.done(first2, false, 1)
+	if got := tr.loaded().item; got != 10 {
+		t.Errorf("b1 not processed yet: got %d want 10", got)
+	}
+
+	// b2 is reused by a pooling batch factory while b1 is still in flight
+	tr.appended(b2)
+	tr.done(first1, true, 2)
+	if got := tr.loaded().item; got != 13 {
+		t.Errorf("everything up to the reused batch is loaded: got %d want 13", got)
+	}
+	tr.done(tr.claim(b2), false, 1)
+	if got := tr.loaded().item; got != 14 {
+		t.Errorf("everything is loaded: got %d want 14", got)
+	}
+	if batches, items := tr.failed(); batches != 1 || items != 2 {
+		t.Errorf("wrong failed batches: got %d batches %d items want 1 batch 2 items", batches, items)
+	}
+}
+
+func TestCheckpointTrackerOffsets(t *testing.T) {
+	tr := newCheckpointTracker(0)
+	b1, b2 := &testBatch{id: 1}, &testBatch{id: 2}
+	// items of 10 bytes, item 0 and 2 go to b1, item 1 to b2
+	for i, b := range []targets.Batch{b1, b2, b1} {
+		tr.readAt(int64(i*10), int64(i*10+10))
+		tr.appended(b)
+	}
+	first1 := tr.claim(b1)
+	tr.done(tr.claim(b2), false, 1)
+	if got := tr.loaded(); got.item != 0 || got.offset != 0 {
+		t.Errorf("b1 not processed yet: got %+v", got)
+	}
+	tr.done(first1, false, 2)
+	if got := tr.loaded(); got.item != 3 || got.offset != 30 {
+		t.Errorf("everything is loaded: got %+v want item 3 at 30", got)
+	}
+
+	// resumed without reading anything yet
+	tr = newCheckpointTracker(3)
+	tr.skippedOffset = 30
+	if got := tr.loaded(); got.item != 3 || got.offset != 30 {
+		t.Errorf("nothing read after resuming: got %+v want item 3 at 30", got)
+	}
+}
+
+func TestCheckpointFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "checkpoint.json")
+
+	hash := func(c *BenchmarkRunnerConfig) string {
+		h, err := configHash(c)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return h
+	}
+	type simulator struct {
+		Use   string
+		Scale uint64
+	}
+	c := &BenchmarkRunnerConfig{DBName: "benchmark", BatchSize: 1000, DataSource: &simulator{Use: "devops", Scale: 10}}
+	if err := writeCheckpoint(fileName, &checkpoint{Version: checkpointVersion, ConfigHash: hash(c), Items: 42, Offset: 4200}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cp, err := readCheckpoint(fileName, hash(c))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cp.Items != 42 || cp.Offset != 4200 {
+		t.Errorf("wrong position: got %d at %d want 42 at 4200", cp.Items, cp.Offset)
+	}
+
+	others := []*BenchmarkRunnerConfig{
+		{DBName: "benchmark", BatchSize: 1000, DataSource: &simulator{Use: "devops", Scale: 20}},
+		{DBName: "benchmark", BatchSize: 1000, DataSource: &simulator{Use: "iot", Scale: 10}},
+		{DBName: "benchmark", BatchSize: 500, DataSource: &simulator{Use: "devops", Scale: 10}},
+		{DBName: "other", BatchSize: 1000, DataSource: &simulator{Use: "devops", Scale: 10}},
+		{DBName: "benchmark", FileName: "data.gz"},
+	}
+	for _, other := range others {
+		if _, err := readCheckpoint(fileName, hash(other)); err == nil {
+			t.Errorf("expected error when resuming a different load: %+v", other)
+		}
+	}
+	if _, err := readCheckpoint(filepath.Join(dir, "missing.json"), hash(c)); err == nil {
+		t.Errorf("expected error for a missing checkpoint")
+	}
+}
+
+func TestSkipDataSource(t *testing.T) {
+	ds := &skipDataSource{
+		DataSource: &testDataSource{br: bufio.NewReader(bytes.NewReader([]byte{1, 2, 3}))},
+		skip:       2,
+	}
+	if item := ds.NextItem(); item.Data != byte(3) {
+		t.Errorf("wrong first item after skipping: got %v want 3", item.Data)
+	}
+	if item := ds.NextItem(); item.Data != nil {
+		t.Errorf("no items expected, got %v", item.Data)
+	}
+
+	ds = &skipDataSource{
+		DataSource: &testDataSource{br: bufio.NewReader(bytes.NewReader([]byte{1}))},
+		skip:       5,
+	}
+	if item := ds.NextItem(); item.Data != nil {
+		t.Errorf("skipping past the end should return no items, got %v", item.Data)
+	}
+}
+
+// testSeekableDataSource reads the bytes of a data file, an item each
+type testSeekableDataSource struct {
+	testDataSource
+	file  *DataFile
+	items *bufio.Scanner
+}
+
+func (d *testSeekableDataSource) NextItem() data.LoadedPoint {
+	if !d.items.Scan() {
+		return data.LoadedPoint{}
+	}
+	return data.NewLoadedPoint(d.items.Text())
+}
+
+func (d *testSeekableDataSource) Offset() int64 {
+	return d.file.Offset()
+}
+
+func (d *testSeekableDataSource) SeekTo(offset int64) error {
+	items, err := d.file.SeekTo(offset)
+	if err != nil {
+		return err
+	}
+	d.items = items
+	return nil
+}
+
+func TestSkipDataSourceSeek(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "data")
+	if err := ioutil.WriteFile(fileName, []byte("a\nbb\nccc\ndddd\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	file, items := OpenDataFile(fileName)
+	tr := newCheckpointTracker(2)
+	sds := &offsetDataSource{SeekableDataSource: &testSeekableDataSource{file: file, items: items}, tracker: tr}
+	// the offset of the third item, the first two are not read
+	ds := &skipDataSource{DataSource: sds, skip: 2, offset: 5}
+	if item := ds.NextItem(); item.Data != "ccc" {
+		t.Errorf("wrong first item after seeking: got %v want ccc", item.Data)
+	}
+	tr.appended(&testBatch{})
+	if got := tr.loaded(); got.item != 2 || got.offset != 5 {
+		t.Errorf("wrong position of the first item after seeking: got %+v", got)
+	}
+	if item := ds.NextItem(); item.Data != "dddd" {
+		t.Errorf("wrong second item after seeking: got %v want dddd", item.Data)
+	}
+	if got := file.Offset(); got != 14 {
+		t.Errorf("wrong offset at the end: got %d want 14", got)
+	}
+}
+
+func TestUseDBCreatorResume(t *testing.T) {
+	br := &CommonBenchmarkRunner{}
+	br.DoLoad = true
+	br.DoCreateDB = true
+	br.DoAbortOnExist = true
+	br.Resume = true
+
+	c := &testCreator{exists: true}
+	br.useDBCreator(c)
+	if c.removeCalled || c.createCalled {
+		t.Errorf("resumed load must keep the existing database")
+	}
+
+	c = &testCreator{exists: false}
+	br.useDBCreator(c)
+	if !c.createCalled {
+		t.Errorf("resumed load must create a missing database")
+	}
+}
@@ -0,0 +1,68 @@
+package load
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+)
+
+var errSeekStdin = errors.New("cannot seek in data read from STDIN")
+
+// DataFile is a data file read line by line through a bufio.Scanner that keeps
+// track of the byte offset of the lines the scanner returned. A file DataSource
+// reading it can implement targets.SeekableDataSource with Offset and SeekTo.
+type DataFile struct {
+	// file is nil when reading from STDIN
+	file   *os.File
+	offset int64
+}
+
+// OpenDataFile opens fileName, or STDIN if it's empty, and returns it with the
+// scanner to read its lines with
+func OpenDataFile(fileName string) (*DataFile, *bufio.Scanner) {
+	f := &DataFile{}
+	if len(fileName) == 0 {
+		return f, f.newScanner(bufio.NewReaderSize(os.Stdin, defaultReadSize))
+	}
+	file, err := os.Open(fileName)
+	if err != nil {
+		fatal("cannot open file for read %s: %v", fileName, err)
+		return nil, nil
+	}
+	f.file = file
+	return f, f.newScanner(bufio.NewReaderSize(file, defaultReadSize))
+}
+
+// newScanner returns a scanner of the lines of r which counts the bytes it consumes
+func (f *DataFile) newScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		f.offset += int64(advance)
+		return advance, token, err
+	})
+	return scanner
+}
+
+// Offset returns the byte offset of the line after the last one returned by
+// the scanner, or -1 for a nil DataFile
+func (f *DataFile) Offset() int64 {
+	if f == nil {
+		return -1
+	}
+	return f.offset
+}
+
+// SeekTo moves to the line at offset and returns the scanner to read from there,
+// which replaces the scanner used until then
+func (f *DataFile) SeekTo(offset int64) (*bufio.Scanner, error) {
+	if f == nil || f.file == nil {
+		return nil, errSeekStdin
+	}
+	if _, err := f.file.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	f.offset = offset
+	return f.newScanner(bufio.NewReaderSize(f.file, defaultReadSize)), nil
+}
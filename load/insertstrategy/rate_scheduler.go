@@ -0,0 +1,76 @@
+package insertstrategy
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateScheduler is an open-loop insert strategy. It hands out the intended start
+// time of each batch so that all workers together insert at a constant target rate,
+// independent of how long each insert takes. When the database can't keep up the
+// intended start times stay on schedule, so latencies measured from them include
+// the time a batch was waiting (coordinated omission correction) and the runner
+// falls behind schedule instead of silently lowering the offered load.
+type RateScheduler struct {
+	mu        sync.Mutex
+	rate      float64 // items per second
+	start     time.Time
+	scheduled float64 // items scheduled since start
+	lag       time.Duration
+	maxLag    time.Duration
+	nowFn     nowProviderFn
+	sleepFn   func(time.Duration)
+}
+
+// NewRateScheduler returns a RateScheduler for the given rate of items per second
+func NewRateScheduler(rate float64) (*RateScheduler, error) {
+	if rate <= 0 {
+		return nil, fmt.Errorf("target rate must be positive, can't be %f", rate)
+	}
+	return &RateScheduler{
+		rate:    rate,
+		nowFn:   time.Now,
+		sleepFn: time.Sleep,
+	}, nil
+}
+
+// Rate returns the target rate in items per second
+func (s *RateScheduler) Rate() float64 {
+	return s.rate
+}
+
+// Next reserves the next slot of the schedule for a batch of the given number of
+// items and blocks until its intended start time. The intended start time is returned.
+// If the schedule is already behind, Next returns immediately and the lag is recorded.
+func (s *RateScheduler) Next(items float64) time.Time {
+	s.mu.Lock()
+	now := s.nowFn()
+	if s.start.IsZero() {
+		s.start = now
+	}
+	intended := s.start.Add(time.Duration(s.scheduled / s.rate * float64(time.Second)))
+	s.scheduled += items
+	if now.After(intended) {
+		s.lag = now.Sub(intended)
+		if s.lag > s.maxLag {
+			s.maxLag = s.lag
+		}
+	} else {
+		s.lag = 0
+	}
+	s.mu.Unlock()
+
+	if wait := intended.Sub(now); wait > 0 {
+		s.sleepFn(wait)
+	}
+	return intended
+}
+
+// Lag returns how far behind schedule the last started batch was, and the
+// maximum lag observed since the schedule started
+func (s *RateScheduler) Lag() (current, max time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lag, s.maxLag
+}
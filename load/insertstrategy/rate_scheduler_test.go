@@ -0,0 +1,70 @@
+package insertstrategy
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewRateScheduler(t *testing.T) {
+	if _, err := NewRateScheduler(0); err == nil {
+		t.Error("expected error for rate 0")
+	}
+	if _, err := NewRateScheduler(-1); err == nil {
+		t.Error("expected error for negative rate")
+	}
+	s, err := NewRateScheduler(10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Rate() != 10 {
+		t.Errorf("wrong rate: got %f want %f", s.Rate(), 10.0)
+	}
+}
+
+func TestRateSchedulerNext(t *testing.T) {
+	start := time.Unix(1000, 0)
+	now := start
+	var slept []time.Duration
+	s, _ := NewRateScheduler(100) // 100 items per second
+	s.nowFn = func() time.Time { return now }
+	s.sleepFn = func(d time.Duration) {
+		slept = append(slept, d)
+		now = now.Add(d)
+	}
+
+	// first batch starts right away
+	if got := s.Next(10); !got.Equal(start) {
+		t.Errorf("wrong intended start of batch 1: got %v want %v", got, start)
+	}
+	// 10 items at 100/s take 100ms, so the next batch is due at start+100ms
+	if got, want := s.Next(10), start.Add(100*time.Millisecond); !got.Equal(want) {
+		t.Errorf("wrong intended start of batch 2: got %v want %v", got, want)
+	}
+	if len(slept) != 1 || slept[0] != 100*time.Millisecond {
+		t.Errorf("expected a single sleep of 100ms, got %v", slept)
+	}
+	if cur, max := s.Lag(); cur != 0 || max != 0 {
+		t.Errorf("unexpected lag when on schedule: %v %v", cur, max)
+	}
+
+	// the database is slow, so the next batch is requested late
+	now = now.Add(500 * time.Millisecond)
+	if got, want := s.Next(10), start.Add(200*time.Millisecond); !got.Equal(want) {
+		t.Errorf("wrong intended start of late batch: got %v want %v", got, want)
+	}
+	if len(slept) != 1 {
+		t.Errorf("late batch should not sleep")
+	}
+	cur, max := s.Lag()
+	if cur != 400*time.Millisecond || max != 400*time.Millisecond {
+		t.Errorf("wrong lag: got %v/%v want %v", cur, max, 400*time.Millisecond)
+	}
+
+	// catching up resets the current lag but keeps the max
+	now = start.Add(300 * time.Millisecond)
+	s.Next(10)
+	cur, max = s.Lag()
+	if cur != 0 || max != 400*time.Millisecond {
+		t.Errorf("wrong lag after catching up: got %v/%v", cur, max)
+	}
+}
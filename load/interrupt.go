@@ -0,0 +1,44 @@
+package load
+
+import (
+	"sync/atomic"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// interrupt makes the load stop reading data, the batches already read are still loaded
+func (l *CommonBenchmarkRunner) interrupt() {
+	atomic.StoreInt32(&l.interrupted, 1)
+}
+
+// wasInterrupted reports whether the load was interrupted before all the data was read
+func (l *CommonBenchmarkRunner) wasInterrupted() bool {
+	return atomic.LoadInt32(&l.interrupted) == 1
+}
+
+// stop makes the load stop reading data like an interrupt, but the results
+// are not marked as interrupted
+func (l *CommonBenchmarkRunner) stop() {
+	atomic.StoreInt32(&l.stopped, 1)
+}
+
+// stopReading reports whether the scanner should stop reading data
+func (l *CommonBenchmarkRunner) stopReading() bool {
+	return l.wasInterrupted() || atomic.LoadInt32(&l.stopped) == 1
+}
+
+// interruptibleDataSource wraps a DataSource and reports the end of the data
+// once the load is interrupted, so the scanner stops and the workers drain
+type interruptibleDataSource struct {
+	targets.DataSource
+	interrupted func() bool
+}
+
+// NextItem returns the next item of the wrapped DataSource, or an empty item after an interruption
+func (d *interruptibleDataSource) NextItem() data.LoadedPoint {
+	if d.interrupted() {
+		return data.LoadedPoint{}
+	}
+	return d.DataSource.NextItem()
+}
@@ -0,0 +1,39 @@
+package load
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInterruptibleDataSource(t *testing.T) {
+	br := &CommonBenchmarkRunner{}
+	ds := &interruptibleDataSource{
+		DataSource:  &testDataSource{br: bufio.NewReader(bytes.NewReader([]byte{1, 2, 3}))},
+		interrupted: br.wasInterrupted,
+	}
+	if item := ds.NextItem(); item.Data == nil {
+		t.Errorf("item expected before the interruption")
+	}
+	br.interrupt()
+	if item := ds.NextItem(); item.Data != nil {
+		t.Errorf("no item expected after the interruption, got %v", item.Data)
+	}
+}
+
+func TestSummaryInterrupted(t *testing.T) {
+	var b bytes.Buffer
+	printFn = func(s string, args ...interface{}) (n int, err error) {
+		return fmt.Fprintf(&b, s, args...)
+	}
+	br := &CommonBenchmarkRunner{}
+	br.metricCnt = 10
+	br.interrupt()
+	br.summary(time.Second)
+	if got := b.String(); !strings.Contains(got, "load interrupted") {
+		t.Errorf("summary should say the load was interrupted, got %s", got)
+	}
+}
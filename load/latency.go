@@ -0,0 +1,138 @@
+package load
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+const (
+	// latencies are recorded in microseconds, between 1us and 1h,
+	// with 3 significant digits
+	minRecordableLatency = 1
+	maxRecordableLatency = int64(time.Hour / time.Microsecond)
+	latencySigFigs       = 3
+	latencyScaleFactor   = 1e3 // microseconds in a millisecond
+)
+
+// reportedQuantiles are the quantiles of the batch latency shown in the summary and the results file
+var reportedQuantiles = []struct {
+	name     string
+	quantile float64
+}{
+	{"p50", 50.0},
+	{"p90", 90.0},
+	{"p99", 99.0},
+	{"p999", 99.9},
+}
+
+// workerLatency is the batch latency histogram of a single worker
+type workerLatency struct {
+	mu   sync.Mutex
+	hist *hdrhistogram.Histogram
+}
+
+// batchLatencies keeps a High Dynamic Range (HDR) histogram of the
+// insert latency of every batch, separately for each worker.
+type batchLatencies struct {
+	workers []*workerLatency
+}
+
+func newLatencyHistogram() *hdrhistogram.Histogram {
+	return hdrhistogram.New(minRecordableLatency, maxRecordableLatency, latencySigFigs)
+}
+
+func newBatchLatencies(numWorkers uint) *batchLatencies {
+	bl := &batchLatencies{workers: make([]*workerLatency, numWorkers)}
+	for i := range bl.workers {
+		bl.workers[i] = &workerLatency{hist: newLatencyHistogram()}
+	}
+	return bl
+}
+
+// record adds the latency of one batch processed by worker workerNum
+func (bl *batchLatencies) record(workerNum uint, took time.Duration) {
+	if bl == nil || int(workerNum) >= len(bl.workers) {
+		return
+	}
+	w := bl.workers[workerNum]
+	w.mu.Lock()
+	_ = w.hist.RecordValue(int64(took / time.Microsecond))
+	w.mu.Unlock()
+}
+
+// reset clears the histograms of all the workers
+func (bl *batchLatencies) reset() {
+	if bl == nil {
+		return
+	}
+	for _, w := range bl.workers {
+		w.mu.Lock()
+		w.hist.Reset()
+		w.mu.Unlock()
+	}
+}
+
+// worker returns a copy of the histogram of a single worker
+func (bl *batchLatencies) worker(workerNum int) *hdrhistogram.Histogram {
+	w := bl.workers[workerNum]
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return hdrhistogram.Import(w.hist.Export())
+}
+
+// overall returns a histogram with the latencies of all the workers merged
+func (bl *batchLatencies) overall() *hdrhistogram.Histogram {
+	merged := newLatencyHistogram()
+	if bl == nil {
+		return merged
+	}
+	for _, w := range bl.workers {
+		w.mu.Lock()
+		merged.Merge(w.hist)
+		w.mu.Unlock()
+	}
+	return merged
+}
+
+// latencySummary returns min, mean, max and the reported quantiles
+// of the histogram, in milliseconds
+func latencySummary(h *hdrhistogram.Histogram) map[string]interface{} {
+	summary := map[string]interface{}{
+		"count": h.TotalCount(),
+		"min":   float64(h.Min()) / latencyScaleFactor,
+		"mean":  h.Mean() / latencyScaleFactor,
+		"max":   float64(h.Max()) / latencyScaleFactor,
+	}
+	for _, q := range reportedQuantiles {
+		summary[q.name] = float64(h.ValueAtQuantile(q.quantile)) / latencyScaleFactor
+	}
+	return summary
+}
+
+// latencyString makes a single line description of the histogram
+func latencyString(h *hdrhistogram.Histogram) string {
+	s := fmt.Sprintf("min: %0.2fms, mean: %0.2fms", float64(h.Min())/latencyScaleFactor, h.Mean()/latencyScaleFactor)
+	for _, q := range reportedQuantiles {
+		s += fmt.Sprintf(", %s: %0.2fms", q.name, float64(h.ValueAtQuantile(q.quantile))/latencyScaleFactor)
+	}
+	return s + fmt.Sprintf(", max: %0.2fms, count: %d", float64(h.Max())/latencyScaleFactor, h.TotalCount())
+}
+
+// writeHDRLatencies writes the percentile distribution of the histogram (in milliseconds) to fileName
+func writeHDRLatencies(h *hdrhistogram.Histogram, fileName string) error {
+	f, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	bw := bufio.NewWriter(f)
+	if _, err = h.PercentilesPrint(bw, 10, latencyScaleFactor); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,84 @@
+package load
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBatchLatencies(t *testing.T) {
+	bl := newBatchLatencies(2)
+	bl.record(0, 500*time.Microsecond)
+	bl.record(0, 1500*time.Microsecond)
+	bl.record(1, time.Millisecond)
+	// out of range workers are ignored
+	bl.record(2, time.Second)
+
+	if got := bl.worker(0).TotalCount(); got != 2 {
+		t.Errorf("wrong count for worker 0: got %d want %d", got, 2)
+	}
+	if got := bl.worker(1).TotalCount(); got != 1 {
+		t.Errorf("wrong count for worker 1: got %d want %d", got, 1)
+	}
+
+	overall := bl.overall()
+	if got := overall.TotalCount(); got != 3 {
+		t.Errorf("wrong overall count: got %d want %d", got, 3)
+	}
+
+	summary := latencySummary(overall)
+	if got := summary["min"].(float64); got != 0.5 {
+		t.Errorf("wrong min: got %f want %f", got, 0.5)
+	}
+	if got := summary["mean"].(float64); got != 1.0 {
+		t.Errorf("wrong mean: got %f want %f", got, 1.0)
+	}
+	for _, key := range []string{"max", "p50", "p90", "p99", "p999"} {
+		if _, ok := summary[key]; !ok {
+			t.Errorf("summary missing %s", key)
+		}
+	}
+}
+
+func TestBatchLatenciesNil(t *testing.T) {
+	var bl *batchLatencies
+	bl.record(0, time.Millisecond)
+	if got := bl.overall().TotalCount(); got != 0 {
+		t.Errorf("nil latencies should be empty: got %d", got)
+	}
+}
+
+func TestLatencyString(t *testing.T) {
+	bl := newBatchLatencies(1)
+	bl.record(0, time.Millisecond)
+	got := latencyString(bl.overall())
+	want := "min: 1.00ms, mean: 1.00ms, p50: 1.00ms, p90: 1.00ms, p99: 1.00ms, p999: 1.00ms, max: 1.00ms, count: 1"
+	if got != want {
+		t.Errorf("wrong latency string\ngot  %s\nwant %s", got, want)
+	}
+}
+
+func TestWriteHDRLatencies(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hdr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	bl := newBatchLatencies(1)
+	bl.record(0, 10*time.Millisecond)
+	fileName := filepath.Join(dir, "latencies.hdr")
+	if err := writeHDRLatencies(bl.overall(), fileName); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	contents, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(contents), "Value") {
+		t.Errorf("HDR file does not contain the percentile distribution header")
+	}
+}
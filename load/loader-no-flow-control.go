@@ -3,12 +3,10 @@ package load
 import (
 	"github.com/timescale/tsbs/pkg/targets"
 	"sync"
-	"sync/atomic"
-	"time"
 )
 
 type noFlowBenchmarkRunner struct {
-	CommonBenchmarkRunner
+	*CommonBenchmarkRunner
 }
 
 func (l *noFlowBenchmarkRunner) RunBenchmark(b targets.Benchmark) {
@@ -21,13 +19,20 @@ func (l *noFlowBenchmarkRunner) RunBenchmark(b targets.Benchmark) {
 		numChannels = 1
 	}
 	channels := l.createChannels(numChannels, l.ChannelCapacity)
+	l.metrics.watchQueue(func() int {
+		queued := 0
+		for _, c := range channels {
+			queued += len(c)
+		}
+		return queued
+	})
 
 	// Launch all worker processes in background
 	for i := uint(0); i < l.Workers; i++ {
 		go l.work(b, wg, channels[i%numChannels], i)
 	}
 	// Start scan process - actual data read process
-	scanWithoutFlowControl(b.GetDataSource(), b.GetPointIndexer(numChannels), b.GetBatchFactory(), channels, l.BatchSize, l.Limit)
+	scanWithoutFlowControl(l.getDataSource(b, *start), b.GetPointIndexer(numChannels), b.GetBatchFactory(), channels, l.BatchSize, l.scanLimit(), l.checkpoints)
 	for _, c := range channels {
 		close(c)
 	}
@@ -53,10 +58,7 @@ func (l *noFlowBenchmarkRunner) work(b targets.Benchmark, wg *sync.WaitGroup, c
 
 	// Process batches coming from the incoming queue (c)
 	for batch := range c {
-		startedWorkAt := time.Now()
-		metricCnt, rowCnt := proc.ProcessBatch(batch, l.DoLoad)
-		atomic.AddUint64(&l.metricCnt, metricCnt)
-		atomic.AddUint64(&l.rowCnt, rowCnt)
+		startedWorkAt := l.handleBatch(proc, batch, workerNum)
 		l.timeToSleep(workerNum, startedWorkAt)
 	}
 
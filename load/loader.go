@@ -4,14 +4,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/timescale/tsbs/pkg/targets"
+	"io"
 	"io/ioutil"
 	"log"
 	"math/rand"
+	"os"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/internal/interrupt"
+	"github.com/timescale/tsbs/internal/slo"
 	"github.com/timescale/tsbs/load/insertstrategy"
 )
 
@@ -21,6 +25,10 @@ const (
 	DefaultChannelCapacityFlagVal   = 0
 	defaultChannelCapacityPerWorker = 5
 	errDBExistsFmt                  = "database \"%s\" exists: aborting."
+
+	// TargetRateUnitRows and TargetRateUnitMetrics are the valid units of the target rate
+	TargetRateUnitRows    = "rows"
+	TargetRateUnitMetrics = "metrics"
 )
 
 // change for more useful testing
@@ -44,6 +52,28 @@ type BenchmarkRunnerConfig struct {
 	ChannelCapacity uint          `yaml:"channel-capacity" mapstructure:"channel-capacity" json:"channel-capacity"`
 	InsertIntervals string        `yaml:"insert-intervals" mapstructure:"insert-intervals" json:"insert-intervals"`
 	ResultsFile     string        `yaml:"results-file" mapstructure:"results-file" json:"results-file"`
+	HDRLatencies    string        `yaml:"hdr-latencies" mapstructure:"hdr-latencies" json:"hdr-latencies"`
+	TargetRate      float64       `yaml:"target-rate" mapstructure:"target-rate" json:"target-rate"`
+	TargetRateUnit  string        `yaml:"target-rate-unit" mapstructure:"target-rate-unit" json:"target-rate-unit"`
+	Duration        time.Duration `yaml:"duration" mapstructure:"duration" json:"duration"`
+	Warmup          time.Duration `yaml:"warmup" mapstructure:"warmup" json:"warmup"`
+	MaxRetries      uint          `yaml:"max-retries" mapstructure:"max-retries" json:"max-retries"`
+	RetryBackoff    time.Duration `yaml:"retry-backoff" mapstructure:"retry-backoff" json:"retry-backoff"`
+	RetryMaxBackoff time.Duration `yaml:"retry-max-backoff" mapstructure:"retry-max-backoff" json:"retry-max-backoff"`
+	ErrorBudget     uint64        `yaml:"error-budget" mapstructure:"error-budget" json:"error-budget"`
+	// MetricsListenAddr is the address to serve live Prometheus metrics on, disabled if empty
+	MetricsListenAddr string `yaml:"metrics-listen-addr" mapstructure:"metrics-listen-addr" json:"metrics-listen-addr"`
+	ReportFormat      string `yaml:"report-format" mapstructure:"report-format" json:"report-format"`
+	ReportFile        string `yaml:"report-file" mapstructure:"report-file" json:"report-file"`
+	// CheckpointFile is where the progress of the load is saved every CheckpointInterval, disabled if empty
+	CheckpointFile     string        `yaml:"checkpoint-file" mapstructure:"checkpoint-file" json:"checkpoint-file"`
+	CheckpointInterval time.Duration `yaml:"checkpoint-interval" mapstructure:"checkpoint-interval" json:"checkpoint-interval"`
+	Resume             bool          `yaml:"resume" mapstructure:"resume" json:"resume"`
+	// DataSource is the config of the data source of the load, it identifies the input
+	// data of a checkpoint together with FileName and Seed
+	DataSource interface{} `yaml:"-" mapstructure:"-" json:"-"`
+	// Asserts are the assertions checked against the final stats, see package slo
+	Asserts []string `yaml:"assert" mapstructure:"assert" json:"assert"`
 	// deprecated, should not be used in other places other than tsbs_load_xx commands
 	FileName string `yaml:"file" mapstructure:"file" json:"file"`
 	Seed     int64  `yaml:"seed" mapstructure:"seed" json:"seed"`
@@ -64,6 +94,22 @@ func (c BenchmarkRunnerConfig) AddToFlagSet(fs *pflag.FlagSet) {
 	fs.String("insert-intervals", "", "Time to wait between each insert, default '' => all workers insert ASAP. '1,2' = worker 1 waits 1s between inserts, worker 2 and others wait 2s")
 	fs.Bool("hash-workers", false, "Whether to consistently hash insert data to the same workers (i.e., the data for a particular host always goes to the same worker)")
 	fs.String("results-file", "", "Write the test results summary json to this file")
+	fs.String("hdr-latencies", "", "Write the High Dynamic Range (HDR) Histogram of batch insert latencies to this file")
+	fs.Float64("target-rate", 0, "Insert at this constant rate, independent of how long inserts take (0 = as fast as possible). Latencies are measured from the scheduled start of each batch")
+	fs.String("target-rate-unit", TargetRateUnitRows, "Unit of --target-rate: 'rows' (data points read) or 'metrics' per second")
+	fs.Duration("duration", 0, "Stop loading after this wall-clock duration, warm-up included (0 = no time limit)")
+	fs.Duration("warmup", 0, "Exclude the first part of the load of this duration from the reported rates and latencies")
+	fs.Uint("max-retries", 0, "Number of times a failed batch is retried before it counts as failed (only for targets that report insert errors)")
+	fs.Duration("retry-backoff", defaultRetryBackoff, "Time to wait before the first retry of a failed batch, doubled on every following retry")
+	fs.Duration("retry-max-backoff", defaultRetryMaxBackoff, "Maximum time to wait between two retries of a failed batch")
+	fs.Uint64("error-budget", 0, "Number of failed batches tolerated before the load is aborted (0 = abort on the first failed batch)")
+	fs.String("report-format", ReportFormatText, "Format of the periodic report: 'text' (human readable), 'csv' or 'jsonl' (one JSON object per line)")
+	fs.String("report-file", "", "Write the periodic report to this file instead of stdout")
+	fs.String("checkpoint-file", "", "Periodically save the number of items loaded to this file, so an interrupted load can be resumed")
+	fs.Duration("checkpoint-interval", defaultCheckpointInterval, "Period to save the checkpoint-file")
+	fs.Bool("resume", false, "Resume the load from the checkpoint-file: skip the items already loaded and don't (re)create the database")
+	fs.String("metrics-listen-addr", "", "Serve live Prometheus metrics of the load on this address (e.g. ':9090'), on the /metrics path")
+	fs.StringSlice("assert", nil, "Assertion on the final stats, e.g. 'metricRate>500000', 'p99<100ms' or 'errors==0', repeated or comma separated. The load exits with 3 if one fails. Stats: metricRate, rowRate, metrics, rows, retriedBatches, failedBatches (or errors) and the batch latency: pN (e.g. p99.9), min, mean and max (in ms)")
 }
 
 type BenchmarkRunner interface {
@@ -75,10 +121,30 @@ type BenchmarkRunner interface {
 // flags across all database systems and ultimately running a supplied Benchmark
 type CommonBenchmarkRunner struct {
 	BenchmarkRunnerConfig
-	metricCnt      uint64
-	rowCnt         uint64
-	initialRand    *rand.Rand
-	sleepRegulator insertstrategy.SleepRegulator
+	metricCnt       uint64
+	rowCnt          uint64
+	itemCnt         uint64
+	batchCnt        uint64
+	retriedBatchCnt uint64
+	failedBatchCnt  uint64
+	initialRand     *rand.Rand
+	sleepRegulator  insertstrategy.SleepRegulator
+	retries         *retryPolicy
+	latencies       *batchLatencies
+	rateScheduler   *insertstrategy.RateScheduler
+	warmup          warmupPhase
+	metrics         *runnerMetrics
+	checkpoints     *checkpointTracker
+	assertions      []slo.Assertion
+	stopCheckpoints func()
+	interrupted     int32
+	stopped         int32
+	stopWatching    func()
+	// ready is closed once the database is set up, if not nil
+	ready chan struct{}
+	// done is closed when the run finished, took is how long it took
+	done chan struct{}
+	took time.Duration
 }
 
 // GetBenchmarkRunnerWithBatchSize returns the singleton CommonBenchmarkRunner for use in a benchmark program
@@ -92,9 +158,34 @@ func GetBenchmarkRunner(c BenchmarkRunnerConfig) BenchmarkRunner {
 	}
 
 	loader.initialRand = rand.New(rand.NewSource(loader.Seed))
+	loader.retries = newRetryPolicy(&loader.BenchmarkRunnerConfig)
+	loader.latencies = newBatchLatencies(loader.Workers)
+	assertions, err := parseAssertions(c.Asserts)
+	if err != nil {
+		panic(fmt.Sprintf("could not initialize BenchmarkRunner: %v", err))
+	}
+	loader.assertions = assertions
+	if c.MetricsListenAddr != "" {
+		loader.metrics = newRunnerMetrics(&loader)
+	}
+
+	if _, err := newProgressWriter(c.ReportFormat, ioutil.Discard, false, false, false); err != nil {
+		panic(fmt.Sprintf("could not initialize BenchmarkRunner: %v", err))
+	}
 
-	var err error
-	if c.InsertIntervals == "" {
+	if c.TargetRate > 0 {
+		if c.InsertIntervals != "" {
+			panic("could not initialize BenchmarkRunner: target-rate and insert-intervals can't be used together")
+		}
+		if c.TargetRateUnit != "" && c.TargetRateUnit != TargetRateUnitRows && c.TargetRateUnit != TargetRateUnitMetrics {
+			panic(fmt.Sprintf("could not initialize BenchmarkRunner: invalid target-rate-unit '%s'", c.TargetRateUnit))
+		}
+		loader.rateScheduler, err = insertstrategy.NewRateScheduler(c.TargetRate)
+		if err != nil {
+			panic(fmt.Sprintf("could not initialize BenchmarkRunner: %v", err))
+		}
+		loader.sleepRegulator = insertstrategy.NoWait()
+	} else if c.InsertIntervals == "" {
 		loader.sleepRegulator = insertstrategy.NoWait()
 	} else {
 		loader.sleepRegulator, err = insertstrategy.NewSleepRegulator(c.InsertIntervals, int(loader.Workers), loader.initialRand)
@@ -114,7 +205,7 @@ func GetBenchmarkRunner(c BenchmarkRunnerConfig) BenchmarkRunner {
 		}
 	}
 
-	return &noFlowBenchmarkRunner{loader}
+	return &noFlowBenchmarkRunner{&loader}
 }
 
 // DatabaseName returns the value of the --db-name flag (name of the database to store data)
@@ -123,40 +214,128 @@ func (l *CommonBenchmarkRunner) DatabaseName() string {
 }
 
 func (l *CommonBenchmarkRunner) preRun(b targets.Benchmark) (*sync.WaitGroup, *time.Time) {
+	// Read the checkpoint to resume from before touching the database
+	l.stopCheckpoints = l.startCheckpoints()
+
 	// Create required DB
 	if b.GetDBCreator() != nil {
 		cleanupFn := l.useDBCreator(b.GetDBCreator())
 		defer cleanupFn()
 	}
+	if l.ready != nil {
+		close(l.ready)
+	}
 
+	l.serveMetrics()
+	l.done = make(chan struct{})
 	if l.ReportingPeriod.Nanoseconds() > 0 {
 		go l.report(l.ReportingPeriod)
 	}
+	// SIGINT and SIGTERM stop reading data, what was read is still loaded
+	l.stopWatching = interrupt.Watch(l.interrupt)
+
 	wg := &sync.WaitGroup{}
 	wg.Add(int(l.Workers))
 	start := time.Now()
+	l.startWarmup(start)
 	return wg, &start
 }
 
+// getDataSource returns the DataSource of the benchmark, limited to the
+// configured duration of the load (if any)
+func (l *CommonBenchmarkRunner) getDataSource(b targets.Benchmark, start time.Time) targets.DataSource {
+	ds := b.GetDataSource()
+	if sds, ok := ds.(targets.SeekableDataSource); ok && l.checkpoints != nil {
+		ds = &offsetDataSource{SeekableDataSource: sds, tracker: l.checkpoints}
+	}
+	if l.checkpoints != nil && l.checkpoints.skipped > 0 {
+		if l.Limit > 0 && l.checkpoints.skipped >= l.Limit {
+			// everything up to the limit was loaded already: a deadline
+			// that already passed makes the data source empty
+			return newDeadlineDataSource(ds, start)
+		}
+		ds = &skipDataSource{DataSource: ds, skip: l.checkpoints.skipped, offset: l.checkpoints.skippedOffset}
+	}
+	ds = &interruptibleDataSource{DataSource: ds, interrupted: l.stopReading}
+	if l.Duration > 0 {
+		return newDeadlineDataSource(ds, start.Add(l.Duration))
+	}
+	return ds
+}
+
 func (l *CommonBenchmarkRunner) postRun(wg *sync.WaitGroup, start *time.Time) {
 	// Wait for all workers to finish
 	wg.Wait()
+	if l.stopWatching != nil {
+		l.stopWatching()
+	}
+	l.stopWarmup()
+	if l.stopCheckpoints != nil {
+		l.stopCheckpoints()
+	}
 	end := time.Now()
 	took := end.Sub(*start)
+	l.took = took
+	close(l.done)
 	l.summary(took)
+	var assertions []slo.Result
+	if len(l.assertions) > 0 {
+		assertions = l.checkAssertions(took)
+	}
 	if l.BenchmarkRunnerConfig.ResultsFile != "" {
-		metricRate := float64(l.metricCnt) / took.Seconds()
-		rowRate := float64(l.rowCnt) / took.Seconds()
-		l.saveTestResult(took, *start, end, metricRate, rowRate)
+		metricCnt, rowCnt, measuredTook := l.measured(took)
+		metricRate := float64(metricCnt) / measuredTook.Seconds()
+		rowRate := float64(rowCnt) / measuredTook.Seconds()
+		l.saveTestResult(took, *start, end, metricRate, rowRate, assertions)
 	}
+	if l.HDRLatencies != "" {
+		_, _ = fmt.Printf("Saving High Dynamic Range (HDR) Histogram of batch insert latencies to %s\n", l.HDRLatencies)
+		if err := writeHDRLatencies(l.latencies.overall(), l.HDRLatencies); err != nil {
+			log.Fatal(err)
+		}
+	}
+	slo.ExitOnFailure(assertions)
 }
 
-func (l *CommonBenchmarkRunner) saveTestResult(took time.Duration, start time.Time, end time.Time, metricRate, rowRate float64) {
+func (l *CommonBenchmarkRunner) saveTestResult(took time.Duration, start time.Time, end time.Time, metricRate, rowRate float64, assertions []slo.Result) {
 	totals := make(map[string]interface{})
 	totals["metricRate"] = metricRate
 	if l.rowCnt > 0 {
 		totals["rowRate"] = rowRate
 	}
+	totals["retriedBatches"] = l.retriedBatchCnt
+	totals["failedBatches"] = l.failedBatchCnt
+	if ended, warmupTook := l.warmupEnded(); ended {
+		metricCnt, rowCnt, measuredTook := l.measured(took)
+		totals["warmup"] = map[string]interface{}{
+			"durationMillis": warmupTook.Milliseconds(),
+			"metrics":        l.metricCnt - metricCnt,
+			"rows":           l.rowCnt - rowCnt,
+		}
+		totals["measure"] = map[string]interface{}{
+			"durationMillis": measuredTook.Milliseconds(),
+			"metrics":        metricCnt,
+			"rows":           rowCnt,
+		}
+	}
+	if l.rateScheduler != nil {
+		lag, maxLag := l.rateScheduler.Lag()
+		totals["scheduleLag"] = map[string]interface{}{
+			"finalMillis": lag.Milliseconds(),
+			"maxMillis":   maxLag.Milliseconds(),
+		}
+	}
+	if l.latencies != nil {
+		totals["batchLatency"] = latencySummary(l.latencies.overall())
+		perWorker := make([]map[string]interface{}, len(l.latencies.workers))
+		for i := range perWorker {
+			perWorker[i] = latencySummary(l.latencies.worker(i))
+		}
+		totals["batchLatencyPerWorker"] = perWorker
+	}
+	if assertions != nil {
+		totals["assertions"] = assertions
+	}
 
 	testResult := LoaderTestResult{
 		ResultFormatVersion: LoaderTestResultVersion,
@@ -164,6 +343,7 @@ func (l *CommonBenchmarkRunner) saveTestResult(took time.Duration, start time.Ti
 		StartTime:           start.Unix(),
 		EndTime:             end.Unix(),
 		DurationMillis:      took.Milliseconds(),
+		Interrupted:         l.wasInterrupted(),
 		Totals:              totals,
 	}
 
@@ -192,6 +372,13 @@ func (l *CommonBenchmarkRunner) RunBenchmark(b targets.Benchmark) {
 	}
 
 	channels := l.createChannels(numChannels, capacity)
+	l.metrics.watchQueue(func() int {
+		queued := 0
+		for _, c := range channels {
+			queued += len(c.toWorker)
+		}
+		return queued
+	})
 
 	// Launch all worker processes in background
 	for i := uint(0); i < l.Workers; i++ {
@@ -199,7 +386,7 @@ func (l *CommonBenchmarkRunner) RunBenchmark(b targets.Benchmark) {
 	}
 
 	// Start scan process - actual data read process
-	scanWithFlowControl(channels, l.BatchSize, l.Limit, b.GetDataSource(), b.GetBatchFactory(), b.GetPointIndexer(uint(len(channels))))
+	scanWithFlowControl(channels, l.BatchSize, l.scanLimit(), l.getDataSource(b, *start), b.GetBatchFactory(), b.GetPointIndexer(uint(len(channels))), l.checkpoints)
 	// After scan process completed (no more data to come) - begin shutdown process
 
 	// Close all communication channels to/from workers
@@ -228,13 +415,13 @@ func (l *CommonBenchmarkRunner) useDBCreator(dbc targets.DBCreator) func() {
 
 		// Check whether required DB already exists
 		exists := dbc.DBExists(l.DBName)
-		if exists && l.DoAbortOnExist {
+		if exists && l.DoAbortOnExist && !l.Resume {
 			panic(fmt.Sprintf(errDBExistsFmt, l.DBName))
 		}
 
 		// Create required DB if need be
-		// In case DB already exists - delete it
-		if l.DoCreateDB {
+		// In case DB already exists - delete it, unless the load is resumed in it
+		if l.DoCreateDB && !(l.Resume && exists) {
 			if exists {
 				err := dbc.RemoveOldDB(l.DBName)
 				if err != nil {
@@ -281,10 +468,7 @@ func (l *CommonBenchmarkRunner) work(b targets.Benchmark, wg *sync.WaitGroup, c
 	// Process batches coming from duplexChannel.toWorker queue
 	// and send ACKs into duplexChannel.toScanner queue
 	for batch := range c.toWorker {
-		startedWorkAt := time.Now()
-		metricCnt, rowCnt := proc.ProcessBatch(batch, l.DoLoad)
-		atomic.AddUint64(&l.metricCnt, metricCnt)
-		atomic.AddUint64(&l.rowCnt, rowCnt)
+		startedWorkAt := l.handleBatch(proc, batch, workerNum)
 		c.sendToScanner()
 		l.timeToSleep(workerNum, startedWorkAt)
 	}
@@ -298,6 +482,48 @@ func (l *CommonBenchmarkRunner) work(b targets.Benchmark, wg *sync.WaitGroup, c
 	wg.Done()
 }
 
+// handleBatch processes a single batch for worker workerNum and records its counts
+// and latency. With a target rate the batch waits for its scheduled start and the
+// latency is measured from it. The time the batch started being worked on is returned.
+func (l *CommonBenchmarkRunner) handleBatch(proc targets.Processor, batch targets.Batch, workerNum uint) time.Time {
+	batchLen := batch.Len()
+	var intendedStart time.Time
+	if l.rateScheduler != nil {
+		intendedStart = l.rateScheduler.Next(l.scheduledItems(batchLen))
+	}
+	startedWorkAt := time.Now()
+	if intendedStart.IsZero() {
+		intendedStart = startedWorkAt
+	}
+
+	l.metrics.batchStarted()
+	first := l.checkpoints.claim(batch)
+	metricCnt, rowCnt, failed := l.processBatch(proc, batch)
+	l.checkpoints.done(first, failed, batchLen)
+	took := time.Since(intendedStart)
+	l.latencies.record(workerNum, took)
+	l.metrics.batchDone(took)
+	atomic.AddUint64(&l.batchCnt, 1)
+	atomic.AddUint64(&l.metricCnt, metricCnt)
+	atomic.AddUint64(&l.rowCnt, rowCnt)
+	atomic.AddUint64(&l.itemCnt, uint64(batchLen))
+	return startedWorkAt
+}
+
+// scheduledItems converts the length of a batch into the unit of the target rate.
+// For metrics the number of metrics per item observed so far is used.
+func (l *CommonBenchmarkRunner) scheduledItems(batchLen uint) float64 {
+	if l.TargetRateUnit != TargetRateUnitMetrics {
+		return float64(batchLen)
+	}
+	items := atomic.LoadUint64(&l.itemCnt)
+	if items == 0 {
+		return float64(batchLen)
+	}
+	metrics := atomic.LoadUint64(&l.metricCnt)
+	return float64(batchLen) * float64(metrics) / float64(items)
+}
+
 func (l *CommonBenchmarkRunner) timeToSleep(workerNum uint, startedWorkAt time.Time) {
 	if l.sleepRegulator != nil {
 		l.sleepRegulator.Sleep(int(workerNum), startedWorkAt)
@@ -306,41 +532,143 @@ func (l *CommonBenchmarkRunner) timeToSleep(workerNum uint, startedWorkAt time.T
 
 // summary prints the summary of statistics from loading
 func (l *CommonBenchmarkRunner) summary(took time.Duration) {
-	metricRate := float64(l.metricCnt) / took.Seconds()
 	printFn("\nSummary:\n")
-	printFn("loaded %d metrics in %0.3fsec with %d workers (mean rate %0.2f metrics/sec)\n", l.metricCnt, took.Seconds(), l.Workers, metricRate)
-	if l.rowCnt > 0 {
-		rowRate := float64(l.rowCnt) / float64(took.Seconds())
-		printFn("loaded %d rows in %0.3fsec with %d workers (mean rate %0.2f rows/sec)\n", l.rowCnt, took.Seconds(), l.Workers, rowRate)
+	if l.wasInterrupted() {
+		printFn("load interrupted, the results only cover the data loaded until then\n")
+	}
+	if ended, warmupTook := l.warmupEnded(); ended {
+		printFn("excluded warm-up of %0.3fsec from the results\n", warmupTook.Seconds())
+	} else if l.Warmup > 0 {
+		printFn("load finished before the warm-up of %v ended, results include the warm-up\n", l.Warmup)
+	}
+	metricCnt, rowCnt, took := l.measured(took)
+	metricRate := float64(metricCnt) / took.Seconds()
+	printFn("loaded %d metrics in %0.3fsec with %d workers (mean rate %0.2f metrics/sec)\n", metricCnt, took.Seconds(), l.Workers, metricRate)
+	if rowCnt > 0 {
+		rowRate := float64(rowCnt) / float64(took.Seconds())
+		printFn("loaded %d rows in %0.3fsec with %d workers (mean rate %0.2f rows/sec)\n", rowCnt, took.Seconds(), l.Workers, rowRate)
+	}
+	if l.retriedBatchCnt > 0 || l.failedBatchCnt > 0 {
+		printFn("retried %d batches, %d batches failed\n", l.retriedBatchCnt, l.failedBatchCnt)
+	}
+	if l.rateScheduler != nil {
+		lag, maxLag := l.rateScheduler.Lag()
+		printFn("target rate %0.2f %s/sec, behind schedule at the end: %v (max %v)\n", l.TargetRate, l.targetRateUnit(), lag, maxLag)
+	}
+	if l.latencies == nil {
+		return
+	}
+	overall := l.latencies.overall()
+	if overall.TotalCount() == 0 {
+		return
+	}
+	printFn("batch insert latency:\n%s\n", latencyString(overall))
+	if len(l.latencies.workers) > 1 {
+		for i := range l.latencies.workers {
+			printFn("worker %d: %s\n", i, latencyString(l.latencies.worker(i)))
+		}
 	}
 }
 
 // report handles periodic reporting of loading stats
 func (l *CommonBenchmarkRunner) report(period time.Duration) {
+	var out io.Writer
+	if l.ReportFile != "" {
+		f, err := os.Create(l.ReportFile)
+		if err != nil {
+			fatal("could not create report file %s: %v", l.ReportFile, err)
+			return
+		}
+		defer f.Close()
+		out = f
+	}
+	w, err := newProgressWriter(l.ReportFormat, out, l.Warmup > 0, l.rateScheduler != nil, l.reportsErrors())
+	if err != nil {
+		fatal("%v", err)
+		return
+	}
+
 	start := time.Now()
 	prevTime := start
 	prevColCount := uint64(0)
 	prevRowCount := uint64(0)
 
-	printFn("time,per. metric/s,metric total,overall metric/s,per. row/s,row total,overall row/s\n")
-	for now := range time.NewTicker(period).C {
-		cCount := atomic.LoadUint64(&l.metricCnt)
-		rCount := atomic.LoadUint64(&l.rowCnt)
-
-		sinceStart := now.Sub(start)
-		took := now.Sub(prevTime)
-		colrate := float64(cCount-prevColCount) / float64(took.Seconds())
-		overallColRate := float64(cCount) / float64(sinceStart.Seconds())
-		if rCount > 0 {
-			rowrate := float64(rCount-prevRowCount) / float64(took.Seconds())
-			overallRowRate := float64(rCount) / float64(sinceStart.Seconds())
-			printFn("%d,%0.2f,%E,%0.2f,%0.2f,%E,%0.2f\n", now.Unix(), colrate, float64(cCount), overallColRate, rowrate, float64(rCount), overallRowRate)
-		} else {
-			printFn("%d,%0.2f,%E,%0.2f,-,-,-\n", now.Unix(), colrate, float64(cCount), overallColRate)
+	if err := w.writeHeader(); err != nil {
+		fatal("could not write report: %v", err)
+		return
+	}
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		var now time.Time
+		select {
+		case now = <-ticker.C:
+		case <-l.done:
+			return
+		}
+		r := l.progress(now, start, prevTime, prevColCount, prevRowCount)
+		if err := w.write(r); err != nil {
+			fatal("could not write report: %v", err)
+			return
 		}
 
-		prevColCount = cCount
-		prevRowCount = rCount
+		prevColCount = r.MetricTotal
+		prevRowCount = r.RowTotal
 		prevTime = now
 	}
 }
+
+// progress returns the state of the load at now, with the rates since prevTime
+// when prevColCount metrics and prevRowCount rows were loaded
+func (l *CommonBenchmarkRunner) progress(now, start, prevTime time.Time, prevColCount, prevRowCount uint64) *progressRecord {
+	cCount := atomic.LoadUint64(&l.metricCnt)
+	rCount := atomic.LoadUint64(&l.rowCnt)
+
+	// overall rates are calculated for the current phase only
+	phase, measureStart, warmupColCount, warmupRowCount := l.phase()
+	phaseStart := start
+	if !measureStart.IsZero() {
+		phaseStart = measureStart
+	}
+	sinceStart := now.Sub(phaseStart)
+	took := now.Sub(prevTime)
+
+	r := &progressRecord{
+		Timestamp:         now.Unix(),
+		IntervalSeconds:   took.Seconds(),
+		MetricRate:        float64(cCount-prevColCount) / took.Seconds(),
+		MetricTotal:       cCount,
+		OverallMetricRate: float64(cCount-warmupColCount) / sinceStart.Seconds(),
+		RowRate:           float64(rCount-prevRowCount) / took.Seconds(),
+		RowTotal:          rCount,
+		OverallRowRate:    float64(rCount-warmupRowCount) / sinceStart.Seconds(),
+		Batches:           atomic.LoadUint64(&l.batchCnt),
+		RetriedBatches:    atomic.LoadUint64(&l.retriedBatchCnt),
+		FailedBatches:     atomic.LoadUint64(&l.failedBatchCnt),
+	}
+	if l.Warmup > 0 {
+		r.Phase = phase
+	}
+	if l.rateScheduler != nil {
+		lag, _ := l.rateScheduler.Lag()
+		lagSeconds := lag.Seconds()
+		r.ScheduleLag = &lagSeconds
+	}
+	if l.latencies != nil {
+		if overall := l.latencies.overall(); overall.TotalCount() > 0 {
+			r.Latency = make(map[string]float64, len(reportedQuantiles))
+			for _, q := range reportedQuantiles {
+				r.Latency[q.name] = float64(overall.ValueAtQuantile(q.quantile)) / latencyScaleFactor
+			}
+		}
+	}
+	return r
+}
+
+// targetRateUnit returns the unit of the target rate, defaulting to rows
+func (l *CommonBenchmarkRunner) targetRateUnit() string {
+	if l.TargetRateUnit == "" {
+		return TargetRateUnitRows
+	}
+	return l.TargetRateUnit
+}
@@ -3,6 +3,7 @@ package load
 import (
 	"bytes"
 	"fmt"
+	"github.com/timescale/tsbs/load/insertstrategy"
 	"github.com/timescale/tsbs/pkg/targets"
 	"strings"
 	"sync"
@@ -378,6 +379,57 @@ func TestWorkWithSleep(t *testing.T) {
 	}
 }
 
+func TestWorkWithTargetRate(t *testing.T) {
+	scheduler, err := insertstrategy.NewRateScheduler(1000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	br := &CommonBenchmarkRunner{
+		rateScheduler: scheduler,
+		latencies:     newBatchLatencies(1),
+	}
+	b := &testBenchmark{}
+	b.processors = append(b.processors, &testProcessor{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	c := newDuplexChannel(2)
+	c.sendToWorker(&testBatch{len: 10})
+	c.sendToWorker(&testBatch{len: 10})
+	start := time.Now()
+	go br.work(b, &wg, c, 0)
+	<-c.toScanner
+	<-c.toScanner
+	c.close()
+	wg.Wait()
+
+	// 10 items at 1000 items/sec: the second batch is scheduled 10ms after the first
+	if took := time.Since(start); took < 10*time.Millisecond {
+		t.Errorf("second batch did not wait for its schedule, took %v", took)
+	}
+	if got := br.itemCnt; got != 20 {
+		t.Errorf("invalid item count: got %d want %d", got, 20)
+	}
+	if got := br.latencies.overall().TotalCount(); got != 2 {
+		t.Errorf("invalid number of recorded latencies: got %d want %d", got, 2)
+	}
+}
+
+func TestScheduledItems(t *testing.T) {
+	br := &CommonBenchmarkRunner{}
+	if got := br.scheduledItems(10); got != 10 {
+		t.Errorf("rows: got %f want %f", got, 10.0)
+	}
+	br.TargetRateUnit = TargetRateUnitMetrics
+	if got := br.scheduledItems(10); got != 10 {
+		t.Errorf("metrics without observed batches: got %f want %f", got, 10.0)
+	}
+	br.itemCnt = 5
+	br.metricCnt = 50
+	if got := br.scheduledItems(10); got != 100 {
+		t.Errorf("metrics with 10 metrics per item: got %f want %f", got, 100.0)
+	}
+}
+
 func TestSummary(t *testing.T) {
 	cases := []struct {
 		desc    string
@@ -0,0 +1,89 @@
+package load
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/timescale/tsbs/internal/metrics"
+)
+
+// runnerMetrics are the live metrics of a load, served with --metrics-listen-addr.
+// The totals are read from the counters of the runner when scraped, only the
+// in-flight batches and the latency histogram are updated by the workers.
+type runnerMetrics struct {
+	registry      *prometheus.Registry
+	inFlight      prometheus.Gauge
+	batchDuration prometheus.Histogram
+}
+
+func newRunnerMetrics(l *CommonBenchmarkRunner) *runnerMetrics {
+	m := &runnerMetrics{
+		registry: prometheus.NewRegistry(),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "tsbs_load_inflight_batches",
+			Help: "Number of batches being inserted by the workers at the moment.",
+		}),
+		batchDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "tsbs_load_batch_duration_seconds",
+			Help:    "Insert latency of a batch, measured from its scheduled start when a target rate is set.",
+			Buckets: metrics.LatencyBuckets,
+		}),
+	}
+	counterFunc := func(name, help string, cnt *uint64) prometheus.Collector {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{Name: name, Help: help}, func() float64 {
+			return float64(atomic.LoadUint64(cnt))
+		})
+	}
+	m.registry.MustRegister(
+		m.inFlight,
+		m.batchDuration,
+		counterFunc("tsbs_load_metrics_total", "Number of metrics loaded.", &l.metricCnt),
+		counterFunc("tsbs_load_rows_total", "Number of rows loaded.", &l.rowCnt),
+		counterFunc("tsbs_load_batches_total", "Number of batches processed.", &l.batchCnt),
+		counterFunc("tsbs_load_retried_batches_total", "Number of batches that were retried after an insert error.", &l.retriedBatchCnt),
+		counterFunc("tsbs_load_failed_batches_total", "Number of batches that failed after all retries.", &l.failedBatchCnt),
+	)
+	return m
+}
+
+// watchQueue registers a gauge with the number of batches waiting for a worker,
+// as reported by depth when scraped
+func (m *runnerMetrics) watchQueue(depth func() int) {
+	if m == nil {
+		return
+	}
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "tsbs_load_queued_batches",
+		Help: "Number of batches read by the scanner that wait for a worker.",
+	}, func() float64 {
+		return float64(depth())
+	}))
+}
+
+// batchStarted and batchDone track a batch being inserted, and its latency
+func (m *runnerMetrics) batchStarted() {
+	if m != nil {
+		m.inFlight.Inc()
+	}
+}
+
+func (m *runnerMetrics) batchDone(took time.Duration) {
+	if m != nil {
+		m.inFlight.Dec()
+		m.batchDuration.Observe(took.Seconds())
+	}
+}
+
+// serveMetrics starts serving the live metrics if a listen address is configured
+func (l *CommonBenchmarkRunner) serveMetrics() {
+	if l.metrics == nil {
+		return
+	}
+	ln, err := metrics.Serve(l.MetricsListenAddr, l.metrics.registry)
+	if err != nil {
+		fatal("could not serve metrics on %s: %v", l.MetricsListenAddr, err)
+		return
+	}
+	printFn("serving live metrics on http://%s%s\n", ln.Addr(), metrics.Path)
+}
@@ -0,0 +1,62 @@
+package load
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRunnerMetrics(t *testing.T) {
+	br := &CommonBenchmarkRunner{}
+	br.metrics = newRunnerMetrics(br)
+	queued := 3
+	br.metrics.watchQueue(func() int { return queued })
+
+	br.handleBatch(&testProcessor{}, &testBatch{id: 1, len: 2}, 0)
+	br.retriedBatchCnt = 2
+
+	m := br.metrics
+	if got := testutil.ToFloat64(m.inFlight); got != 0 {
+		t.Errorf("no batch should be in flight, got %f", got)
+	}
+	if got := testutil.CollectAndCount(m.batchDuration); got != 1 {
+		t.Errorf("batch latency histogram not collected: got %d", got)
+	}
+	want := map[string]float64{
+		"tsbs_load_batches_total":         1,
+		"tsbs_load_metrics_total":         float64(br.metricCnt),
+		"tsbs_load_retried_batches_total": 2,
+		"tsbs_load_queued_batches":        3,
+	}
+	families, err := m.registry.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, f := range families {
+		v, ok := want[f.GetName()]
+		if !ok {
+			continue
+		}
+		delete(want, f.GetName())
+		var got float64
+		if c := f.GetMetric()[0].GetCounter(); c != nil {
+			got = c.GetValue()
+		} else {
+			got = f.GetMetric()[0].GetGauge().GetValue()
+		}
+		if got != v {
+			t.Errorf("wrong value of %s: got %f want %f", f.GetName(), got, v)
+		}
+	}
+	if len(want) > 0 {
+		t.Errorf("metrics not registered: %v", want)
+	}
+}
+
+func TestRunnerMetricsDisabled(t *testing.T) {
+	var m *runnerMetrics
+	m.watchQueue(func() int { return 0 })
+	m.batchStarted()
+	m.batchDone(time.Second)
+}
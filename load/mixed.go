@@ -0,0 +1,215 @@
+package load
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// MixedConfig configures the query side of a mixed read/write workload
+type MixedConfig struct {
+	// Queries is the configuration of the query runner, its DBName is replaced by the one of the load
+	Queries query.BenchmarkRunnerConfig
+	// QueryPool and CreateQueryProcessor are the ones of the target, see targets.QueryTarget
+	QueryPool            *sync.Pool
+	CreateQueryProcessor query.ProcessorCreate
+	// Baseline runs the queries again once the load finished, without writes,
+	// to compare the latencies under load with
+	Baseline bool
+	// ResultsFile is where the combined results are saved, if set
+	ResultsFile string
+}
+
+// mixedLoad is the write side of a mixed workload run
+type mixedLoad struct {
+	DurationMillis int64                  `json:"durationMillis"`
+	Metrics        uint64                 `json:"metrics"`
+	Rows           uint64                 `json:"rows"`
+	MetricRate     float64                `json:"metricRate"`
+	RowRate        float64                `json:"rowRate"`
+	BatchLatency   map[string]interface{} `json:"batchLatency"`
+}
+
+// mixedQueries is the read side of a mixed workload run, or of its baseline
+type mixedQueries struct {
+	DurationMillis int64                       `json:"durationMillis"`
+	QueryRate      float64                     `json:"queryRate"`
+	Labels         map[string]query.LabelStats `json:"labels"`
+}
+
+// queryCount returns the number of queries run, all labels together
+func (q *mixedQueries) queryCount() int64 {
+	var count int64
+	for label, stats := range q.Labels {
+		if label == query.LabelAllQueries {
+			return stats.Count
+		}
+		count += stats.Count
+	}
+	return count
+}
+
+// RunMixed loads the data of b with the configuration c while the queries of m
+// run against the same database, each side with its own workers and rate. The
+// queries start once the database is set up and the run ends when either side
+// finishes, the other one is stopped. It prints a combined report of the ingest
+// rate and the query latencies under load, next to the latencies without writes
+// when m.Baseline is set.
+func RunMixed(c BenchmarkRunnerConfig, b targets.Benchmark, m MixedConfig) {
+	if m.QueryPool == nil || m.CreateQueryProcessor == nil {
+		panic("mixed workload needs the query pool and processor of the target")
+	}
+	if m.Queries.WorkersSweep != "" {
+		panic("workers-sweep runs the queries several times, it can't be used in a mixed workload")
+	}
+	if len(c.Asserts) > 0 || len(m.Queries.Asserts) > 0 {
+		panic("assert checks the stats of a single run, it can't be used in a mixed workload")
+	}
+	m.Queries.DBName = c.DBName
+	br := GetBenchmarkRunner(c)
+	loader := commonRunner(br)
+	loader.ready = make(chan struct{})
+	queries := query.NewBenchmarkRunner(m.Queries)
+
+	start := time.Now()
+	var queriesTook time.Duration
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		br.RunBenchmark(b)
+		// only the queries run while data is loaded count
+		queries.Stop()
+	}()
+	go func() {
+		defer wg.Done()
+		<-loader.ready
+		queriesStart := time.Now()
+		queries.Run(m.QueryPool, m.CreateQueryProcessor)
+		queriesTook = time.Since(queriesStart)
+		loader.stop()
+	}()
+	wg.Wait()
+
+	metricCnt, rowCnt, took := loader.measured(loader.took)
+	load := &mixedLoad{
+		DurationMillis: took.Milliseconds(),
+		Metrics:        metricCnt,
+		Rows:           rowCnt,
+		MetricRate:     float64(metricCnt) / took.Seconds(),
+		RowRate:        float64(rowCnt) / took.Seconds(),
+		BatchLatency:   latencySummary(loader.latencies.overall()),
+	}
+	underLoad := newMixedQueries(queries.LabelStats(), queriesTook)
+
+	var baseline *mixedQueries
+	if m.Baseline && !loader.wasInterrupted() {
+		printFn("\nRunning the queries without writes for the baseline\n")
+		baselineConfig := m.Queries
+		baselineConfig.ResultsFile = ""
+		baselineConfig.HDRLatenciesFile = ""
+		baselineConfig.HDRLatenciesDir = ""
+		baselineConfig.ReportFile = ""
+		baselineConfig.MemProfile = ""
+		baselineConfig.ErrorLogFile = ""
+		// the metrics server of the run under load is still listening on the address
+		baselineConfig.MetricsListenAddr = ""
+		baselineQueries := query.NewBenchmarkRunner(baselineConfig)
+		baselineStart := time.Now()
+		baselineQueries.Run(m.QueryPool, m.CreateQueryProcessor)
+		baseline = newMixedQueries(baselineQueries.LabelStats(), time.Since(baselineStart))
+	}
+
+	printMixedSummary(load, underLoad, baseline, loader.wasInterrupted())
+	if m.ResultsFile != "" {
+		saveMixedResult(c, m, load, underLoad, baseline, loader.wasInterrupted(), start, time.Now())
+	}
+}
+
+func newMixedQueries(labels map[string]query.LabelStats, took time.Duration) *mixedQueries {
+	q := &mixedQueries{DurationMillis: took.Milliseconds(), Labels: labels}
+	q.QueryRate = float64(q.queryCount()) / took.Seconds()
+	return q
+}
+
+// printMixedSummary prints the ingest rate and the query latencies of every label,
+// compared with the ones of the baseline (if any)
+func printMixedSummary(load *mixedLoad, underLoad, baseline *mixedQueries, interrupted bool) {
+	printFn("\nMixed workload summary:\n")
+	if interrupted {
+		printFn("run interrupted, the results only cover the work done until then\n")
+	}
+	printFn("load: %d metrics in %0.3fsec (%0.2f metrics/sec)", load.Metrics, float64(load.DurationMillis)/1e3, load.MetricRate)
+	if load.Rows > 0 {
+		printFn(", %d rows (%0.2f rows/sec)", load.Rows, load.RowRate)
+	}
+	printFn("\nqueries under load: %d queries in %0.3fsec (%0.2f queries/sec)\n",
+		underLoad.queryCount(), float64(underLoad.DurationMillis)/1e3, underLoad.QueryRate)
+	if baseline != nil {
+		printFn("queries without writes: %d queries in %0.3fsec (%0.2f queries/sec)\n",
+			baseline.queryCount(), float64(baseline.DurationMillis)/1e3, baseline.QueryRate)
+	}
+
+	labels := make([]string, 0, len(underLoad.Labels))
+	for label := range underLoad.Labels {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	header := "label,count,p50 ms,p95 ms,p99 ms"
+	if baseline != nil {
+		header += ",baseline p50 ms,baseline p95 ms,baseline p99 ms,p99 change"
+	}
+	printFn("%s\n", header)
+	for _, label := range labels {
+		s := underLoad.Labels[label]
+		printFn("%s,%d,%0.2f,%0.2f,%0.2f", label, s.Count, s.P50, s.P95, s.P99)
+		if baseline != nil {
+			if b, ok := baseline.Labels[label]; ok && b.Count > 0 {
+				change := "-"
+				if b.P99 > 0 {
+					change = fmt.Sprintf("%+0.1f%%", (s.P99-b.P99)/b.P99*100)
+				}
+				printFn(",%0.2f,%0.2f,%0.2f,%s", b.P50, b.P95, b.P99, change)
+			} else {
+				printFn(",-,-,-,-")
+			}
+		}
+		printFn("\n")
+	}
+}
+
+// saveMixedResult writes the combined results of the run to the results file of m
+func saveMixedResult(c BenchmarkRunnerConfig, m MixedConfig, load *mixedLoad, underLoad, baseline *mixedQueries, interrupted bool, start, end time.Time) {
+	totals := map[string]interface{}{
+		"load":          load,
+		"queries":       underLoad,
+		"queriesRunner": m.Queries,
+	}
+	if baseline != nil {
+		totals["baseline"] = baseline
+	}
+	testResult := LoaderTestResult{
+		ResultFormatVersion: LoaderTestResultVersion,
+		RunnerConfig:        c,
+		StartTime:           start.Unix(),
+		EndTime:             end.Unix(),
+		DurationMillis:      end.Sub(start).Milliseconds(),
+		Interrupted:         interrupted,
+		Totals:              totals,
+	}
+	_, _ = fmt.Printf("Saving results json file to %s\n", m.ResultsFile)
+	file, err := json.MarshalIndent(testResult, "", " ")
+	if err != nil {
+		fatal("%v", err)
+		return
+	}
+	if err = ioutil.WriteFile(m.ResultsFile, file, 0644); err != nil {
+		fatal("%v", err)
+	}
+}
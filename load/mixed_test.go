@@ -0,0 +1,110 @@
+package load
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// sleepQueryProcessor takes a fixed time for every query
+type sleepQueryProcessor struct {
+	took time.Duration
+}
+
+func (p *sleepQueryProcessor) Init(int) {}
+
+func (p *sleepQueryProcessor) ProcessQuery(_ context.Context, q query.Query, _ bool) ([]*query.Stat, error) {
+	time.Sleep(p.took)
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), float64(p.took.Milliseconds()))
+	return []*query.Stat{stat}, nil
+}
+
+func writeQueryFile(t *testing.T, dir string, count int) string {
+	var b bytes.Buffer
+	enc := gob.NewEncoder(&b)
+	for i := 0; i < count; i++ {
+		q := query.NewTimescaleDB()
+		q.HumanLabel = []byte("q1")
+		q.SqlQuery = []byte("SELECT 1")
+		if err := enc.Encode(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fileName := filepath.Join(dir, "queries.gob")
+	if err := ioutil.WriteFile(fileName, b.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return fileName
+}
+
+func TestRunMixed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsbs-mixed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var out bytes.Buffer
+	printFn = func(s string, args ...interface{}) (n int, err error) {
+		return fmt.Fprintf(&out, s, args...)
+	}
+	creator := &testCreator{}
+	c := BenchmarkRunnerConfig{BatchSize: 1, Workers: 1, DoLoad: true, DoCreateDB: true}
+	m := MixedConfig{
+		Queries:     query.BenchmarkRunnerConfig{FileName: writeQueryFile(t, dir, 20), Workers: 1},
+		QueryPool:   &query.TimescaleDBPool,
+		Baseline:    true,
+		ResultsFile: filepath.Join(dir, "results.json"),
+		CreateQueryProcessor: func() query.Processor {
+			return &sleepQueryProcessor{took: time.Millisecond}
+		},
+	}
+	// the data source is endless, the load stops once the queries are done
+	RunMixed(c, &saturationBenchmark{creator: creator}, m)
+
+	if !creator.createCalled {
+		t.Errorf("the database should be created before the queries run")
+	}
+	if !strings.Contains(out.String(), "p99 change") {
+		t.Errorf("summary should compare with the baseline, output:\n%s", out.String())
+	}
+
+	content, err := ioutil.ReadFile(m.ResultsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result struct {
+		Interrupted bool
+		Totals      struct {
+			Load     mixedLoad    `json:"load"`
+			Queries  mixedQueries `json:"queries"`
+			Baseline mixedQueries `json:"baseline"`
+		}
+	}
+	if err = json.Unmarshal(content, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Interrupted {
+		t.Errorf("stopping the load when the queries are done is not an interruption")
+	}
+	if result.Totals.Load.Metrics == 0 {
+		t.Errorf("no data loaded while the queries ran")
+	}
+	if got := result.Totals.Queries.queryCount(); got != 20 {
+		t.Errorf("wrong number of queries under load: got %d want 20", got)
+	}
+	if got := result.Totals.Baseline.queryCount(); got != 20 {
+		t.Errorf("wrong number of baseline queries: got %d want 20", got)
+	}
+}
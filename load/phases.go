@@ -0,0 +1,118 @@
+package load
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+const (
+	phaseWarmup  = "warmup"
+	phaseMeasure = "measure"
+)
+
+// warmupPhase keeps track of the warm-up phase of a load. Everything loaded
+// before the warm-up ended is excluded from the reported rates and latencies.
+type warmupPhase struct {
+	mu        sync.Mutex
+	done      bool
+	startedAt time.Time
+	endedAt   time.Time
+	timer     *time.Timer
+	metricCnt uint64
+	rowCnt    uint64
+}
+
+// startWarmup starts the timer that ends the warm-up phase, if one is configured
+func (l *CommonBenchmarkRunner) startWarmup(start time.Time) {
+	if l.Warmup <= 0 {
+		return
+	}
+	l.warmup.mu.Lock()
+	defer l.warmup.mu.Unlock()
+	l.warmup.startedAt = start
+	l.warmup.timer = time.AfterFunc(l.Warmup, func() { l.endWarmup(time.Now()) })
+}
+
+// stopWarmup makes sure the warm-up doesn't end after the load finished
+func (l *CommonBenchmarkRunner) stopWarmup() {
+	l.warmup.mu.Lock()
+	defer l.warmup.mu.Unlock()
+	if l.warmup.timer != nil {
+		l.warmup.timer.Stop()
+	}
+}
+
+// endWarmup marks the end of the warm-up phase: the counts loaded so far are
+// remembered so they can be subtracted, and the latency histograms are cleared
+func (l *CommonBenchmarkRunner) endWarmup(now time.Time) {
+	l.warmup.mu.Lock()
+	defer l.warmup.mu.Unlock()
+	l.warmup.done = true
+	l.warmup.endedAt = now
+	l.warmup.metricCnt = atomic.LoadUint64(&l.metricCnt)
+	l.warmup.rowCnt = atomic.LoadUint64(&l.rowCnt)
+	l.latencies.reset()
+}
+
+// phase returns the phase of the load at the moment, and when the measured phase
+// started together with the counts loaded before it (only if the warm-up ended)
+func (l *CommonBenchmarkRunner) phase() (phase string, measureStart time.Time, metricCnt, rowCnt uint64) {
+	if l.Warmup <= 0 {
+		return phaseMeasure, time.Time{}, 0, 0
+	}
+	l.warmup.mu.Lock()
+	defer l.warmup.mu.Unlock()
+	if !l.warmup.done {
+		return phaseWarmup, time.Time{}, 0, 0
+	}
+	return phaseMeasure, l.warmup.endedAt, l.warmup.metricCnt, l.warmup.rowCnt
+}
+
+// warmupEnded reports whether a warm-up phase was configured and finished,
+// and how long it took
+func (l *CommonBenchmarkRunner) warmupEnded() (bool, time.Duration) {
+	if l.Warmup <= 0 {
+		return false, 0
+	}
+	l.warmup.mu.Lock()
+	defer l.warmup.mu.Unlock()
+	if !l.warmup.done {
+		return false, 0
+	}
+	return true, l.warmup.endedAt.Sub(l.warmup.startedAt)
+}
+
+// measured returns the metrics and rows loaded and the time they took, excluding
+// the warm-up phase. If the load ended before the warm-up did, everything is included.
+func (l *CommonBenchmarkRunner) measured(took time.Duration) (metricCnt, rowCnt uint64, measuredTook time.Duration) {
+	ended, warmupTook := l.warmupEnded()
+	if !ended {
+		return l.metricCnt, l.rowCnt, took
+	}
+	l.warmup.mu.Lock()
+	defer l.warmup.mu.Unlock()
+	return l.metricCnt - l.warmup.metricCnt, l.rowCnt - l.warmup.rowCnt, took - warmupTook
+}
+
+// deadlineDataSource wraps a DataSource and reports the end of the data once
+// the deadline has passed, used to stop loading after a wall-clock duration
+type deadlineDataSource struct {
+	targets.DataSource
+	deadline time.Time
+}
+
+func newDeadlineDataSource(ds targets.DataSource, deadline time.Time) *deadlineDataSource {
+	return &deadlineDataSource{DataSource: ds, deadline: deadline}
+}
+
+// NextItem returns the next item of the wrapped DataSource, or an empty item if the deadline has passed
+func (d *deadlineDataSource) NextItem() data.LoadedPoint {
+	if !time.Now().Before(d.deadline) {
+		return data.LoadedPoint{}
+	}
+	return d.DataSource.NextItem()
+}
@@ -0,0 +1,85 @@
+package load
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeadlineDataSource(t *testing.T) {
+	newSource := func() *testDataSource {
+		return &testDataSource{br: bufio.NewReader(bytes.NewReader([]byte{1, 2, 3}))}
+	}
+
+	ds := newDeadlineDataSource(newSource(), time.Now().Add(time.Hour))
+	if item := ds.NextItem(); item.Data == nil {
+		t.Errorf("item expected before the deadline")
+	}
+
+	ds = newDeadlineDataSource(newSource(), time.Now().Add(-time.Second))
+	if item := ds.NextItem(); item.Data != nil {
+		t.Errorf("no item expected after the deadline, got %v", item.Data)
+	}
+}
+
+func TestWarmup(t *testing.T) {
+	start := time.Now()
+	br := &CommonBenchmarkRunner{latencies: newBatchLatencies(1)}
+	br.Warmup = time.Second
+	br.warmup.startedAt = start
+
+	if phase, _, _, _ := br.phase(); phase != phaseWarmup {
+		t.Errorf("wrong phase before warm-up ended: got %s want %s", phase, phaseWarmup)
+	}
+	br.metricCnt, br.rowCnt = 10, 5
+	if m, r, took := br.measured(3 * time.Second); m != 10 || r != 5 || took != 3*time.Second {
+		t.Errorf("warm-up not ended, everything should be measured: got %d %d %v", m, r, took)
+	}
+
+	br.latencies.record(0, time.Millisecond)
+	br.endWarmup(start.Add(time.Second))
+	if got := br.latencies.overall().TotalCount(); got != 0 {
+		t.Errorf("latencies not reset at the end of the warm-up: got %d recorded", got)
+	}
+	phase, measureStart, m, r := br.phase()
+	if phase != phaseMeasure || !measureStart.Equal(start.Add(time.Second)) || m != 10 || r != 5 {
+		t.Errorf("wrong phase after warm-up ended: got %s %v %d %d", phase, measureStart, m, r)
+	}
+
+	br.metricCnt, br.rowCnt = 30, 15
+	if m, r, took := br.measured(3 * time.Second); m != 20 || r != 10 || took != 2*time.Second {
+		t.Errorf("wrong measured values: got %d %d %v want 20 10 2s", m, r, took)
+	}
+}
+
+func TestSummaryWithWarmup(t *testing.T) {
+	var b bytes.Buffer
+	printFn = func(s string, args ...interface{}) (n int, err error) {
+		return fmt.Fprintf(&b, s, args...)
+	}
+	start := time.Now()
+	br := &CommonBenchmarkRunner{}
+	br.Warmup = time.Second
+	br.warmup.startedAt = start
+	br.metricCnt = 10
+	br.endWarmup(start.Add(time.Second))
+	br.metricCnt = 30
+	br.summary(3 * time.Second)
+
+	want := "\nSummary:\nexcluded warm-up of 1.000sec from the results\nloaded 20 metrics in 2.000sec with 0 workers (mean rate 10.00 metrics/sec)\n"
+	if got := b.String(); got != want {
+		t.Errorf("incorrect summary\ngot %s\nwant %s", got, want)
+	}
+
+	b.Reset()
+	br = &CommonBenchmarkRunner{}
+	br.Warmup = time.Minute
+	br.metricCnt = 10
+	br.summary(time.Second)
+	if got := b.String(); !strings.Contains(got, "before the warm-up of 1m0s ended") {
+		t.Errorf("summary should mention that the warm-up did not end, got %s", got)
+	}
+}
@@ -0,0 +1,195 @@
+package load
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Valid values of --report-format
+const (
+	ReportFormatText  = "text"
+	ReportFormatCSV   = "csv"
+	ReportFormatJSONL = "jsonl"
+)
+
+// progressRecord is the state of the load at the end of a reporting period
+type progressRecord struct {
+	Timestamp         int64              `json:"timestamp"`
+	Phase             string             `json:"phase,omitempty"`
+	IntervalSeconds   float64            `json:"intervalSeconds"`
+	MetricRate        float64            `json:"metricRate"`
+	MetricTotal       uint64             `json:"metricTotal"`
+	OverallMetricRate float64            `json:"overallMetricRate"`
+	RowRate           float64            `json:"rowRate"`
+	RowTotal          uint64             `json:"rowTotal"`
+	OverallRowRate    float64            `json:"overallRowRate"`
+	Batches           uint64             `json:"batches"`
+	RetriedBatches    uint64             `json:"retriedBatches"`
+	FailedBatches     uint64             `json:"failedBatches"`
+	ScheduleLag       *float64           `json:"scheduleLagSeconds,omitempty"`
+	Latency           map[string]float64 `json:"latencyMillis,omitempty"`
+}
+
+// progressWriter writes the periodic report of a load, one record per period
+type progressWriter interface {
+	writeHeader() error
+	write(r *progressRecord) error
+}
+
+// newProgressWriter returns the progressWriter for the report format, writing to out.
+// withPhase and withLag tell which optional columns the text and csv formats have,
+// withErrors whether the text format has the retried and failed batches.
+func newProgressWriter(format string, out io.Writer, withPhase, withLag, withErrors bool) (progressWriter, error) {
+	switch format {
+	case "", ReportFormatText:
+		printf := printFn
+		if out != nil {
+			printf = func(format string, args ...interface{}) (int, error) {
+				return fmt.Fprintf(out, format, args...)
+			}
+		}
+		return &textProgressWriter{printf: printf, withPhase: withPhase, withLag: withLag, withErrors: withErrors}, nil
+	case ReportFormatCSV:
+		if out == nil {
+			out = os.Stdout
+		}
+		return &csvProgressWriter{w: csv.NewWriter(out), withPhase: withPhase, withLag: withLag}, nil
+	case ReportFormatJSONL:
+		if out == nil {
+			out = os.Stdout
+		}
+		return &jsonlProgressWriter{enc: json.NewEncoder(out)}, nil
+	default:
+		return nil, fmt.Errorf("invalid report format '%s', valid formats: %s, %s, %s", format, ReportFormatText, ReportFormatCSV, ReportFormatJSONL)
+	}
+}
+
+// textProgressWriter writes the human readable report, mixed with the other output of the load
+type textProgressWriter struct {
+	printf     func(string, ...interface{}) (int, error)
+	withPhase  bool
+	withLag    bool
+	withErrors bool
+}
+
+func (w *textProgressWriter) writeHeader() error {
+	header := "time,per. metric/s,metric total,overall metric/s,per. row/s,row total,overall row/s"
+	if w.withPhase {
+		header = "time,phase" + strings.TrimPrefix(header, "time")
+	}
+	if w.withErrors {
+		header += ",retried batches,failed batches"
+	}
+	if w.withLag {
+		header += ",schedule lag"
+	}
+	_, err := w.printf(header + "\n")
+	return err
+}
+
+func (w *textProgressWriter) write(r *progressRecord) error {
+	timeColumn := fmt.Sprintf("%d", r.Timestamp)
+	if w.withPhase {
+		timeColumn += "," + r.Phase
+	}
+	extraColumns := ""
+	if w.withErrors {
+		extraColumns = fmt.Sprintf(",%d,%d", r.RetriedBatches, r.FailedBatches)
+	}
+	if w.withLag && r.ScheduleLag != nil {
+		extraColumns += fmt.Sprintf(",%0.3f", *r.ScheduleLag)
+	}
+	var err error
+	if r.RowTotal > 0 {
+		_, err = w.printf("%s,%0.2f,%E,%0.2f,%0.2f,%E,%0.2f%s\n", timeColumn, r.MetricRate, float64(r.MetricTotal), r.OverallMetricRate, r.RowRate, float64(r.RowTotal), r.OverallRowRate, extraColumns)
+	} else {
+		_, err = w.printf("%s,%0.2f,%E,%0.2f,-,-,-%s\n", timeColumn, r.MetricRate, float64(r.MetricTotal), r.OverallMetricRate, extraColumns)
+	}
+	return err
+}
+
+// csvProgressWriter writes a plain CSV with a single header line and numeric values only,
+// cells that don't apply (yet) are left empty
+type csvProgressWriter struct {
+	w         *csv.Writer
+	withPhase bool
+	withLag   bool
+}
+
+func (w *csvProgressWriter) writeHeader() error {
+	header := []string{"timestamp"}
+	if w.withPhase {
+		header = append(header, "phase")
+	}
+	header = append(header, "interval_seconds", "metric_rate", "metric_total", "overall_metric_rate",
+		"row_rate", "row_total", "overall_row_rate", "batches", "retried_batches", "failed_batches")
+	if w.withLag {
+		header = append(header, "schedule_lag_seconds")
+	}
+	for _, q := range reportedQuantiles {
+		header = append(header, "latency_"+q.name+"_ms")
+	}
+	return w.writeRecord(header)
+}
+
+func (w *csvProgressWriter) write(r *progressRecord) error {
+	formatFloat := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	formatUint := func(u uint64) string {
+		return strconv.FormatUint(u, 10)
+	}
+	record := []string{strconv.FormatInt(r.Timestamp, 10)}
+	if w.withPhase {
+		record = append(record, r.Phase)
+	}
+	record = append(record, formatFloat(r.IntervalSeconds), formatFloat(r.MetricRate), formatUint(r.MetricTotal), formatFloat(r.OverallMetricRate))
+	if r.RowTotal > 0 {
+		record = append(record, formatFloat(r.RowRate), formatUint(r.RowTotal), formatFloat(r.OverallRowRate))
+	} else {
+		record = append(record, "", "", "")
+	}
+	record = append(record, formatUint(r.Batches), formatUint(r.RetriedBatches), formatUint(r.FailedBatches))
+	if w.withLag {
+		lag := ""
+		if r.ScheduleLag != nil {
+			lag = formatFloat(*r.ScheduleLag)
+		}
+		record = append(record, lag)
+	}
+	for _, q := range reportedQuantiles {
+		latency := ""
+		if v, ok := r.Latency[q.name]; ok {
+			latency = formatFloat(v)
+		}
+		record = append(record, latency)
+	}
+	return w.writeRecord(record)
+}
+
+// writeRecord writes and flushes a single line, so the file can be followed while the load runs
+func (w *csvProgressWriter) writeRecord(record []string) error {
+	if err := w.w.Write(record); err != nil {
+		return err
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// jsonlProgressWriter writes every record as a JSON object on its own line
+type jsonlProgressWriter struct {
+	enc *json.Encoder
+}
+
+func (w *jsonlProgressWriter) writeHeader() error {
+	return nil
+}
+
+func (w *jsonlProgressWriter) write(r *progressRecord) error {
+	return w.enc.Encode(r)
+}
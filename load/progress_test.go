@@ -0,0 +1,78 @@
+package load
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNewProgressWriter(t *testing.T) {
+	for _, format := range []string{"", ReportFormatText, ReportFormatCSV, ReportFormatJSONL} {
+		if _, err := newProgressWriter(format, &bytes.Buffer{}, false, false, false); err != nil {
+			t.Errorf("unexpected error for format '%s': %v", format, err)
+		}
+	}
+	if _, err := newProgressWriter("xml", &bytes.Buffer{}, false, false, false); err == nil {
+		t.Errorf("expected error for an invalid format")
+	}
+}
+
+func TestCSVProgressWriter(t *testing.T) {
+	var b bytes.Buffer
+	w, _ := newProgressWriter(ReportFormatCSV, &b, true, false, false)
+	if err := w.writeHeader(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r := &progressRecord{Timestamp: 100, Phase: phaseWarmup, IntervalSeconds: 10, MetricRate: 1.5, MetricTotal: 15, OverallMetricRate: 1.5, Batches: 3}
+	if err := w.write(r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r = &progressRecord{Timestamp: 110, Phase: phaseMeasure, IntervalSeconds: 10, MetricTotal: 15, RowTotal: 5, RowRate: 0.5, OverallRowRate: 0.25, Batches: 4,
+		Latency: map[string]float64{"p50": 1, "p90": 2, "p99": 3, "p999": 4}}
+	if err := w.write(r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{
+		"timestamp,phase,interval_seconds,metric_rate,metric_total,overall_metric_rate,row_rate,row_total,overall_row_rate,batches,retried_batches,failed_batches,latency_p50_ms,latency_p90_ms,latency_p99_ms,latency_p999_ms",
+		"100,warmup,10,1.5,15,1.5,,,,3,0,0,,,,",
+		"110,measure,10,0,15,0,0.5,5,0.25,4,0,0,1,2,3,4",
+	}
+	if got := strings.Split(strings.TrimSpace(b.String()), "\n"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("incorrect csv\ngot\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestJSONLProgressWriter(t *testing.T) {
+	var b bytes.Buffer
+	w, _ := newProgressWriter(ReportFormatJSONL, &b, false, true, false)
+	lag := 0.5
+	records := []*progressRecord{
+		{Timestamp: 100, MetricTotal: 10, ScheduleLag: &lag},
+		{Timestamp: 110, MetricTotal: 20, Latency: map[string]float64{"p50": 1}},
+	}
+	for _, r := range records {
+		if err := w.write(r); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != len(records) {
+		t.Fatalf("wrong number of lines: got %d want %d", len(lines), len(records))
+	}
+	for i, line := range lines {
+		var got map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("line %d is not valid json: %v", i, err)
+		}
+		if got["metricTotal"] != float64(records[i].MetricTotal) {
+			t.Errorf("line %d: wrong metricTotal %v", i, got["metricTotal"])
+		}
+		if _, ok := got["scheduleLagSeconds"]; ok != (records[i].ScheduleLag != nil) {
+			t.Errorf("line %d: scheduleLagSeconds should only be present with a target rate", i)
+		}
+		if _, ok := got["latencyMillis"]; ok != (records[i].Latency != nil) {
+			t.Errorf("line %d: latencyMillis should only be present once available", i)
+		}
+	}
+}
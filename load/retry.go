@@ -0,0 +1,101 @@
+package load
+
+import (
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+const (
+	defaultRetryBackoff    = 100 * time.Millisecond
+	defaultRetryMaxBackoff = 10 * time.Second
+)
+
+// retryPolicy decides how long a worker waits before inserting a failed batch again.
+// The wait doubles after every attempt, starting with backoff and capped at maxBackoff.
+type retryPolicy struct {
+	maxRetries uint
+	backoff    time.Duration
+	maxBackoff time.Duration
+}
+
+func newRetryPolicy(c *BenchmarkRunnerConfig) *retryPolicy {
+	p := &retryPolicy{
+		maxRetries: c.MaxRetries,
+		backoff:    c.RetryBackoff,
+		maxBackoff: c.RetryMaxBackoff,
+	}
+	if p.backoff <= 0 {
+		p.backoff = defaultRetryBackoff
+	}
+	if p.maxBackoff < p.backoff {
+		p.maxBackoff = p.backoff
+	}
+	return p
+}
+
+// shouldRetry returns true if a batch that failed on the given (0-based) attempt
+// with err should be processed again
+func (p *retryPolicy) shouldRetry(attempt uint, err error) bool {
+	if p == nil {
+		return false
+	}
+	return attempt < p.maxRetries && targets.IsRetryable(err)
+}
+
+// wait returns the time to sleep after the given (0-based) failed attempt
+func (p *retryPolicy) wait(attempt uint) time.Duration {
+	d := p.backoff
+	for i := uint(0); i < attempt; i++ {
+		d *= 2
+		if d >= p.maxBackoff {
+			return p.maxBackoff
+		}
+	}
+	return d
+}
+
+// reportsErrors tells whether retries or an error budget are configured. Without them
+// the processors handle failed inserts the way they always did.
+func (l *CommonBenchmarkRunner) reportsErrors() bool {
+	return l.MaxRetries > 0 || l.ErrorBudget > 0
+}
+
+// processBatch hands the batch over to the processor. If the processor reports insert
+// errors (targets.ProcessorWithError) and retries or an error budget are configured,
+// retryable failures are retried according to the retry policy, and batches that still
+// fail are counted against the error budget. The load is aborted once more batches
+// failed than the error budget allows, failed tells whether the batch was skipped
+// under the error budget.
+func (l *CommonBenchmarkRunner) processBatch(proc targets.Processor, b targets.Batch) (metricCnt, rowCnt uint64, failed bool) {
+	errProc, ok := proc.(targets.ProcessorWithError)
+	if !ok || !l.reportsErrors() {
+		metricCnt, rowCnt = proc.ProcessBatch(b, l.DoLoad)
+		return metricCnt, rowCnt, false
+	}
+
+	for attempt := uint(0); ; attempt++ {
+		m, r, err := errProc.ProcessBatchWithError(b, l.DoLoad)
+		metricCnt += m
+		rowCnt += r
+		if err == nil {
+			return metricCnt, rowCnt, false
+		}
+
+		if l.retries.shouldRetry(attempt, err) {
+			atomic.AddUint64(&l.retriedBatchCnt, 1)
+			time.Sleep(l.retries.wait(attempt))
+			continue
+		}
+
+		failedCnt := atomic.AddUint64(&l.failedBatchCnt, 1)
+		if failedCnt > l.ErrorBudget {
+			fatal("batch failed after %d attempt(s), error budget of %d failed batches exhausted: %v", attempt+1, l.ErrorBudget, err)
+			return metricCnt, rowCnt, true
+		}
+		log.Printf("batch failed after %d attempt(s), skipping it (%d/%d of error budget used): %v", attempt+1, failedCnt, l.ErrorBudget, err)
+		return metricCnt, rowCnt, true
+	}
+}
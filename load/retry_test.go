@@ -0,0 +1,162 @@
+package load
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+type testErrProcessor struct {
+	testProcessor
+	failures  int
+	retryable bool
+	calls     int
+}
+
+func (p *testErrProcessor) ProcessBatchWithError(targets.Batch, bool) (uint64, uint64, error) {
+	p.calls++
+	if p.calls <= p.failures {
+		err := fmt.Errorf("insert failed")
+		if p.retryable {
+			err = targets.NewRetryableError(err)
+		}
+		return 0, 0, err
+	}
+	return 1, 1, nil
+}
+
+func TestRetryPolicyWait(t *testing.T) {
+	p := newRetryPolicy(&BenchmarkRunnerConfig{
+		RetryBackoff:    time.Millisecond,
+		RetryMaxBackoff: 5 * time.Millisecond,
+	})
+	cases := []struct {
+		attempt uint
+		want    time.Duration
+	}{
+		{attempt: 0, want: time.Millisecond},
+		{attempt: 1, want: 2 * time.Millisecond},
+		{attempt: 2, want: 4 * time.Millisecond},
+		{attempt: 3, want: 5 * time.Millisecond},
+		{attempt: 10, want: 5 * time.Millisecond},
+	}
+	for _, c := range cases {
+		if got := p.wait(c.attempt); got != c.want {
+			t.Errorf("wrong wait for attempt %d: got %v want %v", c.attempt, got, c.want)
+		}
+	}
+}
+
+func TestRetryPolicyDefaults(t *testing.T) {
+	p := newRetryPolicy(&BenchmarkRunnerConfig{})
+	if p.backoff != defaultRetryBackoff {
+		t.Errorf("wrong default backoff: got %v want %v", p.backoff, defaultRetryBackoff)
+	}
+	if p.maxBackoff != defaultRetryBackoff {
+		t.Errorf("max backoff not raised to backoff: got %v want %v", p.maxBackoff, defaultRetryBackoff)
+	}
+}
+
+func TestProcessBatch(t *testing.T) {
+	cases := []struct {
+		desc        string
+		maxRetries  uint
+		errorBudget uint64
+		failures    int
+		retryable   bool
+
+		wantMetrics uint64
+		wantCalls   int
+		wantRetried uint64
+		wantFailed  uint64
+		wantFatal   bool
+	}{
+		{
+			desc:        "no retries or error budget, processed the old way",
+			failures:    1,
+			retryable:   true,
+			wantMetrics: 1,
+			wantCalls:   0,
+		},
+		{
+			desc:        "no failures",
+			maxRetries:  1,
+			wantMetrics: 1,
+			wantCalls:   1,
+		},
+		{
+			desc:        "retryable failure, retried until success",
+			maxRetries:  3,
+			failures:    2,
+			retryable:   true,
+			wantMetrics: 1,
+			wantCalls:   3,
+			wantRetried: 2,
+		},
+		{
+			desc:        "retryable failure, retries exhausted, within budget",
+			maxRetries:  1,
+			errorBudget: 1,
+			failures:    5,
+			retryable:   true,
+			wantCalls:   2,
+			wantRetried: 1,
+			wantFailed:  1,
+		},
+		{
+			desc:        "non-retryable failure is not retried",
+			maxRetries:  3,
+			errorBudget: 1,
+			failures:    1,
+			wantCalls:   1,
+			wantFailed:  1,
+		},
+		{
+			desc:        "error budget exhausted",
+			maxRetries:  1,
+			failures:    2,
+			retryable:   true,
+			wantCalls:   2,
+			wantRetried: 1,
+			wantFailed:  1,
+			wantFatal:   true,
+		},
+	}
+	oldFatal := fatal
+	defer func() { fatal = oldFatal }()
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			fatalCalled := false
+			fatal = func(string, ...interface{}) { fatalCalled = true }
+			conf := BenchmarkRunnerConfig{
+				DoLoad:       true,
+				MaxRetries:   c.maxRetries,
+				RetryBackoff: time.Microsecond,
+				ErrorBudget:  c.errorBudget,
+			}
+			br := &CommonBenchmarkRunner{BenchmarkRunnerConfig: conf, retries: newRetryPolicy(&conf)}
+			p := &testErrProcessor{failures: c.failures, retryable: c.retryable}
+			metrics, _, failed := br.processBatch(p, &testBatch{})
+			if metrics != c.wantMetrics {
+				t.Errorf("wrong metric count: got %d want %d", metrics, c.wantMetrics)
+			}
+			if p.calls != c.wantCalls {
+				t.Errorf("wrong number of calls: got %d want %d", p.calls, c.wantCalls)
+			}
+			if br.retriedBatchCnt != c.wantRetried {
+				t.Errorf("wrong retried batch count: got %d want %d", br.retriedBatchCnt, c.wantRetried)
+			}
+			if failed != (c.wantFailed > 0) {
+				t.Errorf("wrong failed result: got %v", failed)
+			}
+			if br.failedBatchCnt != c.wantFailed {
+				t.Errorf("wrong failed batch count: got %d want %d", br.failedBatchCnt, c.wantFailed)
+			}
+			if fatalCalled != c.wantFatal {
+				t.Errorf("fatal called: got %v want %v", fatalCalled, c.wantFatal)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package load
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+const (
+	defaultStepDuration  = time.Minute
+	defaultRateTolerance = 0.95
+)
+
+// SaturationConfig configures a saturation search: the load is run in steps of
+// increasing workers and/or target rate, each held for StepDuration, until the
+// database can't keep up anymore.
+type SaturationConfig struct {
+	// Workers and TargetRates are the values of the steps, the shorter list
+	// keeps its last value for the remaining steps
+	Workers     []uint    `yaml:"workers" mapstructure:"workers" json:"workers"`
+	TargetRates []float64 `yaml:"target-rates" mapstructure:"target-rates" json:"target-rates"`
+	// StepDuration is how long each step is held, the warm-up of the runner included
+	StepDuration time.Duration `yaml:"step-duration" mapstructure:"step-duration" json:"step-duration"`
+	// MinThroughputGain is the relative throughput increase a step needs over the previous one
+	MinThroughputGain float64 `yaml:"min-throughput-gain" mapstructure:"min-throughput-gain" json:"min-throughput-gain"`
+	// RateTolerance is the fraction of the target rate a step needs to achieve
+	RateTolerance float64 `yaml:"rate-tolerance" mapstructure:"rate-tolerance" json:"rate-tolerance"`
+	// MaxP99Latency is the highest p99 batch latency a step can have, 0 for no limit
+	MaxP99Latency time.Duration `yaml:"max-p99-latency" mapstructure:"max-p99-latency" json:"max-p99-latency"`
+	// ContinueAfterSaturation runs all the steps instead of stopping at the first saturated one
+	ContinueAfterSaturation bool `yaml:"continue-after-saturation" mapstructure:"continue-after-saturation" json:"continue-after-saturation"`
+}
+
+// saturationStep is the result of a single step of a saturation search
+type saturationStep struct {
+	Step           int                    `json:"step"`
+	Workers        uint                   `json:"workers"`
+	TargetRate     float64                `json:"targetRate,omitempty"`
+	DurationMillis int64                  `json:"durationMillis"`
+	Metrics        uint64                 `json:"metrics"`
+	Rows           uint64                 `json:"rows"`
+	MetricRate     float64                `json:"metricRate"`
+	RowRate        float64                `json:"rowRate"`
+	BatchLatency   map[string]interface{} `json:"batchLatency"`
+	Saturated      bool                   `json:"saturated"`
+	Incomplete     bool                   `json:"incomplete,omitempty"`
+	Reason         string                 `json:"reason,omitempty"`
+
+	p99 time.Duration
+}
+
+// throughput returns the rate of the step in the unit used to compare the steps
+func (s *saturationStep) throughput(unit string) float64 {
+	if unit == TargetRateUnitRows && s.Rows > 0 {
+		return s.RowRate
+	}
+	return s.MetricRate
+}
+
+// steps returns the workers and target rate of every step
+func (s *SaturationConfig) steps(c *BenchmarkRunnerConfig) []saturationStep {
+	n := len(s.Workers)
+	if len(s.TargetRates) > n {
+		n = len(s.TargetRates)
+	}
+	steps := make([]saturationStep, n)
+	for i := range steps {
+		steps[i].Step = i + 1
+		steps[i].Workers = c.Workers
+		if len(s.Workers) > 0 {
+			steps[i].Workers = s.Workers[minInt(i, len(s.Workers)-1)]
+		}
+		steps[i].TargetRate = c.TargetRate
+		if len(s.TargetRates) > 0 {
+			steps[i].TargetRate = s.TargetRates[minInt(i, len(s.TargetRates)-1)]
+		}
+	}
+	return steps
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// saturated checks the result of step against the thresholds, given the best
+// step before it (nil for the first step), and records the reason if it's saturated
+func (s *SaturationConfig) saturated(step, best *saturationStep, unit string) bool {
+	tolerance := s.RateTolerance
+	if tolerance <= 0 {
+		tolerance = defaultRateTolerance
+	}
+	switch {
+	case step.TargetRate > 0 && step.throughput(unit) < tolerance*step.TargetRate:
+		step.Reason = fmt.Sprintf("achieved %0.2f %s/sec, less than %0.0f%% of the target rate", step.throughput(unit), unit, tolerance*100)
+	case s.MaxP99Latency > 0 && step.p99 > s.MaxP99Latency:
+		step.Reason = fmt.Sprintf("p99 batch latency %v above %v", step.p99, s.MaxP99Latency)
+	case best != nil && step.throughput(unit) < best.throughput(unit)*(1+s.MinThroughputGain):
+		step.Reason = fmt.Sprintf("throughput gained less than %0.0f%% over step %d", s.MinThroughputGain*100, best.Step)
+	default:
+		return false
+	}
+	step.Saturated = true
+	return true
+}
+
+// stepBenchmark runs the steps of a saturation search on the same Benchmark,
+// so the data source continues where the previous step stopped. The database
+// is only set up by the first step.
+type stepBenchmark struct {
+	targets.Benchmark
+	setupDB bool
+}
+
+// GetDBCreator returns the DBCreator of the Benchmark for the first step only
+func (b *stepBenchmark) GetDBCreator() targets.DBCreator {
+	if !b.setupDB {
+		return nil
+	}
+	return b.Benchmark.GetDBCreator()
+}
+
+// commonRunner returns the CommonBenchmarkRunner behind a BenchmarkRunner
+func commonRunner(r BenchmarkRunner) *CommonBenchmarkRunner {
+	switch r := r.(type) {
+	case *CommonBenchmarkRunner:
+		return r
+	case *noFlowBenchmarkRunner:
+		return r.CommonBenchmarkRunner
+	}
+	panic(fmt.Sprintf("unknown benchmark runner %T", r))
+}
+
+// RunSaturationSearch loads the data of b in the steps described by s, each step with
+// the configuration c except for the workers, target rate and duration of the step.
+// It prints a table of all the steps with the knee point, the last step before the
+// database saturated, and saves them to the results file of c (if set).
+func RunSaturationSearch(c BenchmarkRunnerConfig, s SaturationConfig, b targets.Benchmark) {
+	if s.StepDuration <= 0 {
+		s.StepDuration = defaultStepDuration
+	}
+	if c.CheckpointFile != "" || c.Resume {
+		panic("saturation search can't be checkpointed or resumed")
+	}
+	if len(c.Asserts) > 0 {
+		panic("assert checks the stats of a single load, it can't be used with a saturation search")
+	}
+	unit := TargetRateUnitMetrics
+	if len(s.TargetRates) > 0 || c.TargetRate > 0 {
+		unit = (&CommonBenchmarkRunner{BenchmarkRunnerConfig: c}).targetRateUnit()
+	}
+	start := time.Now()
+	steps := s.steps(&c)
+	if len(steps) == 0 {
+		panic("saturation search needs the workers and/or target rates of the steps")
+	}
+
+	var best, knee *saturationStep
+	done := 0
+	for i := range steps {
+		step := &steps[i]
+		printFn("\nSaturation search step %d/%d: %d workers", step.Step, len(steps), step.Workers)
+		if step.TargetRate > 0 {
+			printFn(", target rate %0.2f %s/sec", step.TargetRate, unit)
+		}
+		printFn(", for %v\n", s.StepDuration)
+
+		stepConfig := c
+		stepConfig.Workers = step.Workers
+		stepConfig.TargetRate = step.TargetRate
+		stepConfig.Duration = s.StepDuration
+		stepConfig.Limit = 0
+		stepConfig.ResultsFile = ""
+		stepConfig.HDRLatencies = ""
+		stepConfig.ReportFile = ""
+		// the metrics server can't be restarted on the same address for every step
+		stepConfig.MetricsListenAddr = ""
+		br := GetBenchmarkRunner(stepConfig)
+		br.RunBenchmark(&stepBenchmark{Benchmark: b, setupDB: i == 0})
+		runner := commonRunner(br)
+		done++
+
+		metricCnt, rowCnt, took := runner.measured(runner.took)
+		step.DurationMillis = took.Milliseconds()
+		step.Metrics, step.Rows = metricCnt, rowCnt
+		step.MetricRate = float64(metricCnt) / took.Seconds()
+		step.RowRate = float64(rowCnt) / took.Seconds()
+		latencies := runner.latencies.overall()
+		step.BatchLatency = latencySummary(latencies)
+		step.p99 = time.Duration(latencies.ValueAtQuantile(99.0)) * time.Microsecond
+
+		if runner.wasInterrupted() {
+			step.Incomplete, step.Reason = true, "interrupted"
+			break
+		}
+		if runner.took < s.StepDuration {
+			step.Incomplete, step.Reason = true, "data source exhausted before the end of the step"
+			break
+		}
+		if s.saturated(step, best, unit) {
+			if !s.ContinueAfterSaturation {
+				break
+			}
+			continue
+		}
+		best = step
+	}
+	// the knee is the last complete step before the first saturated one
+	for i := 0; i < done && !steps[i].Saturated && !steps[i].Incomplete; i++ {
+		knee = &steps[i]
+	}
+	steps = steps[:done]
+
+	printSaturationSteps(steps, knee, unit)
+	if c.ResultsFile != "" {
+		saveSaturationResult(c, s, steps, knee, start, time.Now())
+	}
+}
+
+// printSaturationSteps prints the table of the steps and the knee point
+func printSaturationSteps(steps []saturationStep, knee *saturationStep, unit string) {
+	printFn("\nSaturation search summary:\nstep,workers,target rate,%s/sec,p99 latency ms,saturated,reason\n", unit)
+	for i := range steps {
+		step := &steps[i]
+		targetRate := "-"
+		if step.TargetRate > 0 {
+			targetRate = fmt.Sprintf("%0.2f", step.TargetRate)
+		}
+		printFn("%d,%d,%s,%0.2f,%0.2f,%t,%s\n", step.Step, step.Workers, targetRate, step.throughput(unit),
+			step.BatchLatency["p99"], step.Saturated, step.Reason)
+	}
+	if knee == nil {
+		printFn("no knee point: the first step already saturated or did not complete\n")
+		return
+	}
+	printFn("knee point: step %d with %d workers at %0.2f %s/sec\n", knee.Step, knee.Workers, knee.throughput(unit), unit)
+}
+
+// saveSaturationResult writes the steps of the search to the results file
+func saveSaturationResult(c BenchmarkRunnerConfig, s SaturationConfig, steps []saturationStep, knee *saturationStep, start, end time.Time) {
+	totals := map[string]interface{}{
+		"saturation": s,
+		"steps":      steps,
+	}
+	if knee != nil {
+		totals["knee"] = knee
+	}
+	testResult := LoaderTestResult{
+		ResultFormatVersion: LoaderTestResultVersion,
+		RunnerConfig:        c,
+		StartTime:           start.Unix(),
+		EndTime:             end.Unix(),
+		DurationMillis:      end.Sub(start).Milliseconds(),
+		Totals:              totals,
+	}
+	_, _ = fmt.Printf("Saving results json file to %s\n", c.ResultsFile)
+	file, err := json.MarshalIndent(testResult, "", " ")
+	if err != nil {
+		fatal("%v", err)
+		return
+	}
+	if err = ioutil.WriteFile(c.ResultsFile, file, 0644); err != nil {
+		fatal("%v", err)
+	}
+}
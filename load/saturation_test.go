@@ -0,0 +1,126 @@
+package load
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// endlessDataSource never runs out of items
+type endlessDataSource struct{}
+
+func (d *endlessDataSource) NextItem() data.LoadedPoint {
+	return data.LoadedPoint{Data: byte(1)}
+}
+
+func (d *endlessDataSource) Headers() *common.GeneratedDataHeaders {
+	return nil
+}
+
+// sleepProcessor takes a fixed time for every batch, so throughput grows with the workers
+type sleepProcessor struct {
+	took time.Duration
+}
+
+func (p *sleepProcessor) Init(int, bool, bool) {}
+
+func (p *sleepProcessor) ProcessBatch(b targets.Batch, _ bool) (uint64, uint64) {
+	time.Sleep(p.took)
+	return uint64(b.Len()), 0
+}
+
+type saturationBenchmark struct {
+	creator *testCreator
+}
+
+func (b *saturationBenchmark) GetDataSource() targets.DataSource     { return &endlessDataSource{} }
+func (b *saturationBenchmark) GetBatchFactory() targets.BatchFactory { return &testFactory{} }
+func (b *saturationBenchmark) GetPointIndexer(uint) targets.PointIndexer {
+	return &targets.ConstantIndexer{}
+}
+func (b *saturationBenchmark) GetProcessor() targets.Processor {
+	return &sleepProcessor{took: 5 * time.Millisecond}
+}
+func (b *saturationBenchmark) GetDBCreator() targets.DBCreator {
+	return b.creator
+}
+
+func TestSaturationConfigSteps(t *testing.T) {
+	s := &SaturationConfig{Workers: []uint{1, 2, 4}, TargetRates: []float64{100, 200}}
+	steps := s.steps(&BenchmarkRunnerConfig{Workers: 8})
+	want := []struct {
+		workers uint
+		rate    float64
+	}{{1, 100}, {2, 200}, {4, 200}}
+	if len(steps) != len(want) {
+		t.Fatalf("wrong number of steps: got %d want %d", len(steps), len(want))
+	}
+	for i, w := range want {
+		if steps[i].Workers != w.workers || steps[i].TargetRate != w.rate || steps[i].Step != i+1 {
+			t.Errorf("step %d: got %d workers at %f want %d at %f", i, steps[i].Workers, steps[i].TargetRate, w.workers, w.rate)
+		}
+	}
+
+	s = &SaturationConfig{TargetRates: []float64{100}}
+	if steps = s.steps(&BenchmarkRunnerConfig{Workers: 8}); steps[0].Workers != 8 {
+		t.Errorf("configured workers should be used without step workers, got %d", steps[0].Workers)
+	}
+}
+
+func TestSaturationConfigSaturated(t *testing.T) {
+	s := &SaturationConfig{MinThroughputGain: 0.1, RateTolerance: 0.9, MaxP99Latency: time.Second}
+	prev := &saturationStep{Step: 1, MetricRate: 100}
+	cases := []struct {
+		desc string
+		step saturationStep
+		want bool
+	}{
+		{"first step", saturationStep{MetricRate: 10}, false},
+		{"enough gain", saturationStep{MetricRate: 111}, false},
+		{"not enough gain", saturationStep{MetricRate: 105}, true},
+		{"target rate reached", saturationStep{MetricRate: 200, TargetRate: 210}, false},
+		{"target rate missed", saturationStep{MetricRate: 150, TargetRate: 200}, true},
+		{"latency too high", saturationStep{MetricRate: 200, p99: 2 * time.Second}, true},
+	}
+	for _, c := range cases {
+		best := prev
+		if c.desc == "first step" {
+			best = nil
+		}
+		step := c.step
+		if got := s.saturated(&step, best, TargetRateUnitMetrics); got != c.want || step.Saturated != c.want {
+			t.Errorf("%s: got saturated %v want %v", c.desc, got, c.want)
+		}
+		if c.want && step.Reason == "" {
+			t.Errorf("%s: saturated step without a reason", c.desc)
+		}
+	}
+}
+
+func TestRunSaturationSearch(t *testing.T) {
+	var b bytes.Buffer
+	printFn = func(s string, args ...interface{}) (n int, err error) {
+		return fmt.Fprintf(&b, s, args...)
+	}
+	creator := &testCreator{}
+	c := BenchmarkRunnerConfig{BatchSize: 1, Workers: 1, DoLoad: true, DoCreateDB: true}
+	s := SaturationConfig{Workers: []uint{1, 4, 4, 8}, StepDuration: 200 * time.Millisecond, MinThroughputGain: 0.5}
+	RunSaturationSearch(c, s, &saturationBenchmark{creator: creator})
+
+	if !creator.createCalled {
+		t.Errorf("the database should be created by the first step")
+	}
+	out := b.String()
+	if !strings.Contains(out, "knee point: step 2 with 4 workers") {
+		t.Errorf("wrong knee point, output:\n%s", out)
+	}
+	if strings.Contains(out, "step 4/4") {
+		t.Errorf("search should stop at the first saturated step, output:\n%s", out)
+	}
+}
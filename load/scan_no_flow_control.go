@@ -10,9 +10,10 @@ import (
 // readDs does no flow control, if the capacity of a channel is reached, scanning stops for all
 // workers. (should only happen if channel-capacity is low and one worker is unreasonable slower than the rest)
 // in that case just set hash-workers to false and use 1 channel for all workers.
+// Every appended item is reported to the checkpointTracker tracker, if not nil.
 func scanWithoutFlowControl(
 	ds targets.DataSource, indexer targets.PointIndexer, factory targets.BatchFactory, channels []chan targets.Batch,
-	batchSize uint, limit uint64,
+	batchSize uint, limit uint64, tracker *checkpointTracker,
 ) uint64 {
 	if batchSize == 0 {
 		panic("batch size can't be 0")
@@ -37,6 +38,7 @@ func scanWithoutFlowControl(
 
 		idx := indexer.GetIndex(item)
 		batches[idx].Append(item)
+		tracker.appended(batches[idx])
 
 		if batches[idx].Len() >= batchSize {
 			channels[idx] <- batches[idx]
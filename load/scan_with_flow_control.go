@@ -42,9 +42,11 @@ func sendOrQueueBatch(ch *duplexChannel, count *int, batch targets.Batch, unsent
 // which are then dispatched to workers (duplexChannel chosen by PointIndexer).
 // Scan does flow control to make sure workers are not left idle for too long
 // and also that the scanning process does not starve them of CPU.
+// Every appended item is reported to the checkpointTracker tracker, if not nil.
 func scanWithFlowControl(
 	channels []*duplexChannel, batchSize uint, limit uint64,
 	ds targets.DataSource, factory targets.BatchFactory, indexer targets.PointIndexer,
+	tracker *checkpointTracker,
 ) uint64 {
 	var itemsRead uint64
 	numChannels := len(channels)
@@ -124,6 +126,7 @@ func scanWithFlowControl(
 		// Append new item to batch
 		idx := indexer.GetIndex(item)
 		fillingBatches[idx].Append(item)
+		tracker.appended(fillingBatches[idx])
 
 		if fillingBatches[idx].Len() >= batchSize {
 			// Batch is full (contains at least batchSize items) - ready to be sent to worker,
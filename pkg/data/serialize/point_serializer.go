@@ -9,3 +9,14 @@ import (
 type PointSerializer interface {
 	Serialize(p *data.Point, w io.Writer) error
 }
+
+// ShardedPointSerializer is a PointSerializer that keeps state between the
+// points, but can be split between several serializers writing disjoint
+// shards of the points, whose outputs are then interleaved point by point.
+type ShardedPointSerializer interface {
+	PointSerializer
+	// Shard sets the serializer up to write the given shard out of shards
+	// and returns what has to be written once before the points of all the
+	// shards.
+	Shard(shard, shards int) []byte
+}
@@ -90,4 +90,46 @@ func TestDataGeneratorConfigValidate(t *testing.T) {
 			t.Errorf("incorrect error for group id > num groups: got\n%s\nwant\n%s", got, want)
 		}
 	}
+	c.InterleavedGroupID = 0
+
+	// Test kubernetes validation
+	c.Use = common.UseCaseKubernetes
+	c.ChurnRate = -1
+	if err = c.Validate(); err == nil {
+		t.Errorf("unexpected lack of error for a negative churn rate")
+	}
+	c.ChurnRate = 0
+	c.PodLifetime = -time.Hour
+	if err = c.Validate(); err == nil {
+		t.Errorf("unexpected lack of error for a negative pod lifetime")
+	}
+	c.PodLifetime = 0
+
+	// Test disorder validation
+	c.LateChance = 0.1
+	if err = c.Validate(); err == nil {
+		t.Errorf("unexpected lack of error for late points without a max lateness")
+	}
+	c.MaxLateness = time.Minute
+	if err = c.Validate(); err != nil {
+		t.Errorf("unexpected error for late points: %v", err)
+	}
+	c.GapChance = 1
+	if err = c.Validate(); err == nil {
+		t.Errorf("unexpected lack of error for disorder chances summing above 1")
+	}
+	c.GapChance = -0.1
+	if err = c.Validate(); err == nil {
+		t.Errorf("unexpected lack of error for a negative gap chance")
+	}
+	c.GapChance = 0
+	c.Use = common.UseCaseIoT
+	if err = c.Validate(); err == nil {
+		t.Errorf("unexpected lack of error for disorder in the iot use case")
+	}
+	c.LateChance = 0
+	c.EntryMissing = 1.5
+	if err = c.Validate(); err == nil {
+		t.Errorf("unexpected lack of error for an iot chance above 1")
+	}
 }
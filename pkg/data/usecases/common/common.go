@@ -24,6 +24,8 @@ const (
 	UseCaseDevops        = "devops"
 	UseCaseIoT           = "iot"
 	UseCaseDevopsGeneric = "devops-generic"
+	UseCaseCustom        = "custom"
+	UseCaseKubernetes    = "kubernetes"
 )
 
 var UseCaseChoices = []string{
@@ -32,4 +34,6 @@ var UseCaseChoices = []string{
 	UseCaseDevops,
 	UseCaseIoT,
 	UseCaseDevopsGeneric,
+	UseCaseCustom,
+	UseCaseKubernetes,
 }
@@ -0,0 +1,243 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+const (
+	errChanceFmt          = "%s has to be between 0 and 1, got %v"
+	errLateWithoutDelay   = "late points need a max lateness greater than 0"
+	errNegativeMaxLateFmt = "max lateness cannot be negative, got %v"
+	errDisorderIoT        = "the iot use case has its own disorder, see the iot-*-chance options"
+	defaultMaxLateness    = 5 * time.Minute
+)
+
+// Disorder is the disorder a DisorderSimulator adds to the points of a
+// use case: points that arrive late, duplicated points and missing points.
+type Disorder struct {
+	// LateChance is the chance that a point arrives late
+	LateChance float64 `yaml:"late-chance" mapstructure:"late-chance"`
+	// MaxLateness is the longest a late point can be delayed
+	MaxLateness time.Duration `yaml:"max-lateness" mapstructure:"max-lateness"`
+	// DuplicateChance is the chance that a point is written twice
+	DuplicateChance float64 `yaml:"duplicate-chance" mapstructure:"duplicate-chance"`
+	// GapChance is the chance that a point is missing
+	GapChance float64 `yaml:"gap-chance" mapstructure:"gap-chance"`
+}
+
+// Enabled tells whether the Disorder changes the points at all.
+func (d *Disorder) Enabled() bool {
+	return d.LateChance > 0 || d.DuplicateChance > 0 || d.GapChance > 0
+}
+
+// Validate checks that the chances of the Disorder are reasonable.
+func (d *Disorder) Validate() error {
+	if err := validateChances(map[string]float64{
+		"late-chance":      d.LateChance,
+		"duplicate-chance": d.DuplicateChance,
+		"gap-chance":       d.GapChance,
+	}); err != nil {
+		return err
+	}
+	if d.LateChance+d.DuplicateChance+d.GapChance > 1 {
+		return fmt.Errorf(errChanceFmt, "the sum of late-chance, duplicate-chance and gap-chance", d.LateChance+d.DuplicateChance+d.GapChance)
+	}
+	if d.MaxLateness < 0 {
+		return fmt.Errorf(errNegativeMaxLateFmt, d.MaxLateness)
+	}
+	if d.LateChance > 0 && d.MaxLateness == 0 {
+		return fmt.Errorf(errLateWithoutDelay)
+	}
+	return nil
+}
+
+func (d *Disorder) addToFlagSet(fs *pflag.FlagSet) {
+	fs.Float64("late-chance", 0,
+		"Chance that a point arrives late, after the points up to a random delay of at most max-lateness. Not used in iot use-case")
+	fs.Duration("max-lateness", defaultMaxLateness, "Max delay of the late points")
+	fs.Float64("duplicate-chance", 0, "Chance that a point is written twice. Not used in iot use-case")
+	fs.Float64("gap-chance", 0, "Chance that a point is missing. Not used in iot use-case")
+}
+
+// validateChances checks that every named chance is a probability, in the
+// order of the names.
+func validateChances(chances map[string]float64) error {
+	names := make([]string, 0, len(chances))
+	for name := range chances {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if v := chances[name]; v < 0 || v > 1 {
+			return fmt.Errorf(errChanceFmt, name, v)
+		}
+	}
+	return nil
+}
+
+// DisorderSimulatorConfig creates the Simulator of a SimulatorConfig wrapped
+// in a DisorderSimulator.
+type DisorderSimulatorConfig struct {
+	SimulatorConfig
+	Disorder Disorder
+}
+
+// NewSimulator produces a DisorderSimulator of the Simulator of the config.
+func (c *DisorderSimulatorConfig) NewSimulator(interval time.Duration, limit uint64) Simulator {
+	return NewDisorderSimulator(c.SimulatorConfig.NewSimulator(interval, limit), c.Disorder)
+}
+
+// NewShardSimulators produces a DisorderSimulator of each shard Simulator of
+// the config, drawing from the Rand of the shard. It returns nil if the
+// config cannot be sharded.
+func (c *DisorderSimulatorConfig) NewShardSimulators(interval time.Duration, rands []Rand) []Simulator {
+	sc, ok := c.SimulatorConfig.(ShardableSimulatorConfig)
+	if !ok {
+		return nil
+	}
+	sims := sc.NewShardSimulators(interval, rands)
+	for i, sim := range sims {
+		WithRand(rands[i], func() {
+			sims[i] = NewDisorderSimulator(sim, c.Disorder)
+		})
+	}
+	return sims
+}
+
+// DisorderSimulator adds disorder to the points of a Simulator. A late point
+// is held back until the Simulator returns a point at or after its timestamp
+// plus a random delay, a duplicated point is returned a second time right
+// away and a missing point is not written.
+type DisorderSimulator struct {
+	Simulator
+	disorder Disorder
+
+	// now is the latest timestamp returned by the Simulator
+	now       time.Time
+	late      []latePoint
+	duplicate *keptPoint
+	// free are the kept points returned already, to be reused
+	free []*keptPoint
+	// ts is the timestamp of the last kept point returned
+	ts   time.Time
+	rand Rand
+}
+
+// keptPoint is a copy of a point held back to be returned later, with the
+// storage of its timestamp.
+type keptPoint struct {
+	p  *data.Point
+	ts time.Time
+}
+
+type latePoint struct {
+	k   *keptPoint
+	due time.Time
+}
+
+// NewDisorderSimulator adds the disorder d to the points of sim, drawing
+// from the current Rand.
+func NewDisorderSimulator(sim Simulator, d Disorder) *DisorderSimulator {
+	return &DisorderSimulator{
+		Simulator: sim,
+		disorder:  d,
+		rand:      CurrentRand(),
+	}
+}
+
+// Finished tells whether the Simulator is finished and all the late and
+// duplicated points were returned.
+func (s *DisorderSimulator) Finished() bool {
+	return s.Simulator.Finished() && len(s.late) == 0 && s.duplicate == nil
+}
+
+// Next advances the Point to the next point to write, which can be a late or
+// duplicated one. The points that are not written are left empty.
+func (s *DisorderSimulator) Next(p *data.Point) bool {
+	if s.duplicate != nil {
+		s.release(p, s.duplicate)
+		s.duplicate = nil
+		return true
+	}
+	if len(s.late) > 0 && (s.Simulator.Finished() || !s.late[0].due.After(s.now)) {
+		s.release(p, s.late[0].k)
+		s.late = s.late[1:]
+		return true
+	}
+
+	if !s.Simulator.Next(p) {
+		p.Reset()
+		return false
+	}
+	ts := *p.Timestamp()
+	if ts.After(s.now) {
+		s.now = ts
+	}
+
+	d := &s.disorder
+	switch r := s.rand.Float64(); {
+	case r < d.GapChance:
+		p.Reset()
+		return false
+	case r < d.GapChance+d.LateChance:
+		s.addLate(s.keep(p, ts), ts.Add(time.Duration(1+s.rand.Int63n(int64(d.MaxLateness)))))
+		p.Reset()
+		return false
+	case r < d.GapChance+d.LateChance+d.DuplicateChance:
+		s.duplicate = s.keep(p, ts)
+	}
+	return true
+}
+
+// keep copies p, whose timestamp can point to the Simulator state, to be
+// returned later. The copy reuses the kept points returned already, so
+// the disorder adds no garbage once there are enough of them.
+func (s *DisorderSimulator) keep(p *data.Point, ts time.Time) *keptPoint {
+	var k *keptPoint
+	if n := len(s.free); n > 0 {
+		k, s.free = s.free[n-1], s.free[:n-1]
+	} else {
+		k = &keptPoint{p: data.NewPoint()}
+	}
+	copyPoint(k.p, p)
+	k.ts = ts
+	k.p.SetTimestamp(&k.ts)
+	return k
+}
+
+// release copies the kept point k to p and frees k. The timestamp of p is
+// valid until the next call of Next, like the ones of the Simulator.
+func (s *DisorderSimulator) release(p *data.Point, k *keptPoint) {
+	copyPoint(p, k.p)
+	s.ts = k.ts
+	p.SetTimestamp(&s.ts)
+	s.free = append(s.free, k)
+}
+
+// copyPoint copies the measurement, tags and fields of src to dst, into the
+// slices of dst, so they do not share them.
+func copyPoint(dst, src *data.Point) {
+	dst.Reset()
+	dst.SetMeasurementName(src.MeasurementName())
+	values := src.TagValues()
+	for i, key := range src.TagKeys() {
+		dst.AppendTag(key, values[i])
+	}
+	values = src.FieldValues()
+	for i, key := range src.FieldKeys() {
+		dst.AppendField(key, values[i])
+	}
+}
+
+// addLate holds k back until due, after the late points due before it.
+func (s *DisorderSimulator) addLate(k *keptPoint, due time.Time) {
+	i := sort.Search(len(s.late), func(i int) bool { return s.late[i].due.After(due) })
+	s.late = append(s.late, latePoint{})
+	copy(s.late[i+1:], s.late[i:])
+	s.late[i] = latePoint{k: k, due: due}
+}
@@ -0,0 +1,178 @@
+package common
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+func newClockSimulator(start time.Time, epochs int) Simulator {
+	conf := &BaseSimulatorConfig{
+		Start:              start,
+		End:                start.Add(time.Duration(epochs) * time.Second),
+		InitGeneratorScale: 1,
+		GeneratorScale:     1,
+		GeneratorConstructor: func(_ int, start time.Time) Generator {
+			return &clockGenerator{m: &clockMeasurement{NewSubsystemMeasurement(start, 0)}}
+		},
+	}
+	return conf.NewSimulator(time.Second, 0)
+}
+
+// disorderTimestamps returns the timestamps written by sim in seconds since
+// start. It reuses the point like the data generator does.
+func disorderTimestamps(t *testing.T, sim Simulator, start time.Time) []int {
+	var got []int
+	p := data.NewPoint()
+	for i := 0; !sim.Finished(); i++ {
+		if i > 1000 {
+			t.Fatalf("simulator not finished")
+		}
+		p.Reset()
+		if sim.Next(p) {
+			got = append(got, int(p.Timestamp().Sub(start)/time.Second))
+		}
+	}
+	return got
+}
+
+func TestDisorderValidate(t *testing.T) {
+	cases := []struct {
+		desc    string
+		d       Disorder
+		wantErr bool
+	}{
+		{desc: "none", d: Disorder{}},
+		{desc: "all", d: Disorder{LateChance: 0.2, MaxLateness: time.Minute, DuplicateChance: 0.3, GapChance: 0.5}},
+		{desc: "late without delay", d: Disorder{LateChance: 0.2}, wantErr: true},
+		{desc: "negative max lateness", d: Disorder{MaxLateness: -time.Minute}, wantErr: true},
+		{desc: "chance above 1", d: Disorder{DuplicateChance: 1.1}, wantErr: true},
+		{desc: "negative chance", d: Disorder{GapChance: -0.1}, wantErr: true},
+		{desc: "sum above 1", d: Disorder{DuplicateChance: 0.6, GapChance: 0.6}, wantErr: true},
+	}
+	for _, c := range cases {
+		if err := c.d.Validate(); (err != nil) != c.wantErr {
+			t.Errorf("%s: got error %v, want error %v", c.desc, err, c.wantErr)
+		}
+	}
+}
+
+func TestDisorderSimulatorNone(t *testing.T) {
+	start := time.Unix(1451606400, 0).UTC()
+	sim := NewDisorderSimulator(newClockSimulator(start, 10), Disorder{})
+	got := disorderTimestamps(t, sim, start)
+	if len(got) != 10 {
+		t.Fatalf("got %d points want 10", len(got))
+	}
+	for i, ts := range got {
+		if ts != i {
+			t.Errorf("point %d at %ds", i, ts)
+		}
+	}
+}
+
+func TestDisorderSimulatorGap(t *testing.T) {
+	start := time.Unix(1451606400, 0).UTC()
+	sim := NewDisorderSimulator(newClockSimulator(start, 10), Disorder{GapChance: 1})
+	if got := disorderTimestamps(t, sim, start); len(got) != 0 {
+		t.Errorf("got points with a certain gap: %v", got)
+	}
+}
+
+func TestDisorderSimulatorDuplicate(t *testing.T) {
+	start := time.Unix(1451606400, 0).UTC()
+	sim := NewDisorderSimulator(newClockSimulator(start, 10), Disorder{DuplicateChance: 1})
+	got := disorderTimestamps(t, sim, start)
+	if len(got) != 20 {
+		t.Fatalf("got %d points want 20", len(got))
+	}
+	for i, ts := range got {
+		if ts != i/2 {
+			t.Errorf("point %d at %ds want %ds", i, ts, i/2)
+		}
+	}
+}
+
+func TestDisorderSimulatorLate(t *testing.T) {
+	rand.Seed(123)
+	start := time.Unix(1451606400, 0).UTC()
+	maxLateness := 3 * time.Second
+	sim := NewDisorderSimulator(newClockSimulator(start, 100), Disorder{LateChance: 0.3, MaxLateness: maxLateness})
+	got := disorderTimestamps(t, sim, start)
+	if len(got) != 100 {
+		t.Fatalf("got %d points want 100", len(got))
+	}
+
+	seen := make(map[int]bool)
+	late := 0
+	latest := -1
+	for i, ts := range got {
+		if seen[ts] {
+			t.Errorf("point %d at %ds written twice", i, ts)
+		}
+		seen[ts] = true
+		if ts < latest {
+			late++
+			// a late point is written with the first point after it is due
+			if time.Duration(latest-ts)*time.Second > maxLateness {
+				t.Errorf("point %d at %ds written after %ds", i, ts, latest)
+			}
+		}
+		if ts > latest {
+			latest = ts
+		}
+	}
+	if late == 0 {
+		t.Errorf("no late points")
+	}
+}
+
+// countSimulator returns points with the count of points as their value,
+// without allocating.
+type countSimulator struct {
+	Simulator
+	ts    time.Time
+	count int64
+}
+
+var labelCount = []byte("count")
+
+func (s *countSimulator) Finished() bool {
+	return false
+}
+
+func (s *countSimulator) Next(p *data.Point) bool {
+	s.count++
+	s.ts = s.ts.Add(time.Second)
+	p.SetMeasurementName(labelCount)
+	p.AppendField(labelCount, s.count)
+	p.SetTimestamp(&s.ts)
+	return true
+}
+
+func TestDisorderSimulatorAllocs(t *testing.T) {
+	rand.Seed(123)
+	sim := NewDisorderSimulator(&countSimulator{}, Disorder{DuplicateChance: 0.5})
+	p := data.NewPoint()
+	var prev int64
+	next := func() {
+		p.Reset()
+		if !sim.Next(p) {
+			t.Fatalf("point not written")
+		}
+		// the duplicated points are copies, not overwritten by the next ones
+		count := p.GetFieldValue(labelCount).(int64)
+		if count != prev && count != prev+1 {
+			t.Fatalf("point %d written after point %d", count, prev)
+		}
+		prev = count
+	}
+	for i := 0; i < 100; i++ {
+		next()
+	}
+	if allocs := testing.AllocsPerRun(1000, next); allocs != 0 {
+		t.Errorf("got %v allocations per point want 0", allocs)
+	}
+}
@@ -2,7 +2,6 @@ package common
 
 import (
 	"math"
-	"math/rand"
 )
 
 // Distribution provides an interface to model a statistical distribution.
@@ -17,20 +16,23 @@ type NormalDistribution struct {
 	StdDev float64
 
 	value float64
+	rand  Rand
 }
 
-// ND creates a new normal distribution with the given mean/stddev
+// ND creates a new normal distribution with the given mean/stddev, drawing
+// from the current Rand
 func ND(mean, stddev float64) *NormalDistribution {
 	return &NormalDistribution{
 		Mean:   mean,
 		StdDev: stddev,
+		rand:   CurrentRand(),
 	}
 }
 
 // Advance advances this distribution. Since the distribution is
 // stateless, this just overwrites the internal cache value.
 func (d *NormalDistribution) Advance() {
-	d.value = rand.NormFloat64()*d.StdDev + d.Mean
+	d.value = randOrGlobal(d.rand).NormFloat64()*d.StdDev + d.Mean
 }
 
 // Get returns the last computed value for this distribution.
@@ -44,20 +46,23 @@ type UniformDistribution struct {
 	High float64
 
 	value float64
+	rand  Rand
 }
 
-// UD creates a new uniform distribution with the given range
+// UD creates a new uniform distribution with the given range, drawing from
+// the current Rand
 func UD(low, high float64) *UniformDistribution {
 	return &UniformDistribution{
 		Low:  low,
 		High: high,
+		rand: CurrentRand(),
 	}
 }
 
 // Advance advances this distribution. Since the distribution is
 // stateless, this just overwrites the internal cache value.
 func (d *UniformDistribution) Advance() {
-	x := rand.Float64() // uniform
+	x := randOrGlobal(d.rand).Float64() // uniform
 	x *= d.High - d.Low
 	x += d.Low
 	d.value = x
@@ -25,7 +25,12 @@ type Tag struct {
 const (
 	errMaxMetricCountValue = "max metric count per host has to be greater than 0"
 	errLogIntervalZero     = "cannot have log interval of 0"
+	errNoUseCaseFile       = "the custom use case needs a use case file"
+	errNegativePodLifetime = "pod lifetime cannot be negative"
+	errNegativeChurnRate   = "churn rate cannot be negative"
 	defaultLogInterval     = 10 * time.Second
+	defaultPodLifetime     = 2 * time.Hour
+	defaultChurnRate       = 0.25
 )
 
 // DataGeneratorConfig is the GeneratorConfig that should be used with a
@@ -40,6 +45,78 @@ type DataGeneratorConfig struct {
 	InterleavedGroupID    uint          `yaml:"interleaved-generation-group-id" mapstructure:"interleaved-generation-group-id"`
 	InterleavedNumGroups  uint          `yaml:"interleaved-generation-groups" mapstructure:"interleaved-generation-groups"`
 	MaxMetricCountPerHost uint64        `yaml:"max-metric-count" mapstructure:"max-metric-count"`
+	GeneratorWorkers      uint          `yaml:"generator-workers" mapstructure:"generator-workers"`
+	UseCaseFile           string        `yaml:"use-case-file" mapstructure:"use-case-file"`
+	RealTime              bool          `yaml:"real-time" mapstructure:"real-time"`
+	PodLifetime           time.Duration `yaml:"pod-lifetime" mapstructure:"pod-lifetime"`
+	ChurnRate             float64       `yaml:"churn-rate" mapstructure:"churn-rate"`
+	IoTChances            `yaml:",inline" mapstructure:",squash"`
+	Disorder              `yaml:",inline" mapstructure:",squash"`
+}
+
+// IoTChances are the chances of the disorder the IoT simulator introduces in
+// its batches of entries.
+type IoTChances struct {
+	// Batch chances.
+	BatchMissing        float64 `yaml:"iot-batch-missing-chance" mapstructure:"iot-batch-missing-chance"`
+	BatchOutOfOrder     float64 `yaml:"iot-batch-out-of-order-chance" mapstructure:"iot-batch-out-of-order-chance"`
+	BatchInsertPrevious float64 `yaml:"iot-batch-insert-previous-chance" mapstructure:"iot-batch-insert-previous-chance"`
+
+	// Entry chances.
+	EntryMissing        float64 `yaml:"iot-entry-missing-chance" mapstructure:"iot-entry-missing-chance"`
+	EntryOutOfOrder     float64 `yaml:"iot-entry-out-of-order-chance" mapstructure:"iot-entry-out-of-order-chance"`
+	EntryInsertPrevious float64 `yaml:"iot-entry-insert-previous-chance" mapstructure:"iot-entry-insert-previous-chance"`
+
+	// Zero values.
+	ZeroTag   float64 `yaml:"iot-zero-tag-chance" mapstructure:"iot-zero-tag-chance"`
+	ZeroField float64 `yaml:"iot-zero-field-chance" mapstructure:"iot-zero-field-chance"`
+}
+
+// DefaultIoTChances are the chances of the IoT use case unless configured
+// otherwise.
+var DefaultIoTChances = IoTChances{
+	BatchMissing:        0.01,
+	BatchOutOfOrder:     0.05,
+	BatchInsertPrevious: 0.5,
+
+	EntryMissing:        0.1,
+	EntryOutOfOrder:     0.3,
+	EntryInsertPrevious: 0.5,
+
+	ZeroTag:   0.01,
+	ZeroField: 0.1,
+}
+
+// Validate checks that all the IoTChances are probabilities.
+func (c *IoTChances) Validate() error {
+	return validateChances(map[string]float64{
+		"iot-batch-missing-chance":         c.BatchMissing,
+		"iot-batch-out-of-order-chance":    c.BatchOutOfOrder,
+		"iot-batch-insert-previous-chance": c.BatchInsertPrevious,
+		"iot-entry-missing-chance":         c.EntryMissing,
+		"iot-entry-out-of-order-chance":    c.EntryOutOfOrder,
+		"iot-entry-insert-previous-chance": c.EntryInsertPrevious,
+		"iot-zero-tag-chance":              c.ZeroTag,
+		"iot-zero-field-chance":            c.ZeroField,
+	})
+}
+
+// AddToFlagSet adds the flags of the chances, defaulting to DefaultIoTChances,
+// with their names prefixed by prefix.
+func (c *IoTChances) AddToFlagSet(fs *pflag.FlagSet, prefix string) {
+	d := &DefaultIoTChances
+	fs.Float64(prefix+"iot-batch-missing-chance", d.BatchMissing, "Chance that a batch of entries is missing. Used only in iot use-case")
+	fs.Float64(prefix+"iot-batch-out-of-order-chance", d.BatchOutOfOrder,
+		"Chance that a batch of entries is held back to be inserted later. Used only in iot use-case")
+	fs.Float64(prefix+"iot-batch-insert-previous-chance", d.BatchInsertPrevious,
+		"Chance that a held back batch is inserted instead of a new one. Used only in iot use-case")
+	fs.Float64(prefix+"iot-entry-missing-chance", d.EntryMissing, "Chance that an entry is missing. Used only in iot use-case")
+	fs.Float64(prefix+"iot-entry-out-of-order-chance", d.EntryOutOfOrder,
+		"Chance that an entry is held back to be inserted later. Used only in iot use-case")
+	fs.Float64(prefix+"iot-entry-insert-previous-chance", d.EntryInsertPrevious,
+		"Chance that a held back entry is inserted before a new one. Used only in iot use-case")
+	fs.Float64(prefix+"iot-zero-tag-chance", d.ZeroTag, "Chance that an entry has an empty tag. Used only in iot use-case")
+	fs.Float64(prefix+"iot-zero-field-chance", d.ZeroField, "Chance that an entry has an empty field. Used only in iot use-case")
 }
 
 // Validate checks that the values of the DataGeneratorConfig are reasonable.
@@ -57,12 +134,41 @@ func (c *DataGeneratorConfig) Validate() error {
 		return fmt.Errorf(errLogIntervalZero)
 	}
 
+	if c.GeneratorWorkers == 0 {
+		c.GeneratorWorkers = 1
+	}
+
 	err = utils.ValidateGroups(c.InterleavedGroupID, c.InterleavedNumGroups)
 
 	if c.Use == UseCaseDevopsGeneric && c.MaxMetricCountPerHost < 1 {
 		return fmt.Errorf(errMaxMetricCountValue)
 	}
 
+	if c.Use == UseCaseCustom && c.UseCaseFile == "" {
+		return fmt.Errorf(errNoUseCaseFile)
+	}
+
+	if c.Use == UseCaseKubernetes && c.PodLifetime < 0 {
+		return fmt.Errorf(errNegativePodLifetime)
+	}
+
+	if c.Use == UseCaseKubernetes && c.ChurnRate < 0 {
+		return fmt.Errorf(errNegativeChurnRate)
+	}
+
+	if c.Use == UseCaseIoT {
+		if c.Disorder.Enabled() {
+			return fmt.Errorf(errDisorderIoT)
+		}
+		if err := c.IoTChances.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Disorder.Validate(); err != nil {
+		return err
+	}
+
 	return err
 }
 
@@ -77,6 +183,16 @@ func (c *DataGeneratorConfig) AddToFlagSet(fs *pflag.FlagSet) {
 	fs.Uint("interleaved-generation-groups", 1,
 		"The number of round-robin serialization groups. Use this to scale up data generation to multiple processes.")
 	fs.Uint64("max-metric-count", 100, "Max number of metric fields to generate per host. Used only in devops-generic use-case")
+	fs.Bool("real-time", false,
+		"Stamp the points with the wall-clock time and emit each log interval when it is due, for as long as the time between timestamp-start and timestamp-end.")
+	fs.Duration("pod-lifetime", defaultPodLifetime,
+		"Mean lifetime of a pod before it is replaced, 0 = until its deployment is rolled out. Used only in kubernetes use-case")
+	fs.Float64("churn-rate", defaultChurnRate, "Number of rollouts per deployment per hour. Used only in kubernetes use-case")
+	c.IoTChances.AddToFlagSet(fs, "")
+	c.Disorder.addToFlagSet(fs)
+	fs.String("use-case-file", "", "YAML file declaring the entities, measurements and fields of the custom use case")
+	fs.Uint("generator-workers", 1,
+		"The number of goroutines simulating and serializing a share of the hosts each. The output is the same for the same seed and number of workers.")
 }
 
 const defaultTimeStart = "2016-01-01T00:00:00Z"
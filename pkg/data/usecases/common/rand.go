@@ -0,0 +1,85 @@
+package common
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// Rand is a source of the random values of the simulated data, a *rand.Rand
+// fulfills it.
+type Rand interface {
+	Float64() float64
+	ExpFloat64() float64
+	NormFloat64() float64
+	Intn(n int) int
+	Int63n(n int64) int64
+}
+
+// globalRand draws from the top-level source of math/rand, which the data
+// generator seeds.
+type globalRand struct{}
+
+func (globalRand) Float64() float64     { return rand.Float64() }
+func (globalRand) ExpFloat64() float64  { return rand.ExpFloat64() }
+func (globalRand) NormFloat64() float64 { return rand.NormFloat64() }
+func (globalRand) Intn(n int) int       { return rand.Intn(n) }
+func (globalRand) Int63n(n int64) int64 { return rand.Int63n(n) }
+
+var (
+	currentRand   Rand = globalRand{}
+	currentRandMu sync.Mutex
+)
+
+// CurrentRand returns the Rand that the distributions created now draw their
+// values from: the top-level source of math/rand, unless called from WithRand.
+func CurrentRand() Rand {
+	return currentRand
+}
+
+// WithRand calls f with r as the current Rand. The calls are serialized, so
+// the generators of different shards can create new distributions while they
+// are simulated concurrently, each from its own Rand. f must not call WithRand.
+func WithRand(r Rand, f func()) {
+	currentRandMu.Lock()
+	defer currentRandMu.Unlock()
+	prev := currentRand
+	currentRand = r
+	defer func() { currentRand = prev }()
+	f()
+}
+
+// SharedDistribution is a stateless distribution used by many generators,
+// e.g. as the step of their random walks. There is one per Rand, so the
+// generators of different shards do not share it.
+type SharedDistribution struct {
+	newDistribution func() Distribution
+	distributions   map[Rand]Distribution
+}
+
+// Shared returns a SharedDistribution created with newDistribution.
+func Shared(newDistribution func() Distribution) *SharedDistribution {
+	return &SharedDistribution{
+		newDistribution: newDistribution,
+		distributions:   make(map[Rand]Distribution),
+	}
+}
+
+// Current returns the distribution drawing from the current Rand.
+func (s *SharedDistribution) Current() Distribution {
+	r := CurrentRand()
+	d, ok := s.distributions[r]
+	if !ok {
+		d = s.newDistribution()
+		s.distributions[r] = d
+	}
+	return d
+}
+
+// randOrGlobal returns r, or the top-level source of math/rand if r is nil,
+// e.g. for a distribution not created by its constructor.
+func randOrGlobal(r Rand) Rand {
+	if r == nil {
+		return globalRand{}
+	}
+	return r
+}
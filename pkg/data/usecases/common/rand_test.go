@@ -0,0 +1,48 @@
+package common
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestWithRand(t *testing.T) {
+	if _, ok := CurrentRand().(globalRand); !ok {
+		t.Fatalf("the current Rand is not the math/rand one by default")
+	}
+	r := rand.New(rand.NewSource(1))
+	var nd *NormalDistribution
+	var ud *UniformDistribution
+	WithRand(r, func() {
+		if CurrentRand() != r {
+			t.Errorf("the current Rand is not the one of WithRand")
+		}
+		nd = ND(0, 1)
+		ud = UD(0, 1)
+	})
+	if _, ok := CurrentRand().(globalRand); !ok {
+		t.Errorf("the current Rand is not restored after WithRand")
+	}
+
+	want := rand.New(rand.NewSource(1))
+	nd.Advance()
+	if got := nd.Get(); got != want.NormFloat64() {
+		t.Errorf("the normal distribution does not draw from its Rand")
+	}
+	ud.Advance()
+	if got := ud.Get(); got != want.Float64() {
+		t.Errorf("the uniform distribution does not draw from its Rand")
+	}
+}
+
+func TestSharedDistribution(t *testing.T) {
+	s := Shared(func() Distribution { return ND(0, 1) })
+	d := s.Current()
+	if s.Current() != d {
+		t.Errorf("got a new distribution for the same Rand")
+	}
+	var other Distribution
+	WithRand(rand.New(rand.NewSource(1)), func() { other = s.Current() })
+	if other == d {
+		t.Errorf("got the same distribution for another Rand")
+	}
+}
@@ -0,0 +1,74 @@
+package common
+
+import (
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+// RealTimeSimulator paces a Simulator so that every point is returned when
+// its timestamp is due on the wall clock. The Simulator is expected to start
+// at the current time (see RealTimeStart), so each epoch is emitted exactly
+// one log interval after the previous one. Points with a past timestamp, such
+// as the out-of-order entries of the IoT use case, are returned right away.
+type RealTimeSimulator struct {
+	Simulator
+	*RealTimePacer
+}
+
+// NewRealTimeSimulator paces sim in real time, calling beforeWait (if not
+// nil) every time it waits for the next point to be due.
+func NewRealTimeSimulator(sim Simulator, beforeWait func()) *RealTimeSimulator {
+	return &RealTimeSimulator{
+		Simulator:     sim,
+		RealTimePacer: NewRealTimePacer(beforeWait),
+	}
+}
+
+// Next advances the Point to the next state of the Simulator, once it is due.
+func (s *RealTimeSimulator) Next(p *data.Point) bool {
+	write := s.Simulator.Next(p)
+	if ts := p.Timestamp(); ts != nil {
+		s.Wait(*ts)
+	}
+	return write
+}
+
+// RealTimePacer waits for the timestamps of points to be due on the wall
+// clock.
+type RealTimePacer struct {
+	// beforeWait is called before waiting for a point to be due, e.g. to
+	// flush the output written so far
+	beforeWait func()
+
+	// change for testing
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+// NewRealTimePacer returns a RealTimePacer calling beforeWait (if not nil)
+// every time it waits.
+func NewRealTimePacer(beforeWait func()) *RealTimePacer {
+	return &RealTimePacer{
+		beforeWait: beforeWait,
+		now:        time.Now,
+		sleep:      time.Sleep,
+	}
+}
+
+// Wait returns once ts is due, right away if it is in the past.
+func (p *RealTimePacer) Wait(ts time.Time) {
+	if wait := ts.Sub(p.now()); wait > 0 {
+		if p.beforeWait != nil {
+			p.beforeWait()
+		}
+		p.sleep(wait)
+	}
+}
+
+// RealTimeStart moves the simulated period from start to end so it starts
+// now, keeping its duration.
+func RealTimeStart(start, end time.Time) (time.Time, time.Time) {
+	now := time.Now().UTC()
+	return now, now.Add(end.Sub(start))
+}
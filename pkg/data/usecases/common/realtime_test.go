@@ -0,0 +1,92 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+// clockGenerator has a single measurement with the time of the generator
+type clockGenerator struct {
+	m *clockMeasurement
+}
+
+type clockMeasurement struct {
+	*SubsystemMeasurement
+}
+
+func (m *clockMeasurement) ToPoint(p *data.Point) {
+	p.SetMeasurementName(dummyMeasurementName)
+	p.SetTimestamp(&m.Timestamp)
+}
+
+func (g *clockGenerator) Measurements() []SimulatedMeasurement {
+	return []SimulatedMeasurement{g.m}
+}
+
+func (g *clockGenerator) Tags() []Tag {
+	return nil
+}
+
+func (g *clockGenerator) TickAll(d time.Duration) {
+	g.m.Tick(d)
+}
+
+func TestRealTimeSimulator(t *testing.T) {
+	start := time.Unix(1451606400, 0).UTC()
+	conf := &BaseSimulatorConfig{
+		Start:              start,
+		End:                start.Add(3 * time.Second),
+		InitGeneratorScale: 2,
+		GeneratorScale:     2,
+		GeneratorConstructor: func(_ int, start time.Time) Generator {
+			return &clockGenerator{m: &clockMeasurement{NewSubsystemMeasurement(start, 0)}}
+		},
+	}
+
+	var waits []time.Duration
+	flushes := 0
+	now := start
+	sim := NewRealTimeSimulator(conf.NewSimulator(time.Second, 0), func() { flushes++ })
+	sim.now = func() time.Time { return now }
+	sim.sleep = func(d time.Duration) {
+		waits = append(waits, d)
+		now = now.Add(d)
+	}
+
+	points := 0
+	for !sim.Finished() {
+		p := data.NewPoint()
+		if !sim.Next(p) {
+			t.Fatalf("point %d not written", points)
+		}
+		if !p.Timestamp().Equal(now) {
+			t.Fatalf("point %d returned at %v, due at %v", points, now, p.Timestamp())
+		}
+		points++
+	}
+	// 3 epochs of 2 generators, the simulator waits once for each epoch
+	// after the first
+	if points != 6 {
+		t.Errorf("got %d points want 6", points)
+	}
+	if len(waits) != 2 || waits[0] != time.Second || waits[1] != time.Second {
+		t.Errorf("wrong waits: %v", waits)
+	}
+	if flushes != 2 {
+		t.Errorf("got %d calls before waiting want 2", flushes)
+	}
+}
+
+func TestRealTimeStart(t *testing.T) {
+	start := time.Unix(1451606400, 0).UTC()
+	before := time.Now()
+	gotStart, gotEnd := RealTimeStart(start, start.Add(time.Hour))
+	if gotStart.Before(before) || gotStart.After(time.Now()) {
+		t.Errorf("start is not now: %v", gotStart)
+	}
+	if d := gotEnd.Sub(gotStart); d != time.Hour {
+		t.Errorf("wrong duration: got %v want %v", d, time.Hour)
+	}
+}
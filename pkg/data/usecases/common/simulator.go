@@ -11,6 +11,17 @@ type SimulatorConfig interface {
 	NewSimulator(time.Duration, uint64) Simulator
 }
 
+// ShardableSimulatorConfig is a SimulatorConfig whose generators can be split
+// between several Simulators, the shards, which can run concurrently.
+type ShardableSimulatorConfig interface {
+	SimulatorConfig
+	// NewShardSimulators produces one Simulator per Rand over the specified
+	// interval, or fewer if there are not enough generators to split. Each
+	// one simulates a part of the generators, drawing from its own Rand, and
+	// returns their points in the order NewSimulator would, without a limit.
+	NewShardSimulators(time.Duration, []Rand) []Simulator
+}
+
 // BaseSimulatorConfig is used to create a BaseSimulator.
 type BaseSimulatorConfig struct {
 	// Start is the beginning time for the Simulator
@@ -23,6 +34,9 @@ type BaseSimulatorConfig struct {
 	GeneratorScale uint64
 	// GeneratorConstructor is the function used to create a new Generator given an id number and start time
 	GeneratorConstructor func(i int, start time.Time) Generator
+	// ShardGroupSize is the number of consecutive Generators that share some
+	// state and have to be in the same shard, 0 means 1
+	ShardGroupSize int
 }
 
 func calculateEpochs(duration time.Duration, interval time.Duration) uint64 {
@@ -63,6 +77,51 @@ func (sc *BaseSimulatorConfig) NewSimulator(interval time.Duration, limit uint64
 	return sim
 }
 
+// NewShardSimulators produces the shard Simulators of the config. The groups
+// of ShardGroupSize Generators are dealt to the shards in turn. They are
+// created in order, so what they draw from the top-level math/rand source,
+// e.g. their tags, is the same as with NewSimulator.
+func (sc *BaseSimulatorConfig) NewShardSimulators(interval time.Duration, rands []Rand) []Simulator {
+	groupSize := uint64(1)
+	if sc.ShardGroupSize > 1 {
+		groupSize = uint64(sc.ShardGroupSize)
+	}
+	shards := uint64(len(rands))
+	if groups := (sc.GeneratorScale + groupSize - 1) / groupSize; groups < shards {
+		shards = groups
+	}
+
+	epochs := calculateEpochs(sc.End.Sub(sc.Start), interval)
+	sims := make([]*BaseSimulator, shards)
+	for i := range sims {
+		sims[i] = &BaseSimulator{
+			epochs:          epochs,
+			epochGenerators: sc.InitGeneratorScale,
+			initGenerators:  sc.InitGeneratorScale,
+			timestampStart:  sc.Start,
+			timestampEnd:    sc.End,
+			interval:        interval,
+			ids:             []uint64{},
+			scale:           sc.GeneratorScale,
+		}
+	}
+	for i := uint64(0); i < sc.GeneratorScale; i++ {
+		shard := i / groupSize % shards
+		sim := sims[shard]
+		WithRand(rands[shard], func() {
+			sim.generators = append(sim.generators, sc.GeneratorConstructor(int(i), sc.Start))
+		})
+		sim.ids = append(sim.ids, i)
+	}
+
+	ret := make([]Simulator, shards)
+	for i, sim := range sims {
+		sim.maxPoints = epochs * uint64(len(sim.generators)) * uint64(len(sim.generators[0].Measurements()))
+		ret[i] = sim
+	}
+	return ret
+}
+
 type GeneratedDataHeaders struct {
 	TagTypes  []string
 	TagKeys   []string
@@ -97,6 +156,27 @@ type BaseSimulator struct {
 	interval       time.Duration
 
 	simulatedMeasurementIndex int
+
+	// ids are the indexes of the generators in their config when the
+	// simulator is a shard of it, which has scale generators in all
+	ids   []uint64
+	scale uint64
+}
+
+// generatorID returns the index of the i-th generator in the config.
+func (s *BaseSimulator) generatorID(i uint64) uint64 {
+	if s.ids == nil {
+		return i
+	}
+	return s.ids[i]
+}
+
+// generatorScale returns the number of generators of the config.
+func (s *BaseSimulator) generatorScale() uint64 {
+	if s.ids == nil {
+		return uint64(len(s.generators))
+	}
+	return s.scale
 }
 
 // Finished tells whether we have simulated all the necessary points.
@@ -131,7 +211,7 @@ func (s *BaseSimulator) Next(p *data.Point) bool {
 	// Populate measurement-specific tags and fields:
 	generator.Measurements()[s.simulatedMeasurementIndex].ToPoint(p)
 
-	ret := s.generatorIndex < s.epochGenerators
+	ret := s.generatorID(s.generatorIndex) < s.epochGenerators
 	s.madePoints++
 	s.generatorIndex++
 	return ret
@@ -207,7 +287,7 @@ func (s *BaseSimulator) Headers() *GeneratedDataHeaders {
 // we check whether the point should be recorded by the calling process.
 func (s *BaseSimulator) adjustNumHostsForEpoch() {
 	s.epoch++
-	missingScale := float64(uint64(len(s.generators)) - s.initGenerators)
+	missingScale := float64(s.generatorScale() - s.initGenerators)
 	s.epochGenerators = s.initGenerators + uint64(missingScale*float64(s.epoch)/float64(s.epochs-1))
 }
 
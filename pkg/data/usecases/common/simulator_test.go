@@ -3,6 +3,7 @@ package common
 import (
 	"fmt"
 	"github.com/timescale/tsbs/pkg/data"
+	"math/rand"
 	"testing"
 	"time"
 )
@@ -249,3 +250,57 @@ func TestBaseSimulatorConfigNewSimulator(t *testing.T) {
 	}
 
 }
+
+func TestBaseSimulatorConfigNewShardSimulators(t *testing.T) {
+	rands := func(n int) []Rand {
+		r := make([]Rand, n)
+		for i := range r {
+			r[i] = rand.New(rand.NewSource(int64(i)))
+		}
+		return r
+	}
+	written := func(sims ...Simulator) (points, write int) {
+		for _, sim := range sims {
+			for !sim.Finished() {
+				if sim.Next(data.NewPoint()) {
+					write++
+				}
+				points++
+			}
+		}
+		return points, write
+	}
+	wantPoints, wantWrite := written(testBaseConf.NewSimulator(time.Second, 0))
+
+	cases := []struct {
+		desc      string
+		shards    int
+		groupSize int
+		want      [][]uint64
+	}{
+		{desc: "dealt in turn", shards: 3, want: [][]uint64{{0, 3, 6}, {1, 4}, {2, 5}}},
+		{desc: "in groups", shards: 2, groupSize: 3, want: [][]uint64{{0, 1, 2, 6}, {3, 4, 5}}},
+		{desc: "more shards than groups", shards: 5, groupSize: 4, want: [][]uint64{{0, 1, 2, 3}, {4, 5, 6}}},
+	}
+	for _, c := range cases {
+		conf := *testBaseConf
+		conf.GeneratorScale = 7
+		conf.ShardGroupSize = c.groupSize
+		sims := conf.NewShardSimulators(time.Second, rands(c.shards))
+		if len(sims) != len(c.want) {
+			t.Fatalf("%s: got %d shards want %d", c.desc, len(sims), len(c.want))
+		}
+		for i, sim := range sims {
+			if got := sim.(*BaseSimulator).ids; fmt.Sprint(got) != fmt.Sprint(c.want[i]) {
+				t.Errorf("%s: shard %d has generators %v want %v", c.desc, i, got, c.want[i])
+			}
+		}
+	}
+
+	// the shards make the same points and write the same ones as they
+	// ramp up from the initial scale
+	points, write := written(testBaseConf.NewShardSimulators(time.Second, rands(3))...)
+	if points != wantPoints || write != wantWrite {
+		t.Errorf("shards made %d points and wrote %d want %d and %d", points, write, wantPoints, wantWrite)
+	}
+}
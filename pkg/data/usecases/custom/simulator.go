@@ -0,0 +1,98 @@
+package custom
+
+import (
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+// SimulatorConfig returns the config of a simulator of the use case from
+// start to end, ramping up from initScale to scale entities.
+func (uc *UseCase) SimulatorConfig(start, end time.Time, initScale, scale uint64) *common.BaseSimulatorConfig {
+	return &common.BaseSimulatorConfig{
+		Start: start,
+		End:   end,
+
+		InitGeneratorScale:   initScale,
+		GeneratorScale:       scale,
+		GeneratorConstructor: uc.NewEntity,
+	}
+}
+
+// Entity is a simulated entity of a custom use case.
+type Entity struct {
+	tags         []common.Tag
+	measurements []common.SimulatedMeasurement
+}
+
+// NewEntity creates the entity with index i of the use case.
+func (uc *UseCase) NewEntity(i int, start time.Time) common.Generator {
+	e := &Entity{
+		tags:         make([]common.Tag, len(uc.Entities.Tags)),
+		measurements: make([]common.SimulatedMeasurement, len(uc.Measurements)),
+	}
+	for j := range uc.Entities.Tags {
+		t := &uc.Entities.Tags[j]
+		e.tags[j] = common.Tag{Key: []byte(t.Key), Value: t.value(i)}
+	}
+	for j := range uc.Measurements {
+		e.measurements[j] = newMeasurement(&uc.Measurements[j], start)
+	}
+	return e
+}
+
+// Measurements returns the simulated measurements of the entity.
+func (e *Entity) Measurements() []common.SimulatedMeasurement {
+	return e.measurements
+}
+
+// Tags returns the tags of the entity.
+func (e *Entity) Tags() []common.Tag {
+	return e.tags
+}
+
+// TickAll advances all the measurements of the entity.
+func (e *Entity) TickAll(d time.Duration) {
+	for _, m := range e.measurements {
+		m.Tick(d)
+	}
+}
+
+// measurement is a measurement of a custom use case, its fields are in the
+// order of the declaration.
+type measurement struct {
+	*common.SubsystemMeasurement
+	name   []byte
+	labels [][]byte
+	isInt  []bool
+}
+
+func newMeasurement(spec *MeasurementSpec, start time.Time) *measurement {
+	m := &measurement{
+		SubsystemMeasurement: common.NewSubsystemMeasurement(start, len(spec.Fields)),
+		name:                 []byte(spec.Name),
+		labels:               make([][]byte, len(spec.Fields)),
+		isInt:                make([]bool, len(spec.Fields)),
+	}
+	for i, f := range spec.Fields {
+		m.labels[i] = []byte(f.Name)
+		m.isInt[i] = f.Type == FieldTypeInt
+		m.Distributions[i] = f.Distribution.build()
+	}
+	return m
+}
+
+// ToPoint serializes the measurement to a data.Point.
+func (m *measurement) ToPoint(p *data.Point) {
+	p.SetMeasurementName(m.name)
+	p.SetTimestamp(&m.Timestamp)
+
+	for i, d := range m.Distributions {
+		if m.isInt[i] {
+			p.AppendField(m.labels[i], int64(d.Get()))
+		} else {
+			p.AppendField(m.labels[i], d.Get())
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package custom
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+func TestNewEntity(t *testing.T) {
+	uc, err := ParseUseCase([]byte(testUseCase))
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(1451606400, 0)
+	e := uc.NewEntity(7, start)
+
+	tags := e.Tags()
+	if len(tags) != 3 || string(tags[0].Key) != "sensor" || tags[0].Value != "sensor_7" {
+		t.Fatalf("wrong tags: %+v", tags)
+	}
+	if site := tags[1].Value; site != "north" && site != "south" {
+		t.Errorf("site not in the values: %v", site)
+	}
+	if floor := tags[2].Value; floor != "1" && floor != "2" && floor != "3" {
+		t.Errorf("floor not in [1, 3]: %v", floor)
+	}
+
+	e.TickAll(10 * time.Second)
+	p := data.NewPoint()
+	e.Measurements()[0].ToPoint(p)
+	if got := string(p.MeasurementName()); got != "climate" {
+		t.Errorf("wrong measurement: %s", got)
+	}
+	if got := *p.Timestamp(); !got.Equal(start.Add(10 * time.Second)) {
+		t.Errorf("wrong timestamp: %v", got)
+	}
+	wantKeys := [][]byte{[]byte("temperature"), []byte("humidity")}
+	if !reflect.DeepEqual(p.FieldKeys(), wantKeys) {
+		t.Errorf("wrong field keys: %s", p.FieldKeys())
+	}
+	if _, ok := p.GetFieldValue([]byte("temperature")).(float64); !ok {
+		t.Errorf("temperature is not a float64: %T", p.GetFieldValue([]byte("temperature")))
+	}
+	humidity, ok := p.GetFieldValue([]byte("humidity")).(int64)
+	if !ok || humidity < 49 || humidity > 51 {
+		t.Errorf("wrong humidity: %v", p.GetFieldValue([]byte("humidity")))
+	}
+}
+
+func TestSimulator(t *testing.T) {
+	uc, err := ParseUseCase([]byte(testUseCase))
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(1451606400, 0)
+	sim := uc.SimulatorConfig(start, start.Add(time.Minute), 3, 3).NewSimulator(10*time.Second, 0)
+
+	wantFields := map[string][]string{
+		"climate": {"temperature", "humidity"},
+		"power":   {"energy", "offset", "mode"},
+	}
+	if got := sim.Fields(); !reflect.DeepEqual(got, wantFields) {
+		t.Errorf("wrong fields: got %v want %v", got, wantFields)
+	}
+	if got := sim.TagKeys(); !reflect.DeepEqual(got, []string{"sensor", "site", "floor"}) {
+		t.Errorf("wrong tag keys: %v", got)
+	}
+
+	points := 0
+	measurements := make(map[string]int)
+	for !sim.Finished() {
+		p := data.NewPoint()
+		if sim.Next(p) {
+			points++
+			measurements[string(p.MeasurementName())]++
+		}
+	}
+	// 6 log intervals of 3 entities with 2 measurements
+	if points != 36 || measurements["climate"] != 18 || measurements["power"] != 18 {
+		t.Errorf("wrong points: %d %v", points, measurements)
+	}
+}
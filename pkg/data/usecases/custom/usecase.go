@@ -0,0 +1,293 @@
+package custom
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"strconv"
+
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"gopkg.in/yaml.v2"
+)
+
+// Distribution types, named after the common package constructors
+const (
+	DistributionND  = "nd"
+	DistributionUD  = "ud"
+	DistributionWD  = "wd"
+	DistributionCWD = "cwd"
+	DistributionMWD = "mwd"
+	DistributionLD  = "ld"
+	DistributionFP  = "fp"
+)
+
+// Field types
+const (
+	FieldTypeFloat = "float"
+	FieldTypeInt   = "int"
+)
+
+// UseCase is a use case declared in a YAML file: a number of entities with
+// the same tags, each reporting the same measurements every log interval.
+//
+// For example:
+//
+//	entities:
+//	  count: 100
+//	  tags:
+//	    - key: sensor
+//	      format: sensor_%d
+//	    - key: site
+//	      values: [north, south]
+//	measurements:
+//	  - name: climate
+//	    fields:
+//	      - name: temperature
+//	        distribution: {type: cwd, min: -20, max: 40, step: {type: nd, mean: 0, stddev: 0.5}}
+type UseCase struct {
+	Entities     EntitySpec        `yaml:"entities"`
+	Measurements []MeasurementSpec `yaml:"measurements"`
+}
+
+// EntitySpec declares the simulated entities (e.g. hosts or trucks). When
+// Count is 0 the scale of the generator is used.
+type EntitySpec struct {
+	Count uint64    `yaml:"count"`
+	Tags  []TagSpec `yaml:"tags"`
+}
+
+// TagSpec declares a tag of the entities. The value is generated by exactly
+// one of: Format, a fmt format of the index of the entity (e.g. "host_%d"),
+// Values, a random choice, or Min and Max, a random integer in [Min, Max].
+// The values are strings, which every target supports as tags.
+type TagSpec struct {
+	Key    string   `yaml:"key"`
+	Format string   `yaml:"format"`
+	Values []string `yaml:"values"`
+	Min    *int64   `yaml:"min"`
+	Max    *int64   `yaml:"max"`
+}
+
+// MeasurementSpec declares a measurement reported by every entity.
+type MeasurementSpec struct {
+	Name   string      `yaml:"name"`
+	Fields []FieldSpec `yaml:"fields"`
+}
+
+// FieldSpec declares a field of a measurement and the distribution of its
+// values. Type is float (the default) or int.
+type FieldSpec struct {
+	Name         string            `yaml:"name"`
+	Type         string            `yaml:"type"`
+	Distribution *DistributionSpec `yaml:"distribution"`
+}
+
+// DistributionSpec declares a common.Distribution. Which parameters are used
+// depends on the type:
+//
+//	nd:  mean, stddev
+//	ud:  low, high
+//	wd:  step, state
+//	cwd: step, min, max, state (a random value in [min, max] if omitted)
+//	mwd: step, state
+//	ld:  motive, step, threshold
+//	fp:  step, precision
+type DistributionSpec struct {
+	Type      string            `yaml:"type"`
+	Mean      float64           `yaml:"mean"`
+	StdDev    float64           `yaml:"stddev"`
+	Low       float64           `yaml:"low"`
+	High      float64           `yaml:"high"`
+	Min       float64           `yaml:"min"`
+	Max       float64           `yaml:"max"`
+	State     *float64          `yaml:"state"`
+	Threshold float64           `yaml:"threshold"`
+	Precision int               `yaml:"precision"`
+	Step      *DistributionSpec `yaml:"step"`
+	Motive    *DistributionSpec `yaml:"motive"`
+}
+
+// ReadUseCase reads and validates the use case declared in a YAML file.
+func ReadUseCase(path string) (*UseCase, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read use case file: %v", err)
+	}
+	uc, err := ParseUseCase(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid use case file %s: %v", path, err)
+	}
+	return uc, nil
+}
+
+// ParseUseCase parses and validates the YAML declaration of a use case.
+func ParseUseCase(data []byte) (*UseCase, error) {
+	uc := &UseCase{}
+	if err := yaml.UnmarshalStrict(data, uc); err != nil {
+		return nil, err
+	}
+	if err := uc.Validate(); err != nil {
+		return nil, err
+	}
+	return uc, nil
+}
+
+// Validate checks that the use case is complete and that all of its names
+// are unique.
+func (uc *UseCase) Validate() error {
+	if len(uc.Entities.Tags) == 0 {
+		return fmt.Errorf("entities need at least one tag")
+	}
+	keys := make(map[string]bool)
+	for _, t := range uc.Entities.Tags {
+		if err := t.validate(); err != nil {
+			return err
+		}
+		if keys[t.Key] {
+			return fmt.Errorf("duplicate tag '%s'", t.Key)
+		}
+		keys[t.Key] = true
+	}
+
+	if len(uc.Measurements) == 0 {
+		return fmt.Errorf("at least one measurement is needed")
+	}
+	names := make(map[string]bool)
+	for _, m := range uc.Measurements {
+		if m.Name == "" {
+			return fmt.Errorf("measurement without a name")
+		}
+		if names[m.Name] {
+			return fmt.Errorf("duplicate measurement '%s'", m.Name)
+		}
+		names[m.Name] = true
+		if err := m.validate(); err != nil {
+			return fmt.Errorf("measurement '%s': %v", m.Name, err)
+		}
+	}
+	return nil
+}
+
+func (t *TagSpec) validate() error {
+	if t.Key == "" {
+		return fmt.Errorf("tag without a key")
+	}
+	generators := 0
+	if t.Format != "" {
+		generators++
+	}
+	if len(t.Values) > 0 {
+		generators++
+	}
+	if t.Min != nil || t.Max != nil {
+		if t.Min == nil || t.Max == nil || *t.Min > *t.Max {
+			return fmt.Errorf("tag '%s': min and max must both be set, with min <= max", t.Key)
+		}
+		generators++
+	}
+	if generators != 1 {
+		return fmt.Errorf("tag '%s' must have exactly one of format, values or min and max", t.Key)
+	}
+	return nil
+}
+
+// value generates the value of the tag for the entity with the given index
+func (t *TagSpec) value(i int) interface{} {
+	switch {
+	case t.Format != "":
+		return fmt.Sprintf(t.Format, i)
+	case len(t.Values) > 0:
+		return common.RandomStringSliceChoice(t.Values)
+	default:
+		return strconv.FormatInt(*t.Min+rand.Int63n(*t.Max-*t.Min+1), 10)
+	}
+}
+
+func (m *MeasurementSpec) validate() error {
+	if len(m.Fields) == 0 {
+		return fmt.Errorf("at least one field is needed")
+	}
+	names := make(map[string]bool)
+	for _, f := range m.Fields {
+		if f.Name == "" {
+			return fmt.Errorf("field without a name")
+		}
+		if names[f.Name] {
+			return fmt.Errorf("duplicate field '%s'", f.Name)
+		}
+		names[f.Name] = true
+		if f.Type != "" && f.Type != FieldTypeFloat && f.Type != FieldTypeInt {
+			return fmt.Errorf("field '%s': unknown type '%s', must be %s or %s", f.Name, f.Type, FieldTypeFloat, FieldTypeInt)
+		}
+		if f.Distribution == nil {
+			return fmt.Errorf("field '%s' has no distribution", f.Name)
+		}
+		if err := f.Distribution.validate(); err != nil {
+			return fmt.Errorf("field '%s': %v", f.Name, err)
+		}
+	}
+	return nil
+}
+
+func (d *DistributionSpec) validate() error {
+	switch d.Type {
+	case DistributionND:
+		if d.StdDev < 0 {
+			return fmt.Errorf("nd: stddev must not be negative")
+		}
+		return nil
+	case DistributionUD:
+		if d.Low > d.High {
+			return fmt.Errorf("ud: low must not be greater than high")
+		}
+		return nil
+	case DistributionCWD:
+		if d.Min > d.Max {
+			return fmt.Errorf("cwd: min must not be greater than max")
+		}
+	case DistributionLD:
+		if d.Motive == nil {
+			return fmt.Errorf("ld: no motive distribution")
+		}
+		if err := d.Motive.validate(); err != nil {
+			return err
+		}
+	case DistributionWD, DistributionMWD, DistributionFP:
+	case "":
+		return fmt.Errorf("distribution without a type")
+	default:
+		return fmt.Errorf("unknown distribution type '%s', must be nd, ud, wd, cwd, mwd, ld or fp", d.Type)
+	}
+	if d.Step == nil {
+		return fmt.Errorf("%s: no step distribution", d.Type)
+	}
+	return d.Step.validate()
+}
+
+// build creates a new distribution from a valid DistributionSpec
+func (d *DistributionSpec) build() common.Distribution {
+	state := 0.0
+	if d.State != nil {
+		state = *d.State
+	}
+	switch d.Type {
+	case DistributionND:
+		return common.ND(d.Mean, d.StdDev)
+	case DistributionUD:
+		return common.UD(d.Low, d.High)
+	case DistributionWD:
+		return common.WD(d.Step.build(), state)
+	case DistributionCWD:
+		if d.State == nil {
+			state = d.Min + rand.Float64()*(d.Max-d.Min)
+		}
+		return common.CWD(d.Step.build(), d.Min, d.Max, state)
+	case DistributionMWD:
+		return common.MWD(d.Step.build(), state)
+	case DistributionLD:
+		return common.LD(d.Motive.build(), d.Step.build(), d.Threshold)
+	case DistributionFP:
+		return common.FP(d.Step.build(), d.Precision)
+	}
+	panic(fmt.Sprintf("unknown distribution type '%s'", d.Type))
+}
@@ -0,0 +1,184 @@
+package custom
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+const testUseCase = `
+entities:
+  count: 3
+  tags:
+    - key: sensor
+      format: sensor_%d
+    - key: site
+      values: [north, south]
+    - key: floor
+      min: 1
+      max: 3
+measurements:
+  - name: climate
+    fields:
+      - name: temperature
+        distribution:
+          type: fp
+          precision: 1
+          step: {type: cwd, min: -20, max: 40, step: {type: nd, mean: 0, stddev: 0.5}}
+      - name: humidity
+        type: int
+        distribution: {type: cwd, min: 0, max: 100, state: 50, step: {type: ud, low: -1, high: 1}}
+  - name: power
+    fields:
+      - name: energy
+        distribution: {type: mwd, step: {type: nd, mean: 5, stddev: 1}}
+      - name: offset
+        distribution: {type: wd, state: 10, step: {type: ud, low: -1, high: 1}}
+      - name: mode
+        distribution:
+          type: ld
+          threshold: 0.9
+          motive: {type: ud, low: 0, high: 1}
+          step: {type: ud, low: 0, high: 3}
+`
+
+func TestParseUseCase(t *testing.T) {
+	uc, err := ParseUseCase([]byte(testUseCase))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uc.Entities.Count != 3 || len(uc.Entities.Tags) != 3 || len(uc.Measurements) != 2 {
+		t.Fatalf("wrong use case: %+v", uc)
+	}
+	if f := uc.Measurements[0].Fields[1]; f.Name != "humidity" || f.Type != FieldTypeInt || *f.Distribution.State != 50 {
+		t.Errorf("wrong field: %+v", f)
+	}
+
+	wantTypes := []common.Distribution{
+		&common.FloatPrecision{},
+		&common.ClampedRandomWalkDistribution{},
+		&common.MonotonicRandomWalkDistribution{},
+		&common.RandomWalkDistribution{},
+		&common.LazyDistribution{},
+	}
+	var got []common.Distribution
+	for _, m := range uc.Measurements {
+		for _, f := range m.Fields {
+			got = append(got, f.Distribution.build())
+		}
+	}
+	for i, want := range wantTypes {
+		if reflect.TypeOf(got[i]) != reflect.TypeOf(want) {
+			t.Errorf("distribution %d: got %T want %T", i, got[i], want)
+		}
+	}
+	cwd := got[1].(*common.ClampedRandomWalkDistribution)
+	if cwd.State != 50 || cwd.Min != 0 || cwd.Max != 100 {
+		t.Errorf("wrong cwd: %+v", cwd)
+	}
+}
+
+func TestParseUseCaseErrors(t *testing.T) {
+	const measurements = `
+measurements:
+  - name: m
+    fields:
+      - name: f
+        distribution: {type: nd, stddev: 1}
+`
+	const tags = `
+entities:
+  tags:
+    - key: t
+      format: t_%d
+`
+	cases := []struct {
+		desc    string
+		yaml    string
+		wantErr string
+	}{
+		{desc: "no tags", yaml: measurements, wantErr: "at least one tag"},
+		{desc: "no measurements", yaml: tags, wantErr: "at least one measurement"},
+		{desc: "unknown key", yaml: tags + measurements + "extra: 1\n", wantErr: "field extra not found"},
+		{
+			desc:    "two tag generators",
+			yaml:    "entities:\n  tags:\n    - {key: t, format: t_%d, values: [a]}\n" + measurements,
+			wantErr: "exactly one of format, values or min and max",
+		},
+		{
+			desc:    "min without max",
+			yaml:    "entities:\n  tags:\n    - {key: t, min: 1}\n" + measurements,
+			wantErr: "min and max must both be set",
+		},
+		{
+			desc:    "duplicate tag",
+			yaml:    "entities:\n  tags:\n    - {key: t, format: a}\n    - {key: t, format: b}\n" + measurements,
+			wantErr: "duplicate tag 't'",
+		},
+		{
+			desc:    "duplicate field",
+			yaml:    tags + "measurements:\n  - name: m\n    fields:\n      - {name: f, distribution: {type: nd}}\n      - {name: f, distribution: {type: nd}}\n",
+			wantErr: "measurement 'm': duplicate field 'f'",
+		},
+		{
+			desc:    "no distribution",
+			yaml:    tags + "measurements:\n  - name: m\n    fields:\n      - {name: f}\n",
+			wantErr: "field 'f' has no distribution",
+		},
+		{
+			desc:    "unknown field type",
+			yaml:    tags + "measurements:\n  - name: m\n    fields:\n      - {name: f, type: bool, distribution: {type: nd}}\n",
+			wantErr: "unknown type 'bool'",
+		},
+		{
+			desc:    "unknown distribution",
+			yaml:    tags + "measurements:\n  - name: m\n    fields:\n      - {name: f, distribution: {type: zipf}}\n",
+			wantErr: "unknown distribution type 'zipf'",
+		},
+		{
+			desc:    "no step",
+			yaml:    tags + "measurements:\n  - name: m\n    fields:\n      - {name: f, distribution: {type: cwd, max: 1}}\n",
+			wantErr: "cwd: no step distribution",
+		},
+		{
+			desc:    "invalid step",
+			yaml:    tags + "measurements:\n  - name: m\n    fields:\n      - {name: f, distribution: {type: wd, step: {type: ud, low: 2, high: 1}}}\n",
+			wantErr: "ud: low must not be greater than high",
+		},
+		{
+			desc:    "no motive",
+			yaml:    tags + "measurements:\n  - name: m\n    fields:\n      - {name: f, distribution: {type: ld, step: {type: nd}}}\n",
+			wantErr: "ld: no motive distribution",
+		},
+	}
+	for _, c := range cases {
+		_, err := ParseUseCase([]byte(c.yaml))
+		if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+			t.Errorf("%s: got error %v want %s", c.desc, err, c.wantErr)
+		}
+	}
+}
+
+func TestReadUseCase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "custom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "usecase.yaml")
+	if err = ioutil.WriteFile(path, []byte(testUseCase), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ReadUseCase(path); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err = ReadUseCase(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Errorf("unexpected lack of error for a missing file")
+	}
+}
@@ -57,6 +57,63 @@ type commonDevopsSimulator struct {
 	timestampStart time.Time
 	timestampEnd   time.Time
 	interval       time.Duration
+
+	// ids are the indexes of the hosts in their config when the simulator
+	// is a shard of it, which has hostCount hosts in all
+	ids       []uint64
+	hostCount uint64
+}
+
+// newShardSimulators creates the hosts of the config with newHost and deals
+// them to the shards in turn, at most one per host. Each shard is returned
+// with its hosts and with maxPoints set to the points of a host.
+func newShardSimulators(c commonDevopsSimulatorConfig, interval time.Duration, rands []common.Rand, hostPoints uint64, newHost func(i int) Host) []*commonDevopsSimulator {
+	shards := uint64(len(rands))
+	if c.HostCount < shards {
+		shards = c.HostCount
+	}
+	epochs := calculateEpochs(c, interval)
+	sims := make([]*commonDevopsSimulator, shards)
+	for i := range sims {
+		sims[i] = &commonDevopsSimulator{
+			maxPoints:      hostPoints,
+			epochs:         epochs,
+			epochHosts:     c.InitHostCount,
+			initHosts:      c.InitHostCount,
+			timestampStart: c.Start,
+			timestampEnd:   c.End,
+			interval:       interval,
+			ids:            []uint64{},
+			hostCount:      c.HostCount,
+		}
+	}
+	for i := uint64(0); i < c.HostCount; i++ {
+		sim := sims[i%shards]
+		common.WithRand(rands[i%shards], func() {
+			sim.hosts = append(sim.hosts, newHost(int(i)))
+		})
+		sim.ids = append(sim.ids, i)
+	}
+	for _, sim := range sims {
+		sim.maxPoints *= uint64(len(sim.hosts))
+	}
+	return sims
+}
+
+// hostID returns the index of the i-th host in the config.
+func (s *commonDevopsSimulator) hostID(i uint64) uint64 {
+	if s.ids == nil {
+		return i
+	}
+	return s.ids[i]
+}
+
+// totalHosts returns the number of hosts of the config.
+func (s *commonDevopsSimulator) totalHosts() uint64 {
+	if s.ids == nil {
+		return uint64(len(s.hosts))
+	}
+	return s.hostCount
 }
 
 // Finished tells whether we have simulated all the necessary points
@@ -128,7 +185,7 @@ func (s *commonDevopsSimulator) populatePoint(p *data.Point, measureIdx int) boo
 	// Populate measurement-specific tags and fields:
 	host.SimulatedMeasurements[measureIdx].ToPoint(p)
 
-	ret := s.hostIndex < s.epochHosts
+	ret := s.hostID(s.hostIndex) < s.epochHosts
 	s.madePoints++
 	s.hostIndex++
 	return ret
@@ -145,6 +202,6 @@ func (s *commonDevopsSimulator) populatePoint(p *data.Point, measureIdx int) boo
 // we check whether the point should be recorded by the calling process.
 func (s *commonDevopsSimulator) adjustNumHostsForEpoch() {
 	s.epoch++
-	missingScale := float64(uint64(len(s.hosts)) - s.initHosts)
+	missingScale := float64(s.totalHosts() - s.initHosts)
 	s.epochHosts = s.initHosts + uint64(missingScale*float64(s.epoch)/float64(s.epochs-1))
 }
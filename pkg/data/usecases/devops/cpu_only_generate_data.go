@@ -75,3 +75,15 @@ func (c *CPUOnlySimulatorConfig) NewSimulator(interval time.Duration, limit uint
 
 	return sim
 }
+
+// NewShardSimulators produces the shard Simulators of the config, the hosts
+// are dealt to the shards in turn.
+func (c *CPUOnlySimulatorConfig) NewShardSimulators(interval time.Duration, rands []common.Rand) []common.Simulator {
+	epochs := calculateEpochs(commonDevopsSimulatorConfig(*c), interval)
+	var sims []common.Simulator
+	newHost := func(i int) Host { return c.HostConstructor(NewHostCtx(i, c.Start)) }
+	for _, s := range newShardSimulators(commonDevopsSimulatorConfig(*c), interval, rands, epochs, newHost) {
+		sims = append(sims, &CPUOnlySimulator{s})
+	}
+	return sims
+}
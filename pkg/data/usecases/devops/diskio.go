@@ -19,18 +19,18 @@ var (
 	// Reuse NormalDistributions as arguments to other distributions. This is
 	// safe to do because the higher-level distribution advances the ND and
 	// immediately uses its value and saves the state
-	opsND   = common.ND(50, 1)
-	bytesND = common.ND(100, 1)
-	timeND  = common.ND(5, 1)
+	opsND   = common.Shared(func() common.Distribution { return common.ND(50, 1) })
+	bytesND = common.Shared(func() common.Distribution { return common.ND(100, 1) })
+	timeND  = common.Shared(func() common.Distribution { return common.ND(5, 1) })
 
 	diskIOFields = []common.LabeledDistributionMaker{
-		{Label: []byte("reads"), DistributionMaker: func() common.Distribution { return common.MWD(opsND, 0) }},
-		{Label: []byte("writes"), DistributionMaker: func() common.Distribution { return common.MWD(opsND, 0) }},
-		{Label: []byte("read_bytes"), DistributionMaker: func() common.Distribution { return common.MWD(bytesND, 0) }},
-		{Label: []byte("write_bytes"), DistributionMaker: func() common.Distribution { return common.MWD(bytesND, 0) }},
-		{Label: []byte("read_time"), DistributionMaker: func() common.Distribution { return common.MWD(timeND, 0) }},
-		{Label: []byte("write_time"), DistributionMaker: func() common.Distribution { return common.MWD(timeND, 0) }},
-		{Label: []byte("io_time"), DistributionMaker: func() common.Distribution { return common.MWD(timeND, 0) }},
+		{Label: []byte("reads"), DistributionMaker: func() common.Distribution { return common.MWD(opsND.Current(), 0) }},
+		{Label: []byte("writes"), DistributionMaker: func() common.Distribution { return common.MWD(opsND.Current(), 0) }},
+		{Label: []byte("read_bytes"), DistributionMaker: func() common.Distribution { return common.MWD(bytesND.Current(), 0) }},
+		{Label: []byte("write_bytes"), DistributionMaker: func() common.Distribution { return common.MWD(bytesND.Current(), 0) }},
+		{Label: []byte("read_time"), DistributionMaker: func() common.Distribution { return common.MWD(timeND.Current(), 0) }},
+		{Label: []byte("write_time"), DistributionMaker: func() common.Distribution { return common.MWD(timeND.Current(), 0) }},
+		{Label: []byte("io_time"), DistributionMaker: func() common.Distribution { return common.MWD(timeND.Current(), 0) }},
 	}
 )
 
@@ -95,3 +95,16 @@ func (d *DevopsSimulatorConfig) NewSimulator(interval time.Duration, limit uint6
 
 	return dg
 }
+
+// NewShardSimulators produces the shard Simulators of the config, the hosts
+// are dealt to the shards in turn.
+func (d *DevopsSimulatorConfig) NewShardSimulators(interval time.Duration, rands []common.Rand) []common.Simulator {
+	epochs := calculateEpochs(commonDevopsSimulatorConfig(*d), interval)
+	var sims []common.Simulator
+	newHost := func(i int) Host { return d.HostConstructor(NewHostCtx(i, d.Start)) }
+	for _, s := range newShardSimulators(commonDevopsSimulatorConfig(*d), interval, rands, epochs, newHost) {
+		s.maxPoints *= uint64(len(s.hosts[0].SimulatedMeasurements))
+		sims = append(sims, &DevopsSimulator{commonDevopsSimulator: s})
+	}
+	return sims
+}
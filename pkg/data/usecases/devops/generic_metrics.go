@@ -12,7 +12,7 @@ import (
 var (
 	labelGenericMetrics                                   = []byte("generic_metrics")
 	genericMetricFields []common.LabeledDistributionMaker = nil
-	metricND                                              = common.ND(0.0, 1.0)
+	metricND                                              = common.Shared(func() common.Distribution { return common.ND(0.0, 1.0) })
 	zipfRandSeed                                          = int64(1234)
 )
 
@@ -25,7 +25,7 @@ func initGenericMetricFields(size uint64) {
 	if genericMetricFields == nil {
 		genericMetricFields = make([]common.LabeledDistributionMaker, size)
 		for i := range genericMetricFields {
-			genericMetricFields[i] = common.LabeledDistributionMaker{Label: []byte(fmt.Sprintf("metric_%d", i)), DistributionMaker: func() common.Distribution { return common.CWD(metricND, 0.0, 1000, rand.Float64()*1000) }}
+			genericMetricFields[i] = common.LabeledDistributionMaker{Label: []byte(fmt.Sprintf("metric_%d", i)), DistributionMaker: func() common.Distribution { return common.CWD(metricND.Current(), 0.0, 1000, rand.Float64()*1000) }}
 		}
 	}
 }
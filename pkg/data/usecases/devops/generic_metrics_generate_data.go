@@ -57,6 +57,23 @@ func (c *GenericMetricsSimulatorConfig) NewSimulator(interval time.Duration, lim
 	return dg
 }
 
+// NewShardSimulators produces the shard Simulators of the config, the hosts
+// are dealt to the shards in turn.
+func (c *GenericMetricsSimulatorConfig) NewShardSimulators(interval time.Duration, rands []common.Rand) []common.Simulator {
+	initGenericMetricFields(c.MaxMetricCount)
+	hostMetricCount := generateHostMetricCount(c.HostCount, c.MaxMetricCount)
+	epochs := calculateEpochs(commonDevopsSimulatorConfig(*c.DevopsSimulatorConfig), interval)
+	epochsToLive := generateHostEpochsToLive(c.HostCount, epochs)
+	newHost := func(i int) Host {
+		return c.HostConstructor(&HostContext{i, c.Start, hostMetricCount[i], epochsToLive[i]})
+	}
+	var sims []common.Simulator
+	for _, s := range newShardSimulators(commonDevopsSimulatorConfig(*c.DevopsSimulatorConfig), interval, rands, epochs, newHost) {
+		sims = append(sims, &GenericMetricsSimulator{s})
+	}
+	return sims
+}
+
 // Fields returns a map of subsystems to metrics collected
 // Since each host has different number of fields (we use zipf distribution to assign # fields) we search
 // for the host with the max number of fields
@@ -93,7 +110,7 @@ func (gms *GenericMetricsSimulator) Next(p *data.Point) bool {
 		gms.adjustNumHostsForEpoch()
 	}
 
-	if gms.hostIndex < gms.epochHosts {
+	if gms.hostID(gms.hostIndex) < gms.epochHosts {
 		host := &gms.hosts[gms.hostIndex]
 		if host.StartEpoch == math.MaxUint64 {
 			// mark the start time of the host
@@ -14,14 +14,14 @@ var (
 	// Reuse NormalDistributions as arguments to other distributions. This is
 	// safe to do because the higher-level distribution advances the ND and
 	// immediately uses its value and saves the state
-	kernelND = common.ND(5, 1)
+	kernelND = common.Shared(func() common.Distribution { return common.ND(5, 1) })
 
 	kernelFields = []common.LabeledDistributionMaker{
-		{Label: []byte("interrupts"), DistributionMaker: func() common.Distribution { return common.MWD(kernelND, 0) }},
-		{Label: []byte("context_switches"), DistributionMaker: func() common.Distribution { return common.MWD(kernelND, 0) }},
-		{Label: []byte("processes_forked"), DistributionMaker: func() common.Distribution { return common.MWD(kernelND, 0) }},
-		{Label: []byte("disk_pages_in"), DistributionMaker: func() common.Distribution { return common.MWD(kernelND, 0) }},
-		{Label: []byte("disk_pages_out"), DistributionMaker: func() common.Distribution { return common.MWD(kernelND, 0) }},
+		{Label: []byte("interrupts"), DistributionMaker: func() common.Distribution { return common.MWD(kernelND.Current(), 0) }},
+		{Label: []byte("context_switches"), DistributionMaker: func() common.Distribution { return common.MWD(kernelND.Current(), 0) }},
+		{Label: []byte("processes_forked"), DistributionMaker: func() common.Distribution { return common.MWD(kernelND.Current(), 0) }},
+		{Label: []byte("disk_pages_in"), DistributionMaker: func() common.Distribution { return common.MWD(kernelND.Current(), 0) }},
+		{Label: []byte("disk_pages_out"), DistributionMaker: func() common.Distribution { return common.MWD(kernelND.Current(), 0) }},
 	}
 )
 
@@ -15,18 +15,18 @@ var (
 	// Reuse NormalDistributions as arguments to other distributions. This is
 	// safe to do because the higher-level distribution advances the ND and
 	// immediately uses its value and saves the state
-	highND = common.ND(50, 1)
-	lowND  = common.ND(5, 1)
+	highND = common.Shared(func() common.Distribution { return common.ND(50, 1) })
+	lowND  = common.Shared(func() common.Distribution { return common.ND(5, 1) })
 
 	netFields = []common.LabeledDistributionMaker{
-		{Label: []byte("bytes_sent"), DistributionMaker: func() common.Distribution { return common.MWD(highND, 0) }},
-		{Label: []byte("bytes_recv"), DistributionMaker: func() common.Distribution { return common.MWD(highND, 0) }},
-		{Label: []byte("packets_sent"), DistributionMaker: func() common.Distribution { return common.MWD(highND, 0) }},
-		{Label: []byte("packets_recv"), DistributionMaker: func() common.Distribution { return common.MWD(highND, 0) }},
-		{Label: []byte("err_in"), DistributionMaker: func() common.Distribution { return common.MWD(lowND, 0) }},
-		{Label: []byte("err_out"), DistributionMaker: func() common.Distribution { return common.MWD(lowND, 0) }},
-		{Label: []byte("drop_in"), DistributionMaker: func() common.Distribution { return common.MWD(lowND, 0) }},
-		{Label: []byte("drop_out"), DistributionMaker: func() common.Distribution { return common.MWD(lowND, 0) }},
+		{Label: []byte("bytes_sent"), DistributionMaker: func() common.Distribution { return common.MWD(highND.Current(), 0) }},
+		{Label: []byte("bytes_recv"), DistributionMaker: func() common.Distribution { return common.MWD(highND.Current(), 0) }},
+		{Label: []byte("packets_sent"), DistributionMaker: func() common.Distribution { return common.MWD(highND.Current(), 0) }},
+		{Label: []byte("packets_recv"), DistributionMaker: func() common.Distribution { return common.MWD(highND.Current(), 0) }},
+		{Label: []byte("err_in"), DistributionMaker: func() common.Distribution { return common.MWD(lowND.Current(), 0) }},
+		{Label: []byte("err_out"), DistributionMaker: func() common.Distribution { return common.MWD(lowND.Current(), 0) }},
+		{Label: []byte("drop_in"), DistributionMaker: func() common.Distribution { return common.MWD(lowND.Current(), 0) }},
+		{Label: []byte("drop_out"), DistributionMaker: func() common.Distribution { return common.MWD(lowND.Current(), 0) }},
 	}
 )
 
@@ -17,16 +17,16 @@ var (
 	// Reuse NormalDistributions as arguments to other distributions. This is
 	// safe to do because the higher-level distribution advances the ND and
 	// immediately uses its value and saves the state
-	nginxND = common.ND(5, 1)
+	nginxND = common.Shared(func() common.Distribution { return common.ND(5, 1) })
 
 	nginxFields = []common.LabeledDistributionMaker{
-		{Label: []byte("accepts"), DistributionMaker: func() common.Distribution { return common.MWD(nginxND, 0) }},
-		{Label: []byte("active"), DistributionMaker: func() common.Distribution { return common.CWD(nginxND, 0, 100, 0) }},
-		{Label: []byte("handled"), DistributionMaker: func() common.Distribution { return common.MWD(nginxND, 0) }},
-		{Label: []byte("reading"), DistributionMaker: func() common.Distribution { return common.CWD(nginxND, 0, 100, 0) }},
-		{Label: []byte("requests"), DistributionMaker: func() common.Distribution { return common.MWD(nginxND, 0) }},
-		{Label: []byte("waiting"), DistributionMaker: func() common.Distribution { return common.CWD(nginxND, 0, 100, 0) }},
-		{Label: []byte("writing"), DistributionMaker: func() common.Distribution { return common.CWD(nginxND, 0, 100, 0) }},
+		{Label: []byte("accepts"), DistributionMaker: func() common.Distribution { return common.MWD(nginxND.Current(), 0) }},
+		{Label: []byte("active"), DistributionMaker: func() common.Distribution { return common.CWD(nginxND.Current(), 0, 100, 0) }},
+		{Label: []byte("handled"), DistributionMaker: func() common.Distribution { return common.MWD(nginxND.Current(), 0) }},
+		{Label: []byte("reading"), DistributionMaker: func() common.Distribution { return common.CWD(nginxND.Current(), 0, 100, 0) }},
+		{Label: []byte("requests"), DistributionMaker: func() common.Distribution { return common.MWD(nginxND.Current(), 0) }},
+		{Label: []byte("waiting"), DistributionMaker: func() common.Distribution { return common.CWD(nginxND.Current(), 0, 100, 0) }},
+		{Label: []byte("writing"), DistributionMaker: func() common.Distribution { return common.CWD(nginxND.Current(), 0, 100, 0) }},
 	}
 )
 
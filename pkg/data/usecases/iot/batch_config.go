@@ -1,21 +1,7 @@
 package iot
 
-import "math/rand"
-
-var (
-	// Batch chances.
-	bMissingChance        = 0.01
-	bOutOfOrderChance     = 0.05
-	bInsertPreviousChance = 0.5
-
-	// Entry chances.
-	eMissingChance        = 0.1
-	eOutOfOrderChance     = 0.3
-	eInsertPreviousChance = 0.5
-
-	// Zero values.
-	zeroTagChance   = 0.01
-	zeroFieldChance = 0.1
+import (
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
 )
 
 type batchConfig struct {
@@ -32,9 +18,10 @@ type batchConfig struct {
 	OutOfOrderEntries   map[int]bool
 }
 
-func newBatchConfig(outOfOrderBatchCount, outOfOrderEntryCount, fieldCount, tagCount int) *batchConfig {
+// newBatchConfig draws the configuration of a batch from the chances c, with rand.
+func newBatchConfig(rand common.Rand, c *common.IoTChances, outOfOrderBatchCount, outOfOrderEntryCount, fieldCount, tagCount int) *batchConfig {
 
-	batchMissing := rand.Float64() < bMissingChance
+	batchMissing := rand.Float64() < c.BatchMissing
 
 	if batchMissing {
 		return &batchConfig{
@@ -42,11 +29,11 @@ func newBatchConfig(outOfOrderBatchCount, outOfOrderEntryCount, fieldCount, tagC
 		}
 	}
 
-	batchOutOfOrder := rand.Float64() < bOutOfOrderChance
+	batchOutOfOrder := rand.Float64() < c.BatchOutOfOrder
 
 	batchInsertPrevious := false
 	if outOfOrderBatchCount > 0 {
-		batchInsertPrevious = rand.Float64() < bInsertPreviousChance
+		batchInsertPrevious = rand.Float64() < c.BatchInsertPrevious
 	}
 
 	zeroFields := make(map[int]int)
@@ -56,26 +43,26 @@ func newBatchConfig(outOfOrderBatchCount, outOfOrderEntryCount, fieldCount, tagC
 	outOfOrderEntries := make(map[int]bool)
 
 	for i := 0; i < defaultBatchSize; i++ {
-		if outOfOrderEntryCount > 0 && rand.Float64() < eInsertPreviousChance {
+		if outOfOrderEntryCount > 0 && rand.Float64() < c.EntryInsertPrevious {
 			insertPreviousEntry[i] = true
 			outOfOrderEntryCount--
 		}
 
-		if rand.Float64() < eMissingChance {
+		if rand.Float64() < c.EntryMissing {
 			missingEntries[i] = true
 			// Since the entry is missing, no point in setting zero values or making it out-of-order.
 			continue
 		}
 
-		if fieldCount > 0 && rand.Float64() < zeroFieldChance {
+		if fieldCount > 0 && rand.Float64() < c.ZeroField {
 			zeroFields[i] = rand.Intn(fieldCount)
 		}
 
-		if tagCount > 0 && rand.Float64() < zeroTagChance {
+		if tagCount > 0 && rand.Float64() < c.ZeroTag {
 			zeroTags[i] = rand.Intn(tagCount)
 		}
 
-		if rand.Float64() < eOutOfOrderChance {
+		if rand.Float64() < c.EntryOutOfOrder {
 			outOfOrderEntries[i] = true
 		}
 	}
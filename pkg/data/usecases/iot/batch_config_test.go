@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
 )
 
 var (
@@ -21,7 +22,7 @@ func TestNewBatchConfig(t *testing.T) {
 		batchRuns[i] = make([]*batchConfig, numberOfBatches)
 
 		for j := 0; j < numberOfBatches; j++ {
-			batchRuns[i][j] = newBatchConfig(j, j, j+5, j+5)
+			batchRuns[i][j] = newBatchConfig(common.CurrentRand(), &common.DefaultIoTChances, j, j, j+5, j+5)
 		}
 	}
 
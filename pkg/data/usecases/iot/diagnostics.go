@@ -17,17 +17,17 @@ var (
 	labelFuelState   = []byte("fuel_state")
 	labelCurrentLoad = []byte("current_load")
 	labelStatus      = []byte("status")
-	fuelUD           = common.UD(-0.001, 0)
-	loadUD           = common.UD(0, maxLoad)
-	loadSaddleUD     = common.UD(0, 1)
-	statusND         = common.ND(0, 1)
+	fuelUD           = common.Shared(func() common.Distribution { return common.UD(-0.001, 0) })
+	loadUD           = common.Shared(func() common.Distribution { return common.UD(0, maxLoad) })
+	loadSaddleUD     = common.Shared(func() common.Distribution { return common.UD(0, 1) })
+	statusND         = common.Shared(func() common.Distribution { return common.ND(0, 1) })
 
 	diagnosticsFields = []common.LabeledDistributionMaker{
 		{
 			Label: labelFuelState,
 			DistributionMaker: func() common.Distribution {
 				return common.FP(
-					&customFuelDistribution{common.CWD(fuelUD, 0, maxFuel, maxFuel)},
+					&customFuelDistribution{common.CWD(fuelUD.Current(), 0, maxFuel, maxFuel)},
 					1,
 				)
 			},
@@ -36,7 +36,7 @@ var (
 			Label: labelCurrentLoad,
 			DistributionMaker: func() common.Distribution {
 				return common.FP(
-					common.LD(loadSaddleUD, loadUD, 1-loadChangeChance),
+					common.LD(loadSaddleUD.Current(), loadUD.Current(), 1-loadChangeChance),
 					0,
 				)
 			},
@@ -45,7 +45,7 @@ var (
 			Label: labelStatus,
 			DistributionMaker: func() common.Distribution {
 				return common.FP(
-					common.CWD(statusND, 0, 5, 0),
+					common.CWD(statusND.Current(), 0, 5, 0),
 					0,
 				)
 			},
@@ -26,17 +26,17 @@ var (
 	labelHeading         = []byte("heading")
 	labelGrade           = []byte("grade")
 	labelFuelConsumption = []byte("fuel_consumption")
-	geoStepUD            = common.UD(-0.005, 0.005)
+	geoStepUD            = common.Shared(func() common.Distribution { return common.UD(-0.005, 0.005) })
 
-	bigUD   = common.UD(-10, 10)
-	smallUD = common.UD(-5, 5)
+	bigUD   = common.Shared(func() common.Distribution { return common.UD(-10, 10) })
+	smallUD = common.Shared(func() common.Distribution { return common.UD(-5, 5) })
 
 	readingsFields = []common.LabeledDistributionMaker{
 		{
 			Label: labelLatitude,
 			DistributionMaker: func() common.Distribution {
 				return common.FP(
-					common.CWD(geoStepUD, -90.0, 90.0, rand.Float64()*maxLatitude),
+					common.CWD(geoStepUD.Current(), -90.0, 90.0, rand.Float64()*maxLatitude),
 					5,
 				)
 			},
@@ -45,7 +45,7 @@ var (
 			Label: labelLongitude,
 			DistributionMaker: func() common.Distribution {
 				return common.FP(
-					common.CWD(geoStepUD, -180, 180, rand.Float64()*maxLongitude),
+					common.CWD(geoStepUD.Current(), -180, 180, rand.Float64()*maxLongitude),
 					5,
 				)
 			},
@@ -54,7 +54,7 @@ var (
 			Label: labelElevation,
 			DistributionMaker: func() common.Distribution {
 				return common.FP(
-					common.CWD(bigUD, 0, maxElevation, rand.Float64()*500),
+					common.CWD(bigUD.Current(), 0, maxElevation, rand.Float64()*500),
 					0,
 				)
 			},
@@ -63,7 +63,7 @@ var (
 			Label: labelVelocity,
 			DistributionMaker: func() common.Distribution {
 				return common.FP(
-					common.CWD(bigUD, 0, maxVelocity, 0),
+					common.CWD(bigUD.Current(), 0, maxVelocity, 0),
 					0,
 				)
 			},
@@ -72,7 +72,7 @@ var (
 			Label: labelHeading,
 			DistributionMaker: func() common.Distribution {
 				return common.FP(
-					common.CWD(smallUD, 0, maxHeading, rand.Float64()*maxHeading),
+					common.CWD(smallUD.Current(), 0, maxHeading, rand.Float64()*maxHeading),
 					0,
 				)
 			},
@@ -81,7 +81,7 @@ var (
 			Label: labelGrade,
 			DistributionMaker: func() common.Distribution {
 				return common.FP(
-					common.CWD(smallUD, 0, maxGrade, 0),
+					common.CWD(smallUD.Current(), 0, maxGrade, 0),
 					0,
 				)
 			},
@@ -90,7 +90,7 @@ var (
 			Label: labelFuelConsumption,
 			DistributionMaker: func() common.Distribution {
 				return common.FP(
-					common.CWD(smallUD, 0, maxFuelConsumption, maxFuelConsumption/2),
+					common.CWD(smallUD.Current(), 0, maxFuelConsumption, maxFuelConsumption/2),
 					1,
 				)
 			},
@@ -13,12 +13,55 @@ const (
 
 // SimulatorConfig is used to create an IoT Simulator.
 // It fulfills the common.SimulatorConfig interface.
-type SimulatorConfig common.BaseSimulatorConfig
+type SimulatorConfig struct {
+	// Start is the beginning time for the Simulator
+	Start time.Time
+	// End is the ending time for the Simulator
+	End time.Time
+	// InitGeneratorScale is the number of Generators to start with in the first reporting period
+	InitGeneratorScale uint64
+	// GeneratorScale is the total number of Generators to have in the last reporting period
+	GeneratorScale uint64
+	// GeneratorConstructor is the function used to create a new Generator given an id number and start time
+	GeneratorConstructor func(i int, start time.Time) common.Generator
+	// Chances are the chances of the disorder in the batches of entries,
+	// nil means common.DefaultIoTChances
+	Chances *common.IoTChances
+}
 
 // NewSimulator produces an IoT Simulator with the given
 // config over the specified interval and points limit.
 func (sc *SimulatorConfig) NewSimulator(interval time.Duration, limit uint64) common.Simulator {
-	s := (*common.BaseSimulatorConfig)(sc).NewSimulator(interval, limit)
+	return sc.newSimulator(sc.baseConfig().NewSimulator(interval, limit), common.CurrentRand())
+}
+
+// NewShardSimulators produces the shard Simulators of the config, each one
+// drawing the disorder of its batches from its Rand.
+func (sc *SimulatorConfig) NewShardSimulators(interval time.Duration, rands []common.Rand) []common.Simulator {
+	sims := sc.baseConfig().NewShardSimulators(interval, rands)
+	for i, s := range sims {
+		sims[i] = sc.newSimulator(s, rands[i])
+	}
+	return sims
+}
+
+func (sc *SimulatorConfig) baseConfig() *common.BaseSimulatorConfig {
+	return &common.BaseSimulatorConfig{
+		Start:                sc.Start,
+		End:                  sc.End,
+		InitGeneratorScale:   sc.InitGeneratorScale,
+		GeneratorScale:       sc.GeneratorScale,
+		GeneratorConstructor: sc.GeneratorConstructor,
+	}
+}
+
+// newSimulator produces an IoT Simulator of the entries of s, drawing the
+// disorder of the batches from rand.
+func (sc *SimulatorConfig) newSimulator(s common.Simulator, rand common.Rand) *Simulator {
+	chances := sc.Chances
+	if chances == nil {
+		chances = &common.DefaultIoTChances
+	}
 
 	maxFieldCount := 0
 
@@ -28,10 +71,14 @@ func (sc *SimulatorConfig) NewSimulator(interval time.Duration, limit uint64) co
 		}
 	}
 
+	configGenerator := func(outOfOrderBatchCount, outOfOrderEntryCount, fieldCount, tagCount int) *batchConfig {
+		return newBatchConfig(rand, chances, outOfOrderBatchCount, outOfOrderEntryCount, fieldCount, tagCount)
+	}
+
 	return &Simulator{
 		base:            s,
 		batchSize:       defaultBatchSize,
-		configGenerator: newBatchConfig,
+		configGenerator: configGenerator,
 		maxFieldCount:   maxFieldCount,
 	}
 }
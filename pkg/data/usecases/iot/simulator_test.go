@@ -578,3 +578,46 @@ func TestSimulatorTagTypes(t *testing.T) {
 		}
 	}
 }
+
+func TestSimulatorChances(t *testing.T) {
+	start := time.Unix(1451606400, 0)
+	sc := &SimulatorConfig{
+		Start: start,
+		End:   start.Add(time.Hour),
+
+		InitGeneratorScale:   2,
+		GeneratorScale:       2,
+		GeneratorConstructor: NewTruck,
+		Chances:              &common.IoTChances{},
+	}
+	s := sc.NewSimulator(10*time.Second, 0)
+
+	// without chances of disorder every entry is written in order
+	points := 0
+	var last time.Time
+	for !s.Finished() {
+		p := data.NewPoint()
+		if !s.Next(p) {
+			continue
+		}
+		if p.Timestamp().Before(last) {
+			t.Errorf("entry %d at %v written after %v", points, p.Timestamp(), last)
+		}
+		last = *p.Timestamp()
+		for _, v := range p.TagValues() {
+			if v == nil {
+				t.Errorf("entry %d has an empty tag", points)
+			}
+		}
+		for _, v := range p.FieldValues() {
+			if v == nil {
+				t.Errorf("entry %d has an empty field", points)
+			}
+		}
+		points++
+	}
+	// 360 log intervals of 2 trucks with 2 measurements
+	if points != 1440 {
+		t.Errorf("got %d entries want 1440", points)
+	}
+}
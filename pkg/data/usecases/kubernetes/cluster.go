@@ -0,0 +1,147 @@
+package kubernetes
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+const (
+	replicasPerDeployment = 4
+	podsPerNode           = 16
+	deploymentFmt         = "deployment-%d"
+	nodeFmt               = "node-%d"
+
+	// lengths of the pod-template-hash of a replica set and of the suffix
+	// of a pod name, generated from the alphabet Kubernetes uses for them
+	hashLength   = 10
+	suffixLength = 5
+	nameAlphabet = "bcdfghjklmnpqrstvwxz2456789"
+)
+
+var (
+	// NamespaceChoices contains all the namespace values for the kubernetes use case
+	NamespaceChoices = []string{
+		"default",
+		"kube-system",
+		"monitoring",
+		"ingress",
+		"checkout",
+		"payments",
+		"search",
+		"analytics",
+	}
+
+	// TagKeys are the tags of every pod, in order
+	TagKeys = [][]byte{
+		[]byte("namespace"),
+		[]byte("deployment"),
+		[]byte("replica_set"),
+		[]byte("pod"),
+		[]byte("node"),
+	}
+)
+
+// cluster is the state shared by the pods: the nodes they are scheduled on,
+// the deployments they belong to and how often they are replaced.
+type cluster struct {
+	nodes       int
+	podLifetime time.Duration
+	churnRate   float64
+	deployments []*deployment
+}
+
+func newCluster(pods uint64, podLifetime time.Duration, churnRate float64) *cluster {
+	nodes := int(pods) / podsPerNode
+	if nodes < 1 {
+		nodes = 1
+	}
+	return &cluster{
+		nodes:       nodes,
+		podLifetime: podLifetime,
+		churnRate:   churnRate,
+	}
+}
+
+// deployment returns the deployment of replica 0 to replicasPerDeployment-1
+// of the i-th pod, creating it at start for replica 0. It draws from the
+// current Rand, so the replicas have to be in the same shard.
+func (c *cluster) deployment(i int, start time.Time) *deployment {
+	id := i / replicasPerDeployment
+	if id == len(c.deployments) {
+		r := common.CurrentRand()
+		c.deployments = append(c.deployments, &deployment{
+			namespace: NamespaceChoices[r.Intn(len(NamespaceChoices))],
+			name:      fmt.Sprintf(deploymentFmt, id),
+			hash:      randomName(r, hashLength),
+			now:       start,
+			rand:      r,
+		})
+	}
+	return c.deployments[id]
+}
+
+// randomNode returns the node a new pod is scheduled on.
+func (c *cluster) randomNode(r common.Rand) string {
+	return fmt.Sprintf(nodeFmt, r.Intn(c.nodes))
+}
+
+// randomLifetime returns how long a new pod lives, exponentially distributed
+// around the pod lifetime, 0 when pods are only replaced by rollouts.
+func (c *cluster) randomLifetime(r common.Rand) time.Duration {
+	if c.podLifetime <= 0 {
+		return 0
+	}
+	return time.Duration(r.ExpFloat64() * float64(c.podLifetime))
+}
+
+// deployment is a set of replicas running the same pod template. A rollout
+// changes the template, i.e. the replica set hash, and replaces the replicas
+// one log interval after another.
+type deployment struct {
+	namespace string
+	name      string
+	hash      string
+
+	// sinceRollout is the number of ticks since the last rollout started,
+	// replica i is replaced once it reaches i
+	sinceRollout int
+
+	now  time.Time
+	rand common.Rand
+}
+
+// advance ticks the deployment until it reaches now. Each of its replicas
+// calls it, so the deployment ticks once per interval whichever replicas
+// tick first.
+func (d *deployment) advance(now time.Time, interval time.Duration, churnRate float64) {
+	for d.now.Before(now) {
+		d.now = d.now.Add(interval)
+		d.tick(interval, churnRate)
+	}
+}
+
+// tick starts a rollout with a probability given by the churn rate, the
+// number of rollouts per deployment per hour.
+func (d *deployment) tick(interval time.Duration, churnRate float64) {
+	if churnRate > 0 && d.rand.Float64() < churnRate*interval.Hours() {
+		d.hash = randomName(d.rand, hashLength)
+		d.sinceRollout = 0
+		return
+	}
+	d.sinceRollout++
+}
+
+// replicaSet returns the name of the current replica set of the deployment.
+func (d *deployment) replicaSet() string {
+	return d.name + "-" + d.hash
+}
+
+func randomName(r common.Rand, n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = nameAlphabet[r.Intn(len(nameAlphabet))]
+	}
+	return string(b)
+}
@@ -0,0 +1,80 @@
+package kubernetes
+
+import (
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+var (
+	labelContainerCPU    = []byte("container_cpu") // heap optimization
+	labelContainerMemory = []byte("container_memory")
+
+	containerCPUFields = []common.LabeledDistributionMaker{
+		{Label: []byte("usage_percent"), DistributionMaker: func() common.Distribution {
+			return common.CWD(common.ND(0.0, 1.0), 0.0, 100.0, common.CurrentRand().Float64()*100.0)
+		}},
+		{Label: []byte("throttled_percent"), DistributionMaker: func() common.Distribution {
+			return common.CWD(common.ND(0.0, 0.5), 0.0, 100.0, common.CurrentRand().Float64()*10.0)
+		}},
+	}
+
+	// memoryLimitChoices are the choices for the memory limit of a container.
+	memoryLimitChoices = []int64{256 << 20, 512 << 20, 1 << 30, 2 << 30}
+
+	containerMemoryFieldKeys = [][]byte{
+		[]byte("limit_bytes"),
+		[]byte("usage_bytes"),
+		[]byte("working_set_bytes"),
+		[]byte("rss_bytes"),
+	}
+)
+
+type containerCPUMeasurement struct {
+	*common.SubsystemMeasurement
+}
+
+func newContainerCPUMeasurement(start time.Time) *containerCPUMeasurement {
+	return &containerCPUMeasurement{common.NewSubsystemMeasurementWithDistributionMakers(start, containerCPUFields)}
+}
+
+func (m *containerCPUMeasurement) ToPoint(p *data.Point) {
+	m.SubsystemMeasurement.ToPoint(p, labelContainerCPU, containerCPUFields)
+}
+
+type containerMemoryMeasurement struct {
+	*common.SubsystemMeasurement
+	limit int64 // this doesn't change
+}
+
+// newContainerMemoryMeasurement starts a container with a usage of a tenth of
+// its limit, which then grows or shrinks within the limit.
+func newContainerMemoryMeasurement(start time.Time) *containerMemoryMeasurement {
+	r := common.CurrentRand()
+	sub := common.NewSubsystemMeasurement(start, 2)
+	limit := memoryLimitChoices[r.Intn(len(memoryLimitChoices))]
+	nd := common.ND(0.0, float64(limit)/256)
+
+	// usage bytes
+	sub.Distributions[0] = common.CWD(nd, 0.0, float64(limit), float64(limit)/10)
+	// share of the usage in the working set
+	sub.Distributions[1] = common.CWD(common.ND(0.0, 0.01), 0.5, 1.0, 0.5+r.Float64()/2)
+	return &containerMemoryMeasurement{
+		SubsystemMeasurement: sub,
+		limit:                limit,
+	}
+}
+
+func (m *containerMemoryMeasurement) ToPoint(p *data.Point) {
+	p.SetMeasurementName(labelContainerMemory)
+	p.SetTimestamp(&m.Timestamp)
+
+	usage := m.Distributions[0].Get()
+	workingSet := usage * m.Distributions[1].Get()
+
+	p.AppendField(containerMemoryFieldKeys[0], m.limit)
+	p.AppendField(containerMemoryFieldKeys[1], int64(usage))
+	p.AppendField(containerMemoryFieldKeys[2], int64(workingSet))
+	p.AppendField(containerMemoryFieldKeys[3], int64(workingSet*0.8))
+}
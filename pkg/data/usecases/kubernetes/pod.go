@@ -0,0 +1,88 @@
+package kubernetes
+
+import (
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+// Pod models a replica of a deployment. It is a common.Generator whose tags
+// change every time the pod is replaced, either at the end of its lifetime
+// or by a rollout of its deployment, creating a new series.
+type Pod struct {
+	cluster    *cluster
+	deployment *deployment
+	replica    int
+
+	// These are assigned every time the pod is replaced:
+	hash         string
+	deadline     time.Time // zero means until the next rollout
+	tags         []common.Tag
+	measurements []common.SimulatedMeasurement
+
+	now  time.Time
+	rand common.Rand
+}
+
+func (c *cluster) newPod(i int, start time.Time) common.Generator {
+	p := &Pod{
+		cluster:    c,
+		deployment: c.deployment(i, start),
+		replica:    i % replicasPerDeployment,
+		now:        start,
+		rand:       common.CurrentRand(),
+	}
+	p.replace()
+	return p
+}
+
+// replace starts a new pod in the place of the current one, from the current
+// replica set of the deployment. It draws from the current Rand, which has to
+// be the one of the pod.
+func (p *Pod) replace() {
+	d := p.deployment
+	p.hash = d.hash
+	p.deadline = time.Time{}
+	if lifetime := p.cluster.randomLifetime(p.rand); lifetime > 0 {
+		p.deadline = p.now.Add(lifetime)
+	}
+	replicaSet := d.replicaSet()
+	p.tags = []common.Tag{
+		{Key: TagKeys[0], Value: d.namespace},
+		{Key: TagKeys[1], Value: d.name},
+		{Key: TagKeys[2], Value: replicaSet},
+		{Key: TagKeys[3], Value: replicaSet + "-" + randomName(p.rand, suffixLength)},
+		{Key: TagKeys[4], Value: p.cluster.randomNode(p.rand)},
+	}
+	p.measurements = []common.SimulatedMeasurement{
+		newContainerCPUMeasurement(p.now),
+		newContainerMemoryMeasurement(p.now),
+	}
+}
+
+// Measurements returns the simulated measurements of the pod.
+func (p *Pod) Measurements() []common.SimulatedMeasurement {
+	return p.measurements
+}
+
+// Tags returns the tags of the pod.
+func (p *Pod) Tags() []common.Tag {
+	return p.tags
+}
+
+// TickAll advances the pod and its deployment, replacing the pod if the
+// deployment rolled it out or its lifetime is over.
+func (p *Pod) TickAll(d time.Duration) {
+	p.now = p.now.Add(d)
+	p.deployment.advance(p.now, d, p.cluster.churnRate)
+
+	rolledOut := p.hash != p.deployment.hash && p.deployment.sinceRollout >= p.replica
+	expired := !p.deadline.IsZero() && !p.now.Before(p.deadline)
+	if rolledOut || expired {
+		common.WithRand(p.rand, p.replace)
+		return
+	}
+	for _, m := range p.measurements {
+		m.Tick(d)
+	}
+}
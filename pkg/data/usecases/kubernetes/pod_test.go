@@ -0,0 +1,161 @@
+package kubernetes
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+func podTag(p *Pod, i int) string {
+	return p.Tags()[i].Value.(string)
+}
+
+func TestNewPod(t *testing.T) {
+	rand.Seed(123)
+	start := time.Unix(1451606400, 0)
+	c := newCluster(64, time.Hour, 0)
+	pods := make([]*Pod, 2*replicasPerDeployment)
+	for i := range pods {
+		pods[i] = c.newPod(i, start).(*Pod)
+	}
+	if len(c.deployments) != 2 || c.nodes != 4 {
+		t.Fatalf("wrong cluster: %d deployments %d nodes", len(c.deployments), c.nodes)
+	}
+
+	p := pods[replicasPerDeployment+1]
+	if p.replica != 1 || p.deployment != c.deployments[1] {
+		t.Errorf("wrong replica %d of deployment %s", p.replica, p.deployment.name)
+	}
+	if len(p.Tags()) != len(TagKeys) {
+		t.Fatalf("wrong number of tags: %d", len(p.Tags()))
+	}
+	for i, tag := range p.Tags() {
+		if string(tag.Key) != string(TagKeys[i]) {
+			t.Errorf("wrong tag key %d: got %s want %s", i, tag.Key, TagKeys[i])
+		}
+	}
+	if got := podTag(p, 1); got != "deployment-1" {
+		t.Errorf("wrong deployment: %s", got)
+	}
+	replicaSet := podTag(p, 2)
+	if replicaSet != "deployment-1-"+p.deployment.hash || len(p.deployment.hash) != hashLength {
+		t.Errorf("wrong replica set: %s", replicaSet)
+	}
+	pod := podTag(p, 3)
+	if !strings.HasPrefix(pod, replicaSet+"-") || len(pod) != len(replicaSet)+1+suffixLength {
+		t.Errorf("wrong pod name: %s", pod)
+	}
+	if !p.deadline.After(start) {
+		t.Errorf("pod with a lifetime has no deadline: %v", p.deadline)
+	}
+
+	pt := data.NewPoint()
+	p.Measurements()[1].ToPoint(pt)
+	limit := pt.GetFieldValue([]byte("limit_bytes")).(int64)
+	usage := pt.GetFieldValue([]byte("usage_bytes")).(int64)
+	workingSet := pt.GetFieldValue([]byte("working_set_bytes")).(int64)
+	if usage > limit || workingSet > usage {
+		t.Errorf("wrong memory: limit %d usage %d working set %d", limit, usage, workingSet)
+	}
+}
+
+func TestPodTickAllLifetime(t *testing.T) {
+	start := time.Unix(1451606400, 0)
+	c := newCluster(1, 0, 0)
+	p := c.newPod(0, start).(*Pod)
+	if !p.deadline.IsZero() {
+		t.Fatalf("pod without a lifetime has a deadline: %v", p.deadline)
+	}
+	p.deadline = start.Add(20 * time.Second)
+	name := podTag(p, 3)
+
+	p.TickAll(10 * time.Second)
+	if got := podTag(p, 3); got != name {
+		t.Errorf("pod replaced before its deadline: %s", got)
+	}
+	p.TickAll(10 * time.Second)
+	if got := podTag(p, 3); got == name {
+		t.Errorf("pod not replaced at its deadline")
+	}
+	if got := podTag(p, 2); got != "deployment-0-"+p.deployment.hash {
+		t.Errorf("replaced pod changed replica set: %s", got)
+	}
+	if !p.deadline.IsZero() {
+		t.Errorf("replaced pod without a lifetime has a deadline: %v", p.deadline)
+	}
+
+	// the new pod starts reporting at the time it replaced the old one
+	pt := data.NewPoint()
+	p.Measurements()[0].ToPoint(pt)
+	if got := *pt.Timestamp(); !got.Equal(start.Add(20 * time.Second)) {
+		t.Errorf("wrong timestamp of the replaced pod: %v", got)
+	}
+}
+
+func TestPodTickAllRollout(t *testing.T) {
+	start := time.Unix(1451606400, 0)
+	c := newCluster(replicasPerDeployment, 0, 0)
+	pods := make([]*Pod, replicasPerDeployment)
+	names := make([]string, replicasPerDeployment)
+	for i := range pods {
+		pods[i] = c.newPod(i, start).(*Pod)
+		names[i] = podTag(pods[i], 3)
+	}
+	oldReplicaSet := c.deployments[0].replicaSet()
+
+	// a rollout is certain in the first tick and never happens afterwards,
+	// it then replaces one replica per tick
+	c.churnRate = 1e9
+	for tick := 0; tick < replicasPerDeployment; tick++ {
+		for _, p := range pods {
+			p.TickAll(10 * time.Second)
+		}
+		c.churnRate = 0
+
+		for i, p := range pods {
+			replaced := podTag(p, 3) != names[i]
+			if want := i <= tick; replaced != want {
+				t.Errorf("tick %d: replica %d replaced: got %v want %v", tick, i, replaced, want)
+			}
+			wantReplicaSet := oldReplicaSet
+			if i <= tick {
+				wantReplicaSet = c.deployments[0].replicaSet()
+			}
+			if got := podTag(p, 2); got != wantReplicaSet {
+				t.Errorf("tick %d: replica %d has replica set %s want %s", tick, i, got, wantReplicaSet)
+			}
+		}
+	}
+	if c.deployments[0].replicaSet() == oldReplicaSet {
+		t.Errorf("rollout did not change the replica set")
+	}
+}
+
+func TestPodTickAllDeployment(t *testing.T) {
+	start := time.Unix(1451606400, 0)
+	c := newCluster(replicasPerDeployment, 0, 1e9)
+	pods := make([]*Pod, replicasPerDeployment)
+	for i := range pods {
+		pods[i] = c.newPod(i, start).(*Pod)
+	}
+	d := c.deployments[0]
+	hash := d.hash
+
+	// the deployment ticks once per interval, whichever replicas tick and
+	// in whichever order
+	pods[2].TickAll(10 * time.Second)
+	if d.hash == hash {
+		t.Errorf("deployment did not tick without its first replica")
+	}
+	c.churnRate = 0
+	pods[1].TickAll(10 * time.Second)
+	pods[3].TickAll(10 * time.Second)
+	pods[0].TickAll(10 * time.Second)
+	pods[0].TickAll(10 * time.Second)
+	if want := start.Add(20 * time.Second); !d.now.Equal(want) || d.sinceRollout != 1 {
+		t.Errorf("wrong deployment state: now %v want %v, %d ticks since the rollout", d.now, want, d.sinceRollout)
+	}
+}
@@ -0,0 +1,53 @@
+package kubernetes
+
+import (
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+// SimulatorConfig is used to create a Simulator of the kubernetes use case,
+// whose scale is the number of pods.
+// It fulfills the common.SimulatorConfig interface.
+type SimulatorConfig struct {
+	// Start is the beginning time for the Simulator
+	Start time.Time
+	// End is the ending time for the Simulator
+	End time.Time
+	// InitPodCount is the number of pods to start with in the first reporting period
+	InitPodCount uint64
+	// PodCount is the total number of pods to have in the last reporting period
+	PodCount uint64
+	// PodLifetime is the mean lifetime of a pod before it is replaced,
+	// 0 means pods are only replaced by rollouts
+	PodLifetime time.Duration
+	// ChurnRate is the number of rollouts per deployment per hour
+	ChurnRate float64
+}
+
+// baseConfig returns the config of the simulator of the pods of a new cluster.
+func (c *SimulatorConfig) baseConfig() *common.BaseSimulatorConfig {
+	cl := newCluster(c.PodCount, c.PodLifetime, c.ChurnRate)
+	return &common.BaseSimulatorConfig{
+		Start: c.Start,
+		End:   c.End,
+
+		InitGeneratorScale:   c.InitPodCount,
+		GeneratorScale:       c.PodCount,
+		GeneratorConstructor: cl.newPod,
+		ShardGroupSize:       replicasPerDeployment,
+	}
+}
+
+// NewSimulator produces a Simulator of pods with the given config over the
+// specified interval and points limit. Pods are replaced by new series as
+// they expire or get rolled out, so the number of series grows over time.
+func (c *SimulatorConfig) NewSimulator(interval time.Duration, limit uint64) common.Simulator {
+	return c.baseConfig().NewSimulator(interval, limit)
+}
+
+// NewShardSimulators produces the shard Simulators of the config. The pods of
+// a deployment share it, so the deployments are dealt to the shards.
+func (c *SimulatorConfig) NewShardSimulators(interval time.Duration, rands []common.Rand) []common.Simulator {
+	return c.baseConfig().NewShardSimulators(interval, rands)
+}
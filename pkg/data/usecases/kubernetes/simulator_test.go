@@ -0,0 +1,54 @@
+package kubernetes
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+func TestSimulator(t *testing.T) {
+	rand.Seed(123)
+	start := time.Unix(1451606400, 0)
+	conf := &SimulatorConfig{
+		Start:        start,
+		End:          start.Add(time.Hour),
+		InitPodCount: 8,
+		PodCount:     8,
+		PodLifetime:  10 * time.Minute,
+		ChurnRate:    1,
+	}
+	sim := conf.NewSimulator(10*time.Second, 0)
+
+	wantFields := map[string][]string{
+		"container_cpu":    {"usage_percent", "throttled_percent"},
+		"container_memory": {"limit_bytes", "usage_bytes", "working_set_bytes", "rss_bytes"},
+	}
+	if got := sim.Fields(); !reflect.DeepEqual(got, wantFields) {
+		t.Errorf("wrong fields: got %v want %v", got, wantFields)
+	}
+	wantTagKeys := []string{"namespace", "deployment", "replica_set", "pod", "node"}
+	if got := sim.TagKeys(); !reflect.DeepEqual(got, wantTagKeys) {
+		t.Errorf("wrong tag keys: got %v want %v", got, wantTagKeys)
+	}
+
+	points := 0
+	pods := make(map[string]bool)
+	for !sim.Finished() {
+		p := data.NewPoint()
+		if sim.Next(p) {
+			points++
+			pods[p.TagValues()[3].(string)] = true
+		}
+	}
+	// 360 log intervals of 8 pods with 2 measurements
+	if points != 5760 {
+		t.Errorf("wrong number of points: got %d want 5760", points)
+	}
+	// pods living 10 minutes on average are replaced about 6 times an hour
+	if len(pods) <= 4*8 {
+		t.Errorf("not enough churn: %d pods", len(pods))
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/data/usecases/custom"
 	"github.com/timescale/tsbs/pkg/data/usecases/devops"
 	"github.com/timescale/tsbs/pkg/data/usecases/iot"
+	"github.com/timescale/tsbs/pkg/data/usecases/kubernetes"
 	"math"
 )
 
@@ -22,6 +24,9 @@ func GetSimulatorConfig(dgc *common.DataGeneratorConfig) (common.SimulatorConfig
 	if err != nil {
 		return nil, fmt.Errorf(errCannotParseTimeFmt, dgc.TimeEnd, err)
 	}
+	if dgc.RealTime {
+		tsStart, tsEnd = common.RealTimeStart(tsStart, tsEnd)
+	}
 
 	switch dgc.Use {
 	case common.UseCaseDevops:
@@ -41,6 +46,7 @@ func GetSimulatorConfig(dgc *common.DataGeneratorConfig) (common.SimulatorConfig
 			InitGeneratorScale:   dgc.InitialScale,
 			GeneratorScale:       dgc.Scale,
 			GeneratorConstructor: iot.NewTruck,
+			Chances:              &dgc.IoTChances,
 		}
 	case common.UseCaseCPUOnly:
 		ret = &devops.CPUOnlySimulatorConfig{
@@ -61,23 +67,55 @@ func GetSimulatorConfig(dgc *common.DataGeneratorConfig) (common.SimulatorConfig
 			HostConstructor: devops.NewHostCPUSingle,
 		}
 	case common.UseCaseDevopsGeneric:
-		if dgc.InitialScale == dgc.Scale {
+		initialScale := dgc.InitialScale
+		if initialScale == dgc.Scale {
 			// if no initial scale argument given we will start with 50%. The lower bound is 1
-			dgc.InitialScale = uint64(math.Max(float64(1), float64(dgc.Scale/2)))
+			initialScale = uint64(math.Max(float64(1), float64(dgc.Scale/2)))
 		}
 		ret = &devops.GenericMetricsSimulatorConfig{
 			DevopsSimulatorConfig: &devops.DevopsSimulatorConfig{
 				Start: tsStart,
 				End:   tsEnd,
 
-				InitHostCount:   dgc.InitialScale,
+				InitHostCount:   initialScale,
 				HostCount:       dgc.Scale,
 				HostConstructor: devops.NewHostGenericMetrics,
 				MaxMetricCount:  dgc.MaxMetricCountPerHost,
 			},
 		}
+	case common.UseCaseCustom:
+		uc, err := custom.ReadUseCase(dgc.UseCaseFile)
+		if err != nil {
+			return nil, err
+		}
+		initialScale, scale := dgc.InitialScale, dgc.Scale
+		if uc.Entities.Count > 0 {
+			// the count of the file replaces the scale, the initial scale
+			// too unless it was set to a lower value
+			if initialScale == scale || initialScale > uc.Entities.Count {
+				initialScale = uc.Entities.Count
+			}
+			scale = uc.Entities.Count
+		}
+		ret = uc.SimulatorConfig(tsStart, tsEnd, initialScale, scale)
+	case common.UseCaseKubernetes:
+		ret = &kubernetes.SimulatorConfig{
+			Start: tsStart,
+			End:   tsEnd,
+
+			InitPodCount: dgc.InitialScale,
+			PodCount:     dgc.Scale,
+			PodLifetime:  dgc.PodLifetime,
+			ChurnRate:    dgc.ChurnRate,
+		}
 	default:
 		err = fmt.Errorf("unknown use case: '%s'", dgc.Use)
 	}
+	if err == nil && dgc.Use != common.UseCaseIoT && dgc.Disorder.Enabled() {
+		ret = &common.DisorderSimulatorConfig{
+			SimulatorConfig: ret,
+			Disorder:        dgc.Disorder,
+		}
+	}
 	return ret, err
 }
@@ -4,6 +4,10 @@ import (
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
 	"github.com/timescale/tsbs/pkg/data/usecases/devops"
 	"github.com/timescale/tsbs/pkg/data/usecases/iot"
+	"github.com/timescale/tsbs/pkg/data/usecases/kubernetes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
@@ -38,6 +42,12 @@ func TestGetSimulatorConfig(t *testing.T) {
 	checkType(common.UseCaseIoT, &iot.SimulatorConfig{})
 	checkType(common.UseCaseCPUOnly, &devops.CPUOnlySimulatorConfig{})
 	checkType(common.UseCaseCPUSingle, &devops.CPUOnlySimulatorConfig{})
+	checkType(common.UseCaseKubernetes, &kubernetes.SimulatorConfig{})
+
+	dgc.Disorder = common.Disorder{GapChance: 0.1}
+	checkType(common.UseCaseDevops, &common.DisorderSimulatorConfig{})
+	checkType(common.UseCaseCPUOnly, &common.DisorderSimulatorConfig{})
+	dgc.Disorder = common.Disorder{}
 
 	dgc.Use = "bogus use case"
 	_, err := GetSimulatorConfig(dgc)
@@ -45,3 +55,56 @@ func TestGetSimulatorConfig(t *testing.T) {
 		t.Errorf("unexpected lack of error for bogus use case")
 	}
 }
+
+func TestGetSimulatorConfigCustom(t *testing.T) {
+	dir, err := ioutil.TempDir("", "usecases")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "custom.yaml")
+	err = ioutil.WriteFile(file, []byte(`
+entities:
+  count: 5
+  tags:
+    - {key: sensor, format: sensor_%d}
+measurements:
+  - name: m
+    fields:
+      - {name: f, distribution: {type: nd, stddev: 1}}
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dgc := &common.DataGeneratorConfig{
+		BaseConfig: common.BaseConfig{
+			Use:       common.UseCaseCustom,
+			Scale:     1,
+			TimeStart: "2020-01-01T00:00:00Z",
+			TimeEnd:   "2020-01-01T00:00:01Z",
+		},
+		InitialScale: 1,
+		LogInterval:  defaultLogInterval,
+		UseCaseFile:  file,
+	}
+	scfg, err := GetSimulatorConfig(dgc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	bscfg, ok := scfg.(*common.BaseSimulatorConfig)
+	if !ok {
+		t.Fatalf("wrong scfg type: %T", scfg)
+	}
+	if bscfg.GeneratorScale != 5 || bscfg.InitGeneratorScale != 5 {
+		t.Errorf("the count of the file did not replace the scale: %+v", bscfg)
+	}
+	if dgc.Scale != 1 || dgc.InitialScale != 1 {
+		t.Errorf("the config was changed: scale %d initial scale %d", dgc.Scale, dgc.InitialScale)
+	}
+
+	dgc.UseCaseFile = filepath.Join(dir, "missing.yaml")
+	if _, err = GetSimulatorConfig(dgc); err == nil {
+		t.Errorf("unexpected lack of error for a missing use case file")
+	}
+}
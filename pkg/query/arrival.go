@@ -0,0 +1,59 @@
+package query
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Distributions of the time between the arrivals of the queries in open loop
+const (
+	ArrivalConstant = "constant"
+	ArrivalPoisson  = "poisson"
+)
+
+// arrivalSchedule gives the intended start times of the queries of an open
+// loop run: the queries arrive at a fixed rate, whether or not the database
+// keeps up, like the requests of independent users.
+type arrivalSchedule struct {
+	mu       sync.Mutex
+	next     time.Time
+	interval func() time.Duration
+}
+
+// newArrivalSchedule returns the schedule of rate queries per second, with a
+// constant time between them or exponentially distributed ones (Poisson arrivals)
+func newArrivalSchedule(rate float64, distribution string, seed int64) (*arrivalSchedule, error) {
+	if rate <= 0 {
+		return nil, fmt.Errorf("arrival rate must be positive, got %f", rate)
+	}
+	mean := float64(time.Second) / rate
+	s := &arrivalSchedule{}
+	switch distribution {
+	case "", ArrivalConstant:
+		s.interval = func() time.Duration { return time.Duration(mean) }
+	case ArrivalPoisson:
+		r := rand.New(rand.NewSource(seed))
+		s.interval = func() time.Duration { return time.Duration(r.ExpFloat64() * mean) }
+	default:
+		return nil, fmt.Errorf("invalid arrival distribution '%s', valid distributions: %s, %s", distribution, ArrivalConstant, ArrivalPoisson)
+	}
+	return s, nil
+}
+
+// start sets the arrival time of the first query
+func (s *arrivalSchedule) start(t time.Time) {
+	s.mu.Lock()
+	s.next = t
+	s.mu.Unlock()
+}
+
+// nextStart returns the intended start time of the next query
+func (s *arrivalSchedule) nextStart() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.next
+	s.next = s.next.Add(s.interval())
+	return t
+}
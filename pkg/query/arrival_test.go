@@ -0,0 +1,50 @@
+package query
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestArrivalScheduleConstant(t *testing.T) {
+	s, err := newArrivalSchedule(4, ArrivalConstant, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(100, 0)
+	s.start(start)
+	for i := 0; i < 3; i++ {
+		want := start.Add(time.Duration(i) * 250 * time.Millisecond)
+		if got := s.nextStart(); !got.Equal(want) {
+			t.Errorf("arrival %d: got %v want %v", i, got, want)
+		}
+	}
+}
+
+func TestArrivalSchedulePoisson(t *testing.T) {
+	s, err := newArrivalSchedule(100, ArrivalPoisson, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(100, 0)
+	s.start(start)
+	const count = 10000
+	var last time.Time
+	for i := 0; i < count; i++ {
+		last = s.nextStart()
+	}
+	// the mean time between the arrivals is 10ms
+	mean := last.Sub(start).Seconds() / (count - 1)
+	if math.Abs(mean-0.01) > 0.001 {
+		t.Errorf("wrong mean time between arrivals: got %fs want 0.01s", mean)
+	}
+}
+
+func TestNewArrivalScheduleErrors(t *testing.T) {
+	if _, err := newArrivalSchedule(0, ArrivalConstant, 1); err == nil {
+		t.Errorf("expected error for a rate of 0")
+	}
+	if _, err := newArrivalSchedule(10, "uniform", 1); err == nil {
+		t.Errorf("expected error for an invalid distribution")
+	}
+}
@@ -0,0 +1,107 @@
+package query
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/timescale/tsbs/internal/slo"
+)
+
+// queryStats are the stats of the assertions of a query run, besides the
+// percentiles
+var queryStats = map[string]bool{
+	"min": true, "mean": true, "max": true, "stddev": true,
+	"count": true, "qps": true, "errors": true, "errorRate": true, "timeouts": true,
+}
+
+// parseAssertions parses the assertions of a query run and checks their stats exist
+func parseAssertions(exprs []string) ([]slo.Assertion, error) {
+	assertions, err := slo.ParseAll(exprs)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range assertions {
+		if _, ok := slo.Percentile(a.Stat); !ok && !queryStats[a.Stat] {
+			return nil, fmt.Errorf("unknown stat '%s' in assertion '%s', must be pN (e.g. p99), min, mean, max, stddev, count, qps, errors, errorRate or timeouts", a.Stat, a.Expr)
+		}
+	}
+	return assertions, nil
+}
+
+// checkAssertions evaluates the assertions against the stats of the run that
+// took the given time and prints the results
+func (b *BenchmarkRunner) checkAssertions(took time.Duration) []slo.Result {
+	labels := b.sp.labelStats()
+	results := slo.Evaluate(b.assertions, func(a slo.Assertion) (float64, error) {
+		return b.queryStat(a, labels, took)
+	})
+	_, _ = fmt.Printf("Assertions:\n")
+	if err := slo.Print(os.Stdout, results); err != nil {
+		panic(err)
+	}
+	return results
+}
+
+// labelList returns the labels of the queries run, in the results file form
+// and sorted, to show which ones an assertion can refer to
+func labelList(labels map[string]LabelStats) string {
+	list := make([]string, 0, len(labels))
+	for l := range labels {
+		if l != LabelAllQueries {
+			list = append(list, stripRegex(l))
+		}
+	}
+	sort.Strings(list)
+	return strings.Join(list, ", ")
+}
+
+// queryStat returns the value of the stat of an assertion, for the label of
+// its argument or for all the queries. The label is either the one of the
+// summary or the one of the results file.
+func (b *BenchmarkRunner) queryStat(a slo.Assertion, labels map[string]LabelStats, took time.Duration) (float64, error) {
+	label := LabelAllQueries
+	if a.Arg != "" {
+		label = ""
+		for l := range labels {
+			if l == a.Arg || stripRegex(l) == a.Arg {
+				label = l
+				break
+			}
+		}
+		if label == "" {
+			return 0, fmt.Errorf("no queries labeled '%s', the labels are: %s", a.Arg, labelList(labels))
+		}
+	}
+	s := labels[label]
+	if p, ok := slo.Percentile(a.Stat); ok {
+		if s.Count == 0 {
+			return 0, fmt.Errorf("no successful queries labeled '%s'", label)
+		}
+		v, _ := b.sp.percentile(label, p)
+		return v, nil
+	}
+	switch a.Stat {
+	case "min":
+		return s.Min, nil
+	case "mean":
+		return s.Mean, nil
+	case "max":
+		return s.Max, nil
+	case "stddev":
+		return s.StdDev, nil
+	case "count":
+		return float64(s.Count), nil
+	case "qps":
+		return float64(s.Count) / took.Seconds(), nil
+	case "errors":
+		return float64(s.Errors), nil
+	case "errorRate":
+		return s.ErrorRate, nil
+	case "timeouts":
+		return float64(s.Timeouts), nil
+	}
+	return 0, fmt.Errorf("unknown stat '%s'", a.Stat)
+}
@@ -0,0 +1,132 @@
+package query
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/internal/slo"
+)
+
+func TestParseAssertions(t *testing.T) {
+	assertions, err := parseAssertions([]string{"p99.9(q 1)<50ms", "qps>=10", "errorRate<1%"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assertions) != 3 || assertions[0].Arg != "q 1" || assertions[2].Threshold != 0.01 {
+		t.Errorf("wrong assertions: %+v", assertions)
+	}
+	for _, expr := range []string{"metricRate>1", "p101<1", "p99"} {
+		if _, err := parseAssertions([]string{expr}); err == nil {
+			t.Errorf("%s: no error", expr)
+		}
+	}
+}
+
+func TestQueryStat(t *testing.T) {
+	sp := newStatProcessor(&statProcessorArgs{limit: new(uint64)}).(*defaultStatProcessor)
+	sp.open(5)
+	for _, v := range []float64{1, 2, 3, 4} {
+		sp.c <- GetStat().Init([]byte("cpu max, all hosts"), v)
+	}
+	sp.c <- newErrorStat([]byte("cpu max, all hosts"))
+	close(sp.c)
+	sp.process(1)
+	b := &BenchmarkRunner{sp: sp}
+	labels := sp.labelStats()
+
+	cases := []struct {
+		expr    string
+		want    float64
+		wantErr string
+	}{
+		{expr: "count==0", want: 4},
+		{expr: "count(cpu max, all hosts)==0", want: 4},
+		{expr: "count(cpu_max_all_hosts)==0", want: 4},
+		{expr: "max(cpu_max_all_hosts)==0", want: 4},
+		{expr: "p50==0", want: 2},
+		{expr: "qps==0", want: 2},
+		{expr: "errors==0", want: 1},
+		{expr: "errorRate==0", want: 0.2},
+		{expr: "timeouts==0", want: 0},
+		{expr: "p99(lastpoint)<1", wantErr: "no queries labeled 'lastpoint', the labels are: cpu_max_all_hosts"},
+	}
+	for _, c := range cases {
+		a, err := slo.Parse(c.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := b.queryStat(a, labels, 2*time.Second)
+		if c.wantErr != "" {
+			if err == nil || err.Error() != c.wantErr {
+				t.Errorf("%s: got error %v want %s", c.expr, err, c.wantErr)
+			}
+			continue
+		}
+		if err != nil || got < c.want*0.99 || got > c.want*1.01 {
+			t.Errorf("%s: got %v (%v) want %v", c.expr, got, err, c.want)
+		}
+	}
+}
+
+func TestBenchmarkRunnerRunAssertions(t *testing.T) {
+	var buf bytes.Buffer
+	err := encodeQueries(&buf, 4, func(i uint64) Query {
+		return &testQuery{HumanLabel: []byte(fmt.Sprintf("q%d", i%2))}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "assert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	runner := NewBenchmarkRunner(BenchmarkRunnerConfig{
+		Workers:     2,
+		Asserts:     []string{"p99(q0)<5ms", "count==4", "errors==0"},
+		ResultsFile: filepath.Join(dir, "results.json"),
+	})
+	runner.br = bufio.NewReader(&buf)
+	runner.Run(&testQueryPool, func() Processor { return &fixedLatencyProcessor{} })
+
+	data, err := ioutil.ReadFile(runner.ResultsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result struct {
+		Totals struct {
+			Assertions []slo.Result `json:"assertions"`
+		}
+	}
+	if err = json.Unmarshal(data, &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Totals.Assertions) != 3 {
+		t.Fatalf("wrong assertions in the results: %+v", result.Totals.Assertions)
+	}
+	for _, r := range result.Totals.Assertions {
+		if !r.Passed {
+			t.Errorf("assertion failed: %+v", r)
+		}
+	}
+}
+
+func TestBenchmarkRunnerRunPanicOnInvalidAssertion(t *testing.T) {
+	defer func() {
+		r := recover()
+		if r == nil || !strings.Contains(fmt.Sprint(r), "unknown stat 'rowRate'") {
+			t.Errorf("wrong panic: %v", r)
+		}
+	}()
+	runner := NewBenchmarkRunner(BenchmarkRunnerConfig{Workers: 1, Asserts: []string{"rowRate>1"}})
+	runner.Run(nil, nil)
+}
@@ -2,25 +2,35 @@ package query
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"runtime/pprof"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/internal/interrupt"
+	"github.com/timescale/tsbs/internal/slo"
 	"golang.org/x/time/rate"
 )
 
+// LabelAllQueries is the label of the statistics of all the queries together
+const LabelAllQueries = "all queries"
+
 const (
-	labelAllQueries  = "all queries"
 	labelColdQueries = "cold queries"
 	labelWarmQueries = "warm queries"
 
 	defaultReadSize = 4 << 20 // 4 MB
+
+	defaultPercentiles = "90,95,99,99.9"
 )
 
 // BenchmarkRunnerConfig is the configuration of the benchmark runner.
@@ -38,6 +48,41 @@ type BenchmarkRunnerConfig struct {
 	PrintInterval    uint64 `mapstructure:"print-interval"`
 	PrewarmQueries   bool   `mapstructure:"prewarm-queries"`
 	ResultsFile      string `mapstructure:"results-file"`
+	// MetricsListenAddr is the address to serve live Prometheus metrics on, disabled if empty
+	MetricsListenAddr string `mapstructure:"metrics-listen-addr"`
+	ReportFormat      string `mapstructure:"report-format"`
+	ReportFile        string `mapstructure:"report-file"`
+	// CaptureResultsFile is where the normalized results of the queries are written, disabled if empty
+	CaptureResultsFile string `mapstructure:"capture-results"`
+	CapturePrecision   int    `mapstructure:"capture-precision"`
+	// ContinueOnError counts the failed queries instead of aborting the run
+	ContinueOnError bool   `mapstructure:"continue-on-error"`
+	ErrorLogFile    string `mapstructure:"error-log"`
+	ErrorLogSample  int    `mapstructure:"error-log-sample"`
+	// QueryTimeout cancels the queries running for longer, disabled if 0
+	QueryTimeout time.Duration `mapstructure:"query-timeout"`
+	// ArrivalRate is the rate of queries per second of an open loop run, 0 runs
+	// the queries in closed loop (each worker sends a query once the previous one returned)
+	ArrivalRate         float64 `mapstructure:"arrival-rate"`
+	ArrivalDistribution string  `mapstructure:"arrival-distribution"`
+	ArrivalSeed         int64   `mapstructure:"arrival-seed"`
+	// Percentiles is the comma separated list of the latency percentiles reported per label
+	Percentiles string `mapstructure:"percentiles"`
+	// HDRLatenciesDir is the directory of the HdrHistogram log files of the labels, disabled if empty
+	HDRLatenciesDir string `mapstructure:"hdr-latencies-dir"`
+	// Duration stops sending queries once elapsed, disabled if 0
+	Duration time.Duration `mapstructure:"duration"`
+	// Loop replays the queries once they are all sent, until the duration or the limit is reached
+	Loop        bool  `mapstructure:"loop"`
+	Shuffle     bool  `mapstructure:"shuffle"`
+	ShuffleSeed int64 `mapstructure:"shuffle-seed"`
+	// InputFormat is the format of the queries read: gob (the default) or jsonl
+	InputFormat string `mapstructure:"input-format"`
+	// WorkersSweep is the comma separated list of the numbers of workers to run
+	// the queries with, one after the other, disabled if empty
+	WorkersSweep string `mapstructure:"workers-sweep"`
+	// Asserts are the assertions checked against the final stats, see package slo
+	Asserts []string `mapstructure:"assert"`
 }
 
 // AddToFlagSet adds command line flags needed by the BenchmarkRunnerConfig to the flag set.
@@ -49,12 +94,33 @@ func (c BenchmarkRunnerConfig) AddToFlagSet(fs *pflag.FlagSet) {
 	fs.Uint64("print-interval", 100, "Print timing stats to stderr after this many queries (0 to disable)")
 	fs.String("memprofile", "", "Write a memory profile to this file.")
 	fs.String("hdr-latencies", "", "Write the High Dynamic Range (HDR) Histogram of Response Latencies to this file.")
+	fs.String("hdr-latencies-dir", "", "Write one HdrHistogram log file of the latencies per query label to this directory (cold and warm queries separately with --prewarm-queries), to load into HdrHistogram plotters")
+	fs.String("percentiles", defaultPercentiles, "Comma separated list of the latency percentiles reported per label in the summary and the results file")
 	fs.Uint("workers", 1, "Number of concurrent requests to make.")
+	fs.String("workers-sweep", "", "Comma separated list of numbers of workers (e.g. '1,2,4,8,16') to run the same queries with, one after the other, instead of --workers. With --duration every level runs for that long. The results file has the query rate and latencies of every level")
 	fs.Bool("prewarm-queries", false, "Run each query twice in a row so the warm query is guaranteed to be a cache hit")
 	fs.Bool("print-responses", false, "Pretty print response bodies for correctness checking (default false).")
 	fs.Int("debug", 0, "Whether to print debug messages.")
 	fs.String("file", "", "File name to read queries from")
+	fs.String("input-format", FormatGob, "Format of the queries read: 'gob' or 'jsonl' (one JSON object per line, see tsbs_query_convert)")
 	fs.String("results-file", "", "Write the test results summary json to this file")
+	fs.String("report-format", ReportFormatText, "Format of the stats printed every print-interval queries: 'text' (human readable), 'csv' or 'jsonl' (one JSON object per line)")
+	fs.String("report-file", "", "Write the stats printed every print-interval queries to this file instead of stderr")
+	fs.String("metrics-listen-addr", "", "Serve live Prometheus metrics of the run on this address (e.g. ':9090'), on the /metrics path")
+	fs.String("capture-results", "", "Write the normalized result of every query to this file (one JSON object per line), to check them with tsbs_validate_results")
+	fs.Int("capture-precision", defaultResultPrecision, "Number of significant digits the numbers of the captured results are rounded to")
+	fs.Bool("continue-on-error", false, "Count the queries that fail, per label, instead of aborting the run on the first error")
+	fs.String("error-log", "", "Log the errors of the failed queries to this file instead of stderr (with --continue-on-error or --query-timeout)")
+	fs.Int("error-log-sample", 10, "Maximum number of errors logged per label, 0 logs all of them (with --continue-on-error or --query-timeout)")
+	fs.Float64("arrival-rate", 0, "Send this many queries per second in open loop, whether or not the previous ones returned, and measure their response time from the time they were scheduled. 0 = closed loop")
+	fs.String("arrival-distribution", ArrivalConstant, "Distribution of the time between the queries with --arrival-rate: 'constant' or 'poisson' (exponential times between the queries)")
+	fs.Int64("arrival-seed", 0, "PRNG seed of the poisson arrivals, 0 uses the current timestamp")
+	fs.Duration("duration", 0, "Stop sending queries after this long (e.g. '2h'), the queries in flight are completed, 0 = until the end of the queries")
+	fs.Bool("loop", false, "Replay the queries from the start once they are all sent, until --duration or --max-queries is reached. The stats of every pass are also reported separately")
+	fs.Bool("shuffle", false, "Shuffle the order of the queries on every replay with --loop")
+	fs.Int64("shuffle-seed", 0, "PRNG seed of --shuffle, 0 uses the current timestamp")
+	fs.StringSlice("assert", nil, "Assertion on the final stats, e.g. 'p99(label)<50ms', 'qps>100' or 'errors==0', repeated or comma separated. The run exits with 3 if one fails. Stats: pN (e.g. p99.9), min, mean, max, stddev (in ms), count, qps, errors, errorRate and timeouts, of all the queries or of a label in parentheses")
+	fs.Duration("query-timeout", 0, "Cancel the queries running for longer than this and count them as timeouts instead of aborting the run, 0 = no timeout")
 }
 
 // BenchmarkRunner contains the common components for running a query benchmarking
@@ -65,6 +131,27 @@ type BenchmarkRunner struct {
 	sp      statProcessor
 	scanner *scanner
 	ch      chan Query
+	metrics *runnerMetrics
+	results *resultsCapture
+	errors  *errorLog
+	// arrivals is the schedule of the queries in open loop, nil in closed loop
+	arrivals *arrivalSchedule
+	// deadline is the time to stop sending queries at, zero without a duration
+	deadline time.Time
+	// passStarts has the ID of the first query of every pass of a looping run
+	passStarts []uint64
+	passMu     sync.RWMutex
+	// sweepQueries has the queries of a workers sweep, read once and replayed
+	// at every level, nil without a sweep
+	sweepQueries *queryReplay
+	// chMu guards ch, which is replaced at every level of a workers sweep
+	chMu sync.RWMutex
+	// assertions are checked against the final stats, they are parsed by Run
+	assertions []slo.Assertion
+	// interrupted is set to 1 by SIGINT or SIGTERM
+	interrupted int32
+	// stopped is set to 1 by Stop
+	stopped int32
 }
 
 // NewBenchmarkRunner creates a new instance of BenchmarkRunner which is
@@ -72,12 +159,21 @@ type BenchmarkRunner struct {
 func NewBenchmarkRunner(config BenchmarkRunnerConfig) *BenchmarkRunner {
 	runner := &BenchmarkRunner{BenchmarkRunnerConfig: config}
 	runner.scanner = newScanner(&runner.Limit)
+	if config.MetricsListenAddr != "" {
+		runner.metrics = newRunnerMetrics()
+	}
 	spArgs := &statProcessorArgs{
 		limit:            &runner.Limit,
 		printInterval:    runner.PrintInterval,
 		prewarmQueries:   runner.PrewarmQueries,
 		burnIn:           runner.BurnIn,
 		hdrLatenciesFile: runner.HDRLatenciesFile,
+		metrics:          runner.metrics,
+		reportFormat:     runner.ReportFormat,
+		reportFile:       runner.ReportFile,
+		openLoop:         runner.ArrivalRate > 0,
+		hdrLatenciesDir:  runner.HDRLatenciesDir,
+		loop:             runner.Loop,
 	}
 
 	runner.sp = newStatProcessor(spArgs)
@@ -99,6 +195,23 @@ func (b *BenchmarkRunner) DebugLevel() int {
 	return b.Debug
 }
 
+// CaptureResults indicates whether processors should pass the rows returned by the
+// queries to CaptureResult
+func (b *BenchmarkRunner) CaptureResults() bool {
+	return len(b.CaptureResultsFile) > 0
+}
+
+// CaptureResult normalizes the rows returned by q and writes them to the results
+// capture file. Processors only call it for the first (cold) run of a query.
+func (b *BenchmarkRunner) CaptureResult(q Query, rows [][]interface{}) {
+	if b.results == nil {
+		return
+	}
+	if err := b.results.write(q, rows); err != nil {
+		log.Fatal(err)
+	}
+}
+
 // DatabaseName returns the name of the database to run queries against
 func (b *BenchmarkRunner) DatabaseName() string {
 	return b.DBName
@@ -112,8 +225,17 @@ type Processor interface {
 	// Init initializes at global state for the Processor, possibly based on its worker number / ID
 	Init(workerNum int)
 
-	// ProcessQuery handles a given query and reports its stats
-	ProcessQuery(q Query, isWarm bool) ([]*Stat, error)
+	// ProcessQuery handles a given query and reports its stats. It must return
+	// once ctx is done, with an error, so that timed out queries don't block the worker.
+	ProcessQuery(ctx context.Context, q Query, isWarm bool) ([]*Stat, error)
+}
+
+// ProcessorCloser is a Processor that also needs to close or cleanup once all
+// its queries are processed, e.g. the connections opened by Init
+type ProcessorCloser interface {
+	Processor
+	// Close cleans up after a Processor
+	Close()
 }
 
 // GetBufferedReader returns the buffered Reader that should be used by the loader
@@ -138,7 +260,11 @@ func (b *BenchmarkRunner) GetBufferedReader() *bufio.Reader {
 // It launches a gorountine to track stats, creates workers to process queries,
 // read in the input, execute the queries, and then does cleanup.
 func (b *BenchmarkRunner) Run(queryPool *sync.Pool, processorCreateFn ProcessorCreate) {
-	if b.Workers == 0 {
+	sweep, err := parseWorkersSweep(b.WorkersSweep)
+	if err != nil {
+		panic(err.Error())
+	}
+	if b.Workers == 0 && len(sweep) == 0 {
 		panic("must have at least one worker")
 	}
 
@@ -146,34 +272,82 @@ func (b *BenchmarkRunner) Run(queryPool *sync.Pool, processorCreateFn ProcessorC
 	if spArgs.burnIn > b.Limit {
 		panic("burn-in is larger than limit")
 	}
-	b.ch = make(chan Query, b.Workers)
-
-	// Launch the stats processor:
-	go b.sp.process(b.Workers)
-
-	rateLimiter := getRateLimiter(b.LimitRPS, b.Workers)
-
-	// Launch query processors
-	var wg sync.WaitGroup
-	for i := 0; i < int(b.Workers); i++ {
-		wg.Add(1)
-		go b.processorHandler(&wg, rateLimiter, queryPool, processorCreateFn(), i)
+	if _, err := newProgressWriter(b.ReportFormat, ioutil.Discard); err != nil {
+		panic(err.Error())
+	}
+	if _, err := NewDecoder(b.inputFormat(), nil); err != nil {
+		panic(err.Error())
+	}
+	if percentiles, err := parsePercentiles(b.Percentiles); err != nil {
+		panic(err.Error())
+	} else {
+		spArgs.percentiles = percentiles
+	}
+	if b.Loop && b.Duration == 0 && b.Limit == 0 {
+		panic("loop replays the queries until duration or max-queries is reached, one of them must be set")
+	}
+	if b.Shuffle && !b.Loop {
+		panic("shuffle reorders the queries of the replays, it can only be used with loop")
+	}
+	if b.assertions, err = parseAssertions(b.Asserts); err != nil {
+		panic(err.Error())
+	}
+	if len(sweep) > 0 && len(b.assertions) > 0 {
+		panic("assert checks the stats of a single run, it can't be used with workers-sweep")
+	}
+	if b.ArrivalRate > 0 {
+		if b.LimitRPS > 0 {
+			panic("max-rps limits the rate of a closed loop, it can't be used with arrival-rate")
+		}
+		seed := b.ArrivalSeed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		var err error
+		if b.arrivals, err = newArrivalSchedule(b.ArrivalRate, b.ArrivalDistribution, seed); err != nil {
+			panic(err.Error())
+		}
+	}
+	if len(sweep) > 0 && len(b.CaptureResultsFile) > 0 {
+		panic("capture-results would capture the queries of every level, it can't be used with workers-sweep")
+	}
+	if len(b.CaptureResultsFile) > 0 {
+		var err error
+		if b.results, err = newResultsCapture(b.CaptureResultsFile, b.CapturePrecision); err != nil {
+			panic(fmt.Sprintf("cannot create results capture file %s: %v", b.CaptureResultsFile, err))
+		}
+	}
+	if b.ContinueOnError || b.QueryTimeout > 0 {
+		closeErrorLog := b.openErrorLog()
+		defer closeErrorLog()
+	}
+	b.metrics.watchQueue(func() int { return len(b.queue()) })
+	b.serveMetrics()
+
+	// SIGINT and SIGTERM stop reading queries, the ones read are still executed
+	stopWatching := interrupt.Watch(func() { atomic.StoreInt32(&b.interrupted, 1) })
+
+	var wallStart, wallEnd time.Time
+	var levels []SweepLevel
+	if len(sweep) == 0 {
+		wallStart, wallEnd = b.runWorkers(queryPool, processorCreateFn, b.Workers)
+	} else {
+		levels, wallStart, wallEnd = b.runSweep(queryPool, processorCreateFn, sweep)
+	}
+	if b.results != nil {
+		if err := b.results.close(); err != nil {
+			log.Fatal(err)
+		}
+		_, _ = fmt.Printf("Saved the results of the queries to %s\n", b.CaptureResultsFile)
+	}
+	stopWatching()
+	if b.wasInterrupted() {
+		fmt.Println("run interrupted, the results only cover the queries executed until then")
 	}
-
-	// Read in jobs, closing the job channel when done:
-	// Wall clock start time
-	wallStart := time.Now()
-	b.scanner.setReader(b.GetBufferedReader()).scan(queryPool, b.ch)
-	close(b.ch)
-
-	// Block for workers to finish sending requests, closing the stats channel when done:
-	wg.Wait()
-	b.sp.CloseAndWait()
 
 	// Wall clock end time
-	wallEnd := time.Now()
 	wallTook := wallEnd.Sub(wallStart)
-	_, err := fmt.Printf("wall clock time: %fsec\n", float64(wallTook.Nanoseconds())/1e9)
+	_, err = fmt.Printf("wall clock time: %fsec\n", float64(wallTook.Nanoseconds())/1e9)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -188,20 +362,81 @@ func (b *BenchmarkRunner) Run(queryPool *sync.Pool, processorCreateFn ProcessorC
 		f.Close()
 	}
 
+	var assertions []slo.Result
+	if len(b.assertions) > 0 {
+		assertions = b.checkAssertions(wallTook)
+	}
+
 	// (Optional) save the results file:
 	if len(b.BenchmarkRunnerConfig.ResultsFile) > 0 {
-		b.saveTestResult(wallTook, wallStart, wallEnd)
+		totals := map[string]interface{}{"workersSweep": levels}
+		if len(sweep) == 0 {
+			totals = b.sp.GetTotalsMap()
+		}
+		if assertions != nil {
+			totals["assertions"] = assertions
+		}
+		b.saveTestResult(wallTook, wallStart, wallEnd, totals)
 	}
+	slo.ExitOnFailure(assertions)
 }
 
-func (b *BenchmarkRunner) saveTestResult(took time.Duration, start time.Time, end time.Time) {
+// runWorkers runs the queries with the number of workers until they are all
+// read or the scanner stops, and returns the wall clock start and end times
+func (b *BenchmarkRunner) runWorkers(queryPool *sync.Pool, processorCreateFn ProcessorCreate, workers uint) (time.Time, time.Time) {
+	ch := make(chan Query, workers)
+	b.chMu.Lock()
+	b.ch = ch
+	b.chMu.Unlock()
+
+	// Launch the stats processor:
+	b.sp.open(workers)
+	go b.sp.process(workers)
+
+	rateLimiter := getRateLimiter(b.LimitRPS, workers)
+
+	if b.arrivals != nil {
+		b.arrivals.start(time.Now())
+	}
+
+	// Launch query processors
+	var wg sync.WaitGroup
+	for i := 0; i < int(workers); i++ {
+		wg.Add(1)
+		go b.processorHandler(&wg, rateLimiter, processorCreateFn(), i)
+	}
+
+	// Read in jobs, closing the job channel when done:
+	// Wall clock start time
+	wallStart := time.Now()
+	if b.Duration > 0 {
+		b.deadline = wallStart.Add(b.Duration)
+	}
+	b.scanQueries(queryPool)
+	close(ch)
+
+	// Block for workers to finish sending requests, closing the stats channel when done:
+	wg.Wait()
+	b.sp.CloseAndWait()
+	return wallStart, time.Now()
+}
+
+// queue returns the channel of the queries read that wait for a worker
+func (b *BenchmarkRunner) queue() chan Query {
+	b.chMu.RLock()
+	defer b.chMu.RUnlock()
+	return b.ch
+}
+
+func (b *BenchmarkRunner) saveTestResult(took time.Duration, start time.Time, end time.Time, totals map[string]interface{}) {
 	testResult := LoaderTestResult{
 		ResultFormatVersion: BenchmarkTestResultVersion,
 		RunnerConfig:        b.BenchmarkRunnerConfig,
 		StartTime:           start.UTC().Unix() * 1000,
 		EndTime:             end.UTC().Unix() * 1000,
 		DurationMillis:      took.Milliseconds(),
-		Totals:              b.sp.GetTotalsMap(),
+		Interrupted:         b.wasInterrupted(),
+		Totals:              totals,
 	}
 
 	_, _ = fmt.Printf("Saving results json file to %s\n", b.BenchmarkRunnerConfig.ResultsFile)
@@ -216,15 +451,108 @@ func (b *BenchmarkRunner) saveTestResult(took time.Duration, start time.Time, en
 	}
 }
 
-func (b *BenchmarkRunner) processorHandler(wg *sync.WaitGroup, rateLimiter *rate.Limiter, queryPool *sync.Pool, processor Processor, workerNum int) {
+// wasInterrupted reports whether the run was interrupted before all the queries were read
+func (b *BenchmarkRunner) wasInterrupted() bool {
+	return atomic.LoadInt32(&b.interrupted) == 1
+}
+
+// Stop makes Run stop reading queries, the ones already read are still executed.
+// Unlike an interrupt, the results are not marked as interrupted.
+func (b *BenchmarkRunner) Stop() {
+	atomic.StoreInt32(&b.stopped, 1)
+}
+
+// stopReading reports whether the scanner should stop reading queries
+func (b *BenchmarkRunner) stopReading() bool {
+	if !b.deadline.IsZero() && time.Now().After(b.deadline) {
+		return true
+	}
+	return b.wasInterrupted() || atomic.LoadInt32(&b.stopped) == 1
+}
+
+// scanQueries sends the queries read to the workers. With Loop, the queries
+// are replayed once they are all read, shuffled with Shuffle, until the
+// scanner stops.
+func (b *BenchmarkRunner) scanQueries(queryPool *sync.Pool) {
+	if b.sweepQueries != nil {
+		// the queries of a sweep are read once, then replayed at every level
+		b.scanner.setReader(b.sweepQueries.reader()).setFormat(FormatGob)
+	} else {
+		b.scanner.setReader(b.GetBufferedReader()).setFormat(b.inputFormat())
+	}
+	b.scanner.setStop(b.stopReading)
+	if !b.Loop {
+		b.scanner.scan(queryPool, b.ch)
+		return
+	}
+	seed := b.ShuffleSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	replay := newQueryReplay(b.Shuffle, seed)
+	b.startPass()
+	if !b.scanner.setReplay(replay).scan(queryPool, b.ch) || replay.len() == 0 {
+		return
+	}
+	// the queries are replayed from their gob encoding, whatever the input format
+	b.scanner.setReplay(nil).setFormat(FormatGob)
+	for {
+		b.startPass()
+		if !b.scanner.setReader(replay.reader()).scan(queryPool, b.ch) {
+			return
+		}
+	}
+}
+
+// inputFormat returns the format of the queries read, gob if it isn't set
+func (b *BenchmarkRunner) inputFormat() string {
+	if b.InputFormat == "" {
+		return FormatGob
+	}
+	return b.InputFormat
+}
+
+// startPass records that the next query sent starts a new pass over the queries
+func (b *BenchmarkRunner) startPass() {
+	b.passMu.Lock()
+	b.passStarts = append(b.passStarts, b.scanner.n)
+	b.passMu.Unlock()
+}
+
+// passOf returns the pass over the queries the query of the ID was sent in,
+// starting at 1, or 0 if the run doesn't loop
+func (b *BenchmarkRunner) passOf(id uint64) uint64 {
+	b.passMu.RLock()
+	defer b.passMu.RUnlock()
+	return uint64(sort.Search(len(b.passStarts), func(i int) bool { return b.passStarts[i] > id }))
+}
+
+// LabelStats returns the latency statistics of the queries of every label,
+// it must only be called once Run returned
+func (b *BenchmarkRunner) LabelStats() map[string]LabelStats {
+	return b.sp.labelStats()
+}
+
+func (b *BenchmarkRunner) processorHandler(wg *sync.WaitGroup, rateLimiter *rate.Limiter, processor Processor, workerNum int) {
 	processor.Init(workerNum)
 	for query := range b.ch {
-		r := rateLimiter.Reserve()
-		time.Sleep(r.Delay())
+		var delay time.Duration
+		if b.arrivals != nil {
+			// in open loop the query waits for its arrival, unless it's late
+			// already because all the workers were busy
+			intended := b.arrivals.nextStart()
+			time.Sleep(time.Until(intended))
+			delay = time.Since(intended)
+		} else {
+			r := rateLimiter.Reserve()
+			time.Sleep(r.Delay())
+		}
 
-		stats, err := processor.ProcessQuery(query, false)
-		if err != nil {
-			panic(err)
+		pass := b.passOf(query.GetID())
+		stats := b.runQuery(processor, query, false)
+		for _, s := range stats {
+			s.delay = float64(delay.Nanoseconds()) / 1e6
+			s.pass = pass
 		}
 		b.sp.send(stats)
 
@@ -234,17 +562,75 @@ func (b *BenchmarkRunner) processorHandler(wg *sync.WaitGroup, rateLimiter *rate
 		spArgs := b.sp.getArgs()
 		if spArgs.prewarmQueries {
 			// Warm run
-			stats, err = processor.ProcessQuery(query, true)
-			if err != nil {
-				panic(err)
+			warmStats := b.runQuery(processor, query, true)
+			for _, s := range warmStats {
+				s.pass = pass
 			}
-			b.sp.sendWarm(stats)
+			b.sp.sendWarm(warmStats)
 		}
-		queryPool.Put(query)
+		// the queries are decoded into the ones of the pool, which must not
+		// keep the fields of the previous ones, e.g. when they are replayed
+		query.Release()
 	}
+
+	// Close processor if necessary, e.g. the processors of every level of a
+	// workers sweep are new ones
+	switch c := processor.(type) {
+	case ProcessorCloser:
+		c.Close()
+	}
+
 	wg.Done()
 }
 
+// runQuery executes q with the query timeout, if any, and returns its stats
+func (b *BenchmarkRunner) runQuery(processor Processor, q Query, isWarm bool) []*Stat {
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if b.QueryTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, b.QueryTimeout)
+	}
+	defer cancel()
+
+	b.metrics.queryStarted()
+	stats, err := processor.ProcessQuery(ctx, q, isWarm)
+	b.metrics.queryDone()
+	if err != nil {
+		// processors relying on a timeout of the database wrap its error
+		timedOut := ctx.Err() == context.DeadlineExceeded || errors.Is(err, context.DeadlineExceeded)
+		return b.queryFailed(q, isWarm, err, timedOut)
+	}
+	return stats
+}
+
+// queryFailed logs the error of a query and returns the stat recording the failure.
+// Queries that timed out are only counted, other errors panic if the run
+// doesn't continue on errors.
+func (b *BenchmarkRunner) queryFailed(q Query, isWarm bool, err error, timedOut bool) []*Stat {
+	if !timedOut && !b.ContinueOnError {
+		panic(err)
+	}
+	b.errors.log(q, isWarm, err)
+	if timedOut {
+		return []*Stat{newTimeoutStat(q.HumanLabelName())}
+	}
+	return []*Stat{newErrorStat(q.HumanLabelName())}
+}
+
+// openErrorLog opens the destination of the errors of the failed queries. The
+// returned function closes it.
+func (b *BenchmarkRunner) openErrorLog() func() {
+	if len(b.ErrorLogFile) == 0 {
+		b.errors = newErrorLog(os.Stderr, b.ErrorLogSample)
+		return func() {}
+	}
+	f, err := os.Create(b.ErrorLogFile)
+	if err != nil {
+		panic(fmt.Sprintf("cannot create error log file %s: %v", b.ErrorLogFile, err))
+	}
+	b.errors = newErrorLog(f, b.ErrorLogSample)
+	return func() { _ = f.Close() }
+}
+
 func getRateLimiter(limitRPS uint64, workers uint) *rate.Limiter {
 	var requestRate = rate.Inf
 	var requestBurst = 0
@@ -1,14 +1,20 @@
 package query
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"golang.org/x/time/rate"
 	"io/ioutil"
 	"math"
 	"os"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 type testProcessor struct {
@@ -21,7 +27,7 @@ func (p *testProcessor) Init(workerNum int) {
 	p.count = 0
 }
 
-func (p *testProcessor) ProcessQuery(_ Query, _ bool) ([]*Stat, error) {
+func (p *testProcessor) ProcessQuery(_ context.Context, _ Query, _ bool) ([]*Stat, error) {
 	p.count++
 	return nil, nil
 }
@@ -43,8 +49,8 @@ func TestProcessorHandler(t *testing.T) {
 	var requestBurst = 0
 	var rateLimiter *rate.Limiter = rate.NewLimiter(requestRate, requestBurst)
 
-	go b.processorHandler(&wg, rateLimiter, qPool, p1, 0)
-	go b.processorHandler(&wg, rateLimiter, qPool, p2, 5)
+	go b.processorHandler(&wg, rateLimiter, p1, 0)
+	go b.processorHandler(&wg, rateLimiter, p2, 5)
 	for i := 0; i < qLimit; i++ {
 		q := qPool.Get().(*testQuery)
 		b.ch <- q
@@ -63,6 +69,26 @@ func TestProcessorHandler(t *testing.T) {
 	}
 }
 
+func TestProcessorHandlerReleasesQueries(t *testing.T) {
+	b := NewBenchmarkRunner(BenchmarkRunnerConfig{})
+	b.ch = make(chan Query, 1)
+
+	// the queries decoded into a released one don't keep the fields of the
+	// previous one missing in the input
+	q := NewHTTP()
+	q.Body = []byte("payload")
+	q.StartTimestamp = 5
+	b.ch <- q
+	close(b.ch)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	b.processorHandler(&wg, rate.NewLimiter(rate.Inf, 0), &testProcessor{}, 0)
+	if len(q.Body) != 0 || q.StartTimestamp != 0 {
+		t.Errorf("query not released: got body %q and start %d", q.Body, q.StartTimestamp)
+	}
+}
+
 func TestProcessorHandlerPreWarm(t *testing.T) {
 	qLimit := 17
 	p1Num := 0
@@ -85,8 +111,8 @@ func TestProcessorHandlerPreWarm(t *testing.T) {
 	var wg sync.WaitGroup
 	qPool := &testQueryPool
 	wg.Add(2)
-	go b.processorHandler(&wg, rateLimiter, qPool, p1, 0)
-	go b.processorHandler(&wg, rateLimiter, qPool, p2, 5)
+	go b.processorHandler(&wg, rateLimiter, p1, 0)
+	go b.processorHandler(&wg, rateLimiter, p2, 5)
 	for i := 0; i < qLimit; i++ {
 		q := qPool.Get().(*testQuery)
 		b.ch <- q
@@ -104,6 +130,208 @@ func TestProcessorHandlerPreWarm(t *testing.T) {
 		t.Errorf("total queries wrong: want %d got %d", 2*qLimit, p1.count+p2.count)
 	}
 }
+func TestProcessorHandlerContinueOnError(t *testing.T) {
+	qLimit := 5
+	var sent []*Stat
+	b := &BenchmarkRunner{BenchmarkRunnerConfig: BenchmarkRunnerConfig{ContinueOnError: true}}
+	var log bytes.Buffer
+	b.errors = newErrorLog(&log, 2)
+	b.sp = &mockStatProcessor{
+		args:   &statProcessorArgs{},
+		onSend: func(stats []*Stat) { sent = append(sent, stats...) },
+	}
+	b.ch = make(chan Query, qLimit)
+	for i := 0; i < qLimit; i++ {
+		q := testQueryPool.Get().(*testQuery)
+		q.HumanLabel = []byte("q1")
+		b.ch <- q
+	}
+	close(b.ch)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	p := &mockProcessor{processErr: errors.New("timeout")}
+	b.processorHandler(&wg, rate.NewLimiter(rate.Inf, 0), p, 0)
+
+	if len(sent) != qLimit {
+		t.Fatalf("wrong number of stats: got %d want %d", len(sent), qLimit)
+	}
+	for _, s := range sent {
+		if !s.isError || string(s.label) != "q1" {
+			t.Errorf("failed query not recorded as an error: %+v", s)
+		}
+	}
+	// 2 errors and the line saying the others aren't logged
+	if got := strings.Count(log.String(), "\n"); got != 3 {
+		t.Errorf("wrong number of lines logged: got %d want 3\n%s", got, log.String())
+	}
+}
+
+func TestProcessorHandlerPanicOnError(t *testing.T) {
+	b := &BenchmarkRunner{}
+	b.sp = &mockStatProcessor{args: &statProcessorArgs{}}
+	b.ch = make(chan Query, 1)
+	b.ch <- testQueryPool.Get().(*testQuery)
+	close(b.ch)
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("the code did not panic")
+		}
+	}()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	p := &mockProcessor{processErr: errors.New("timeout")}
+	b.processorHandler(&wg, rate.NewLimiter(rate.Inf, 0), p, 0)
+}
+
+// blockingProcessor runs its queries until they are cancelled
+type blockingProcessor struct{}
+
+func (p *blockingProcessor) Init(int) {}
+func (p *blockingProcessor) ProcessQuery(ctx context.Context, _ Query, _ bool) ([]*Stat, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestProcessorHandlerQueryTimeout(t *testing.T) {
+	var sent []*Stat
+	// timeouts are counted even without ContinueOnError
+	b := &BenchmarkRunner{BenchmarkRunnerConfig: BenchmarkRunnerConfig{QueryTimeout: time.Millisecond}}
+	b.sp = &mockStatProcessor{
+		args:   &statProcessorArgs{},
+		onSend: func(stats []*Stat) { sent = append(sent, stats...) },
+	}
+	b.ch = make(chan Query, 1)
+	b.ch <- testQueryPool.Get().(*testQuery)
+	close(b.ch)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	b.processorHandler(&wg, rate.NewLimiter(rate.Inf, 0), &blockingProcessor{}, 0)
+
+	if len(sent) != 1 || !sent[0].isError || !sent[0].isTimeout {
+		t.Errorf("query not recorded as a timeout: %+v", sent)
+	}
+}
+
+func TestProcessorHandlerOpenLoop(t *testing.T) {
+	var sent []*Stat
+	b := &BenchmarkRunner{}
+	b.sp = &mockStatProcessor{
+		args:   &statProcessorArgs{},
+		onSend: func(stats []*Stat) { sent = append(sent, stats...) },
+	}
+	b.arrivals, _ = newArrivalSchedule(1, ArrivalConstant, 1)
+	// the first query should have started a second ago, the second one is on time
+	b.arrivals.start(time.Now().Add(-time.Second))
+	b.ch = make(chan Query, 2)
+	b.ch <- testQueryPool.Get().(*testQuery)
+	b.ch <- testQueryPool.Get().(*testQuery)
+	close(b.ch)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	b.processorHandler(&wg, rate.NewLimiter(rate.Inf, 0), &fixedLatencyProcessor{}, 0)
+
+	if len(sent) != 2 {
+		t.Fatalf("wrong number of stats: got %d want 2", len(sent))
+	}
+	if got := sent[0].delay; got < 1000 || got > 1100 {
+		t.Errorf("wrong delay of the late query: got %fms want 1000ms", got)
+	}
+	if got := sent[1].delay; got > 100 {
+		t.Errorf("query started late: delay %fms", got)
+	}
+}
+
+// fixedLatencyProcessor returns a new stat of 1ms for every query
+type fixedLatencyProcessor struct{}
+
+func (p *fixedLatencyProcessor) Init(int) {}
+func (p *fixedLatencyProcessor) ProcessQuery(_ context.Context, q Query, _ bool) ([]*Stat, error) {
+	return []*Stat{GetStat().Init(q.HumanLabelName(), 1)}, nil
+}
+
+// recordingProcessor records the IDs and labels of the queries it processes
+type recordingProcessor struct {
+	ids    []uint64
+	labels []string
+}
+
+func (p *recordingProcessor) Init(int) {}
+func (p *recordingProcessor) ProcessQuery(_ context.Context, q Query, _ bool) ([]*Stat, error) {
+	p.ids = append(p.ids, q.GetID())
+	p.labels = append(p.labels, string(q.HumanLabelName()))
+	return []*Stat{GetStat().Init(q.HumanLabelName(), 1)}, nil
+}
+
+func TestBenchmarkRunnerRunLoop(t *testing.T) {
+	var buf bytes.Buffer
+	err := encodeQueries(&buf, 3, func(i uint64) Query {
+		return &testQuery{HumanLabel: []byte(fmt.Sprintf("q%d", i))}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := ioutil.TempFile("", "loop_queries*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	if _, err = f.Write(buf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	runner := NewBenchmarkRunner(BenchmarkRunnerConfig{
+		Workers:     1,
+		Limit:       8,
+		FileName:    f.Name(),
+		Loop:        true,
+		Shuffle:     true,
+		ShuffleSeed: 1,
+	})
+	p := &recordingProcessor{}
+	runner.Run(&testQueryPool, func() Processor { return p })
+
+	if len(p.ids) != 8 {
+		t.Fatalf("wrong number of queries: got %d want 8", len(p.ids))
+	}
+	for i, id := range p.ids {
+		if id != uint64(i) {
+			t.Errorf("wrong id of query %d: got %d", i, id)
+		}
+	}
+	if got := strings.Join(p.labels[:3], ","); got != "q0,q1,q2" {
+		t.Errorf("the first pass is not in order: %s", got)
+	}
+	second := append([]string(nil), p.labels[3:6]...)
+	sort.Strings(second)
+	if got := strings.Join(second, ","); got != "q0,q1,q2" {
+		t.Errorf("the second pass doesn't replay the queries: %v", p.labels[3:6])
+	}
+
+	passes, ok := runner.sp.GetTotalsMap()["passes"].([]PassStats)
+	if !ok || len(passes) != 3 {
+		t.Fatalf("wrong passes in the totals: %v", runner.sp.GetTotalsMap()["passes"])
+	}
+	for i, want := range []uint64{3, 3, 2} {
+		if passes[i].Pass != uint64(i+1) || passes[i].Queries != want {
+			t.Errorf("wrong pass %d: got pass %d with %d queries, want %d queries", i, passes[i].Pass, passes[i].Queries, want)
+		}
+	}
+}
+
+func TestBenchmarkRunnerRunPanicOnEndlessLoop(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("loop without a duration or a limit did not panic")
+		}
+	}()
+	runner := NewBenchmarkRunner(BenchmarkRunnerConfig{Workers: 1, Loop: true})
+	runner.Run(nil, nil)
+}
+
 func TestBenchmarkRunnerGetBufferedReaderPanicOnMissingFile(t *testing.T) {
 	dumbFileName := "some-random-file-that-should-not-exist"
 	_, err := os.Stat(dumbFileName)
@@ -299,6 +527,8 @@ type mockStatProcessor struct {
 	onProcess func(uint)
 	closed    bool
 	wg        *sync.WaitGroup
+	// processed is done once process returned, like the real stat processor
+	processed sync.WaitGroup
 }
 
 func (m *mockStatProcessor) getArgs() *statProcessorArgs {
@@ -314,12 +544,17 @@ func (m *mockStatProcessor) sendWarm(stats []*Stat) {
 		m.onSend(stats)
 	}
 }
+func (m *mockStatProcessor) open(workers uint) {
+	m.processed.Add(1)
+}
 func (m *mockStatProcessor) process(workers uint) {
 	if m.onProcess != nil {
 		m.onProcess(workers)
 	}
+	m.processed.Done()
 }
 func (m *mockStatProcessor) CloseAndWait() {
+	m.processed.Wait()
 	m.closed = true
 	m.wg.Done()
 }
@@ -328,6 +563,14 @@ func (m *mockStatProcessor) GetTotalsMap() map[string]interface{} {
 	return totals
 }
 
+func (m *mockStatProcessor) labelStats() map[string]LabelStats {
+	return nil
+}
+
+func (m *mockStatProcessor) percentile(string, float64) (float64, bool) {
+	return 0, false
+}
+
 type mockProcessor struct {
 	processRes []*Stat
 	processErr error
@@ -335,7 +578,7 @@ type mockProcessor struct {
 }
 
 func (mp *mockProcessor) Init(workerNum int) { mp.initCalled = true }
-func (mp *mockProcessor) ProcessQuery(q Query, isWarm bool) ([]*Stat, error) {
+func (mp *mockProcessor) ProcessQuery(_ context.Context, q Query, isWarm bool) ([]*Stat, error) {
 	return mp.processRes, mp.processErr
 }
 
@@ -19,6 +19,8 @@ type QueryGeneratorConfig struct {
 	QueryType            string `mapstructure:"query-type"`
 	InterleavedGroupID   uint   `mapstructure:"interleaved-generation-group-id"`
 	InterleavedNumGroups uint   `mapstructure:"interleaved-generation-groups"`
+	// OutputFormat is the format of the queries written: gob or jsonl
+	OutputFormat string `mapstructure:"output-format"`
 
 	// TODO - I think this needs some rethinking, but a simple, elegant solution escapes me right now
 	TimescaleUseJSON       bool `mapstructure:"timescale-use-json"`
@@ -50,6 +52,7 @@ func (c *QueryGeneratorConfig) AddToFlagSet(fs *pflag.FlagSet) {
 	c.BaseConfig.AddToFlagSet(fs)
 	fs.Uint64("queries", 1000, "Number of queries to generate.")
 	fs.String("query-type", "", "Query type. (Choices are in the use case matrix.)")
+	fs.String("output-format", "gob", "Format of the queries written: 'gob' or 'jsonl' (one JSON object per line, read by the query runners with --input-format=jsonl)")
 
 	fs.Uint("interleaved-generation-group-id", 0,
 		"Group (0-indexed) to perform round-robin serialization within. Use this to scale up data generation to multiple processes.")
@@ -0,0 +1,45 @@
+package query
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// errorLog logs the errors of the failed queries, at most sampleSize per label
+// so that a query failing all the time doesn't flood the output
+type errorLog struct {
+	mu         sync.Mutex
+	out        io.Writer
+	sampleSize int
+	logged     map[string]int
+}
+
+func newErrorLog(out io.Writer, sampleSize int) *errorLog {
+	return &errorLog{out: out, sampleSize: sampleSize, logged: make(map[string]int)}
+}
+
+// log writes the error of q, if the sample of its label isn't full. A sample
+// size of 0 logs all the errors. A nil errorLog logs nothing.
+func (l *errorLog) log(q Query, isWarm bool, err error) {
+	if l == nil {
+		return
+	}
+	label := string(q.HumanLabelName())
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	logged := l.logged[label]
+	if l.sampleSize > 0 && logged > l.sampleSize {
+		return
+	}
+	l.logged[label] = logged + 1
+	if l.sampleSize > 0 && logged == l.sampleSize {
+		_, _ = fmt.Fprintf(l.out, "more errors for '%s', not logged\n", label)
+		return
+	}
+	run := "cold"
+	if isWarm {
+		run = "warm"
+	}
+	_, _ = fmt.Fprintf(l.out, "query %d '%s' failed (%s run): %v\n", q.GetID(), label, run, err)
+}
@@ -0,0 +1,286 @@
+package query
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/globalsign/mgo/bson"
+)
+
+// Formats of the query files
+const (
+	// FormatGob is a gob stream of the queries, the default
+	FormatGob = "gob"
+	// FormatJSONL has one JSON object per line and query, readable and editable
+	FormatJSONL = "jsonl"
+)
+
+// jsonlTypeKey is the key of the type of the query in its JSON object
+const jsonlTypeKey = "type"
+
+// queryPools are the pools of the types of queries, by the name of the type
+var queryPools = map[string]*sync.Pool{
+	"cassandra":   &CassandraPool,
+	"clickhouse":  &ClickHousePool,
+	"cratedb":     &CrateDBPool,
+	"http":        &HTTPPool,
+	"mongo":       &MongoPool,
+	"siridb":      &SiriDBPool,
+	"timescaledb": &TimescaleDBPool,
+	"timestream":  &TimestreamPool,
+}
+
+// queryTypeNames are the names of the types of queries, by their Go type
+var queryTypeNames = func() map[reflect.Type]string {
+	names := make(map[reflect.Type]string, len(queryPools))
+	for name, pool := range queryPools {
+		names[reflect.TypeOf(pool.New())] = name
+	}
+	return names
+}()
+
+// QueryTypes returns the names of the types of queries, sorted
+func QueryTypes() []string {
+	types := make([]string, 0, len(queryPools))
+	for name := range queryPools {
+		types = append(types, name)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// QueryPool returns the pool of the queries of the named type (e.g. "timescaledb")
+func QueryPool(queryType string) (*sync.Pool, error) {
+	pool, ok := queryPools[queryType]
+	if !ok {
+		return nil, fmt.Errorf("unknown query type '%s', must be one of: %s", queryType, strings.Join(QueryTypes(), ", "))
+	}
+	return pool, nil
+}
+
+// queryTypeName returns the name of the type of a query, empty if it is unknown
+func queryTypeName(q Query) string {
+	return queryTypeNames[reflect.TypeOf(q)]
+}
+
+// Encoder writes queries in a format
+type Encoder interface {
+	Encode(q Query) error
+}
+
+// Decoder reads queries written in a format, it returns io.EOF after the last one
+type Decoder interface {
+	Decode(q Query) error
+}
+
+// NewEncoder returns an Encoder writing queries to w in the format
+func NewEncoder(format string, w io.Writer) (Encoder, error) {
+	switch format {
+	case FormatGob:
+		return &gobEncoder{enc: gob.NewEncoder(w)}, nil
+	case FormatJSONL:
+		return &jsonlEncoder{w: w}, nil
+	default:
+		return nil, fmt.Errorf("unknown query format '%s', must be '%s' or '%s'", format, FormatGob, FormatJSONL)
+	}
+}
+
+// NewDecoder returns a Decoder reading queries from r in the format
+func NewDecoder(format string, r io.Reader) (Decoder, error) {
+	switch format {
+	case FormatGob:
+		return &gobDecoder{dec: gob.NewDecoder(r)}, nil
+	case FormatJSONL:
+		return &jsonlDecoder{r: bufio.NewReader(r)}, nil
+	default:
+		return nil, fmt.Errorf("unknown query format '%s', must be '%s' or '%s'", format, FormatGob, FormatJSONL)
+	}
+}
+
+type gobEncoder struct {
+	enc *gob.Encoder
+}
+
+func (e *gobEncoder) Encode(q Query) error {
+	return e.enc.Encode(q)
+}
+
+type gobDecoder struct {
+	dec *gob.Decoder
+}
+
+func (d *gobDecoder) Decode(q Query) error {
+	return d.dec.Decode(q)
+}
+
+type jsonlEncoder struct {
+	w io.Writer
+}
+
+func (e *jsonlEncoder) Encode(q Query) error {
+	line, err := MarshalQueryJSON(q)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(append(line, '\n'))
+	return err
+}
+
+type jsonlDecoder struct {
+	r    *bufio.Reader
+	line int
+}
+
+// Decode reads the next query, empty lines and lines starting with # are skipped
+func (d *jsonlDecoder) Decode(q Query) error {
+	for {
+		line, err := d.r.ReadBytes('\n')
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			return err
+		}
+		d.line++
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		if err := UnmarshalQueryJSON(line, q); err != nil {
+			return fmt.Errorf("line %d: %v", d.line, err)
+		}
+		return nil
+	}
+}
+
+// MarshalQueryJSON returns the JSON object of a query, on a single line. Its
+// keys are the type of the query and its exported fields, in their order, with
+// the byte slices as strings.
+func MarshalQueryJSON(q Query) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(`{"` + jsonlTypeKey + `":`)
+	if err := writeJSON(&buf, queryTypeName(q)); err != nil {
+		return nil, err
+	}
+	v := reflect.ValueOf(q).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.PkgPath != "" {
+			// unexported, like the ID
+			continue
+		}
+		buf.WriteByte(',')
+		if err := writeJSON(&buf, field.Name); err != nil {
+			return nil, err
+		}
+		buf.WriteByte(':')
+		value, err := marshalField(v.Field(i))
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %v", field.Name, err)
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalQueryJSON sets the exported fields of a query from its JSON object.
+// The fields missing from the object are reset, unknown keys are an error.
+func UnmarshalQueryJSON(data []byte, q Query) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	if raw, ok := object[jsonlTypeKey]; ok {
+		var name string
+		if err := json.Unmarshal(raw, &name); err != nil {
+			return fmt.Errorf("%s: %v", jsonlTypeKey, err)
+		}
+		if want := queryTypeName(q); name != want {
+			return fmt.Errorf("query of type '%s' where '%s' is expected", name, want)
+		}
+		delete(object, jsonlTypeKey)
+	}
+	v := reflect.ValueOf(q).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		raw, ok := object[field.Name]
+		if !ok {
+			v.Field(i).Set(reflect.Zero(field.Type))
+			continue
+		}
+		delete(object, field.Name)
+		if err := unmarshalField(raw, v.Field(i)); err != nil {
+			return fmt.Errorf("field %s: %v", field.Name, err)
+		}
+	}
+	for key := range object {
+		return fmt.Errorf("unknown field %s", key)
+	}
+	return nil
+}
+
+var (
+	bytesType     = reflect.TypeOf([]byte(nil))
+	bsonSliceType = reflect.TypeOf([]bson.M(nil))
+)
+
+// marshalField returns the JSON of a field of a query: byte slices are written
+// as strings and BSON documents in MongoDB extended JSON, to keep their types
+func marshalField(v reflect.Value) ([]byte, error) {
+	switch v.Type() {
+	case bytesType:
+		var buf bytes.Buffer
+		err := writeJSON(&buf, string(v.Bytes()))
+		return buf.Bytes(), err
+	case bsonSliceType:
+		b, err := bson.MarshalJSON(v.Interface())
+		// it ends the documents with a new line
+		return bytes.TrimSpace(b), err
+	default:
+		return json.Marshal(v.Interface())
+	}
+}
+
+// unmarshalField sets a field of a query from its JSON, see marshalField
+func unmarshalField(raw json.RawMessage, v reflect.Value) error {
+	switch v.Type() {
+	case bytesType:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return err
+		}
+		v.SetBytes(append(v.Bytes()[:0], s...))
+		return nil
+	case bsonSliceType:
+		var docs []bson.M
+		if err := bson.UnmarshalJSON(raw, &docs); err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(docs))
+		return nil
+	default:
+		return json.Unmarshal(raw, v.Addr().Interface())
+	}
+}
+
+// writeJSON writes the JSON of a value without escaping HTML characters, which
+// are common in queries (e.g. "<" and "&&")
+func writeJSON(buf *bytes.Buffer, value interface{}) error {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		return err
+	}
+	// Encode ends the value with a new line
+	buf.Truncate(buf.Len() - 1)
+	return nil
+}
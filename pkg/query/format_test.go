@@ -0,0 +1,194 @@
+package query
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/globalsign/mgo/bson"
+)
+
+func TestQueryJSONRoundTrip(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		desc  string
+		query Query
+		empty Query
+	}{
+		{
+			desc: "timescaledb",
+			query: &TimescaleDB{
+				HumanLabel:       []byte("TimescaleDB max cpu"),
+				HumanDescription: []byte("TimescaleDB max cpu: 2016-01-01"),
+				Hypertable:       []byte("cpu"),
+				SqlQuery:         []byte("SELECT * FROM cpu WHERE usage_user > 90.0 AND hostname <> 'host_1'"),
+			},
+			empty: &TimescaleDB{},
+		},
+		{
+			desc: "cassandra",
+			query: &Cassandra{
+				HumanLabel:      []byte("Cassandra mean"),
+				MeasurementName: []byte("cpu"),
+				AggregationType: []byte("avg"),
+				TimeStart:       start,
+				TimeEnd:         start.Add(12 * time.Hour),
+				GroupByDuration: time.Hour,
+				Limit:           5,
+				TagSets:         [][]string{{"hostname=host_0", "hostname=host_1"}},
+			},
+			empty: &Cassandra{},
+		},
+		{
+			desc: "mongo",
+			query: &Mongo{
+				HumanLabel:     []byte("Mongo max cpu"),
+				CollectionName: []byte("point_data"),
+				BsonDoc: []bson.M{
+					{"$match": bson.M{"timestamp_ns": bson.M{"$gte": int64(1451606400000000000)}}},
+					{"$limit": 10},
+				},
+			},
+			empty: &Mongo{},
+		},
+	}
+	for _, c := range cases {
+		line, err := MarshalQueryJSON(c.query)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", c.desc, err)
+		}
+		if bytes.ContainsRune(line, '\n') {
+			t.Errorf("%s: the JSON object is on several lines: %s", c.desc, line)
+		}
+		if !bytes.HasPrefix(line, []byte(`{"type":"`+c.desc+`","HumanLabel":`)) {
+			t.Errorf("%s: the type and the fields are not in order: %s", c.desc, line)
+		}
+		if err = UnmarshalQueryJSON(line, c.empty); err != nil {
+			t.Fatalf("%s: unexpected error: %v", c.desc, err)
+		}
+		if m, ok := c.empty.(*Mongo); ok {
+			// the nested documents are decoded as maps, the numbers keep their type
+			gte := m.BsonDoc[0]["$match"].(map[string]interface{})["timestamp_ns"].(map[string]interface{})["$gte"]
+			if gte != int64(1451606400000000000) {
+				t.Errorf("%s: wrong nested value: %#v", c.desc, gte)
+			}
+			again, err := MarshalQueryJSON(m)
+			if err != nil || !bytes.Equal(again, line) {
+				t.Errorf("%s: got %s want %s (%v)", c.desc, again, line, err)
+			}
+		} else if !reflect.DeepEqual(c.empty, c.query) {
+			t.Errorf("%s: got %+v want %+v", c.desc, c.empty, c.query)
+		}
+	}
+}
+
+func TestQueryJSONReadable(t *testing.T) {
+	q := &TimescaleDB{SqlQuery: []byte("SELECT a FROM cpu WHERE a < 1 AND b > 2 AND c <> '&'")}
+	line, err := MarshalQueryJSON(q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(line, []byte(`"SqlQuery":"SELECT a FROM cpu WHERE a < 1 AND b > 2 AND c <> '&'"`)) {
+		t.Errorf("the query is not readable: %s", line)
+	}
+}
+
+func TestUnmarshalQueryJSONErrors(t *testing.T) {
+	cases := []struct {
+		desc    string
+		line    string
+		wantErr string
+	}{
+		{desc: "not json", line: `SELECT 1`, wantErr: "invalid character"},
+		{desc: "unknown field", line: `{"SqlQuery":"SELECT 1","Sql":"x"}`, wantErr: "unknown field Sql"},
+		{desc: "other type", line: `{"type":"http","SqlQuery":"SELECT 1"}`, wantErr: "query of type 'http' where 'timescaledb' is expected"},
+		{desc: "wrong value", line: `{"SqlQuery":1}`, wantErr: "field SqlQuery"},
+	}
+	for _, c := range cases {
+		err := UnmarshalQueryJSON([]byte(c.line), &TimescaleDB{})
+		if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+			t.Errorf("%s: got error %v want %s", c.desc, err, c.wantErr)
+		}
+	}
+}
+
+func TestJSONLDecoder(t *testing.T) {
+	input := `# a hand-written regression query
+{"type":"timescaledb","HumanLabel":"q1","SqlQuery":"SELECT 1"}
+
+{"HumanLabel":"q2","Hypertable":"cpu","SqlQuery":"SELECT 2"}
+{"HumanLabel":"q3","SqlQuery":"SELECT 3"}`
+	dec, err := NewDecoder(FormatJSONL, strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := &TimescaleDB{}
+	var got []string
+	for {
+		err := dec.Decode(q)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got = append(got, string(q.HumanLabel)+":"+string(q.Hypertable)+":"+string(q.SqlQuery))
+	}
+	// the fields missing from a query are reset
+	want := []string{"q1::SELECT 1", "q2:cpu:SELECT 2", "q3::SELECT 3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+
+	dec, _ = NewDecoder(FormatJSONL, strings.NewReader("{\"SqlQuery\":\"SELECT 1\"}\n{\"Sql\":1}\n"))
+	_ = dec.Decode(q)
+	if err = dec.Decode(q); err == nil || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Errorf("the error doesn't have the line: %v", err)
+	}
+}
+
+func TestEncoderDecoderFormats(t *testing.T) {
+	for _, format := range []string{FormatGob, FormatJSONL} {
+		var buf bytes.Buffer
+		enc, err := NewEncoder(format, &buf)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		for _, label := range []string{"q1", "q2"} {
+			if err = enc.Encode(&HTTP{HumanLabel: []byte(label), Method: []byte("GET")}); err != nil {
+				t.Fatalf("%s: unexpected error: %v", format, err)
+			}
+		}
+		dec, err := NewDecoder(format, &buf)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+		var got []string
+		for {
+			q := &HTTP{}
+			err := dec.Decode(q)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", format, err)
+			}
+			got = append(got, string(q.HumanLabel))
+		}
+		if !reflect.DeepEqual(got, []string{"q1", "q2"}) {
+			t.Errorf("%s: got %v", format, got)
+		}
+	}
+	if _, err := NewEncoder("xml", nil); err == nil {
+		t.Errorf("unknown format did not error")
+	}
+	if _, err := NewDecoder("xml", nil); err == nil {
+		t.Errorf("unknown format did not error")
+	}
+	if _, err := QueryPool("influx"); err == nil {
+		t.Errorf("unknown query type did not error")
+	}
+}
@@ -0,0 +1,78 @@
+package query
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+// hdrLogExtension is the extension of the HDR histogram log files
+const hdrLogExtension = ".hlog"
+
+// writeHDRLogFile writes the latencies of a StatGroup to a file of dir named
+// after the tag, in the HdrHistogram log format read by the HdrHistogram plotters
+// (e.g. HistogramLogAnalyzer). It returns the path of the file.
+func writeHDRLogFile(dir, tag string, sg *statGroup, start, end time.Time) (string, error) {
+	path := filepath.Join(dir, tag+hdrLogExtension)
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	bw := bufio.NewWriter(f)
+	if err = writeHDRLog(bw, tag, sg, start, end); err == nil {
+		err = bw.Flush()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return path, err
+}
+
+// writeHDRLog writes the latencies of a StatGroup as a single interval of a
+// HdrHistogram log, from start to end. The values are written in nanoseconds,
+// the unit the plotters expect.
+func writeHDRLog(w *bufio.Writer, tag string, sg *statGroup, start, end time.Time) error {
+	h := toNanosecondHistogram(sg.latencyHDRHistogram)
+	payload, err := h.Encode(hdrhistogram.V2CompressedEncodingCookieBase)
+	if err != nil {
+		return err
+	}
+	lw := hdrhistogram.NewHistogramLogWriter(w)
+	if err = lw.OutputLogFormatVersion(); err != nil {
+		return err
+	}
+	if err = lw.OutputStartTime(start.UnixNano() / int64(time.Millisecond)); err != nil {
+		return err
+	}
+	if err = lw.OutputLegend(); err != nil {
+		return err
+	}
+	// The interval line is written here because the log writer of the library
+	// writes the millisecond timestamps of the histogram where seconds are
+	// expected. The start of the interval is relative to the start time.
+	_, err = fmt.Fprintf(w, "Tag=%s,%.3f,%.3f,%.3f,%s\n",
+		tag,
+		0.0,
+		end.Sub(start).Seconds(),
+		float64(h.Max())/hdrhistogram.MsToNsRatio,
+		payload)
+	return err
+}
+
+// toNanosecondHistogram returns a copy of a latency histogram of a StatGroup,
+// which is in microseconds, with the values in nanoseconds
+func toNanosecondHistogram(us *hdrhistogram.Histogram) *hdrhistogram.Histogram {
+	const nsPerUs = 1000
+	ns := hdrhistogram.New(nsPerUs, us.HighestTrackableValue()*nsPerUs, int(us.SignificantFigures()))
+	for _, bar := range us.Distribution() {
+		if bar.Count > 0 {
+			// recording the lowest value of a bucket keeps it in the same bucket
+			_ = ns.RecordValues(bar.From*nsPerUs, bar.Count)
+		}
+	}
+	return ns
+}
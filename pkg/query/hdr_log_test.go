@@ -0,0 +1,58 @@
+package query
+
+import (
+	"bufio"
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+func TestWriteHDRLog(t *testing.T) {
+	sg := newStatGroup(0)
+	for _, ms := range []float64{0.5, 2, 2, 1500} {
+		sg.push(ms)
+	}
+	start := time.Unix(1600000000, 0)
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	if err := writeHDRLog(bw, "q1_cold", sg, start, start.Add(90*time.Second)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := bw.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Tag=q1_cold,0.000,90.000,1499.") {
+		t.Errorf("wrong interval line:\n%s", buf.String())
+	}
+
+	r := hdrhistogram.NewHistogramLogReader(&buf)
+	h, err := r.NextIntervalHistogram()
+	if err != nil || h == nil {
+		t.Fatalf("cannot read the log: %v", err)
+	}
+	if h.TotalCount() != 4 {
+		t.Errorf("wrong count: got %d want 4", h.TotalCount())
+	}
+	// the values are in nanoseconds
+	if got := h.ValueAtQuantile(50); !nearlyEqual(got, 2000000) {
+		t.Errorf("wrong median: got %dns want 2000000ns", got)
+	}
+	if got := h.Max(); !nearlyEqual(got, 1500000000) {
+		t.Errorf("wrong max: got %dns want 1500000000ns", got)
+	}
+	if got := h.StartTimeMs(); got != start.Unix()*1000 {
+		t.Errorf("wrong start time: got %d want %d", got, start.Unix()*1000)
+	}
+	if got := h.EndTimeMs(); got != start.Unix()*1000+90000 {
+		t.Errorf("wrong end time: got %d want %d", got, start.Unix()*1000+90000)
+	}
+}
+
+// nearlyEqual tells if a value of a histogram is within its precision of want
+func nearlyEqual(got, want int64) bool {
+	return math.Abs(float64(got-want)) <= float64(want)/1000
+}
@@ -0,0 +1,105 @@
+package query
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/timescale/tsbs/internal/metrics"
+)
+
+// runnerMetrics are the live metrics of a query benchmark, served with --metrics-listen-addr
+type runnerMetrics struct {
+	registry      *prometheus.Registry
+	queries       prometheus.Counter
+	queryErrors   *prometheus.CounterVec
+	queryTimeouts *prometheus.CounterVec
+	inFlight      prometheus.Gauge
+	queryDuration *prometheus.HistogramVec
+}
+
+func newRunnerMetrics() *runnerMetrics {
+	m := &runnerMetrics{
+		registry: prometheus.NewRegistry(),
+		queries: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "tsbs_query_queries_total",
+			Help: "Number of queries executed, burn-in and prewarm runs and failed queries included.",
+		}),
+		queryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tsbs_query_errors_total",
+			Help: "Number of queries that failed, by the label of the query (with --continue-on-error or --query-timeout).",
+		}, []string{"label"}),
+		queryTimeouts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tsbs_query_timeouts_total",
+			Help: "Number of queries cancelled by --query-timeout, by the label of the query (also counted as errors).",
+		}, []string{"label"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "tsbs_query_inflight_queries",
+			Help: "Number of queries being executed by the workers at the moment.",
+		}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "tsbs_query_duration_seconds",
+			Help:    "Latency of the queries, by the label of the query (or of the part of the query).",
+			Buckets: metrics.LatencyBuckets,
+		}, []string{"label"}),
+	}
+	m.registry.MustRegister(m.queries, m.queryErrors, m.queryTimeouts, m.inFlight, m.queryDuration)
+	return m
+}
+
+// watchQueue registers a gauge with the number of queries waiting for a worker,
+// as reported by depth when scraped
+func (m *runnerMetrics) watchQueue(depth func() int) {
+	if m == nil {
+		return
+	}
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "tsbs_query_queued_queries",
+		Help: "Number of queries read by the scanner that wait for a worker.",
+	}, func() float64 {
+		return float64(depth())
+	}))
+}
+
+// queryStarted and queryDone track a query being executed by a worker
+func (m *runnerMetrics) queryStarted() {
+	if m != nil {
+		m.inFlight.Inc()
+	}
+}
+
+func (m *runnerMetrics) queryDone() {
+	if m != nil {
+		m.inFlight.Dec()
+	}
+}
+
+// observe records the latency of a single stat, in milliseconds, or its failure
+func (m *runnerMetrics) observe(stat *Stat) {
+	if m == nil {
+		return
+	}
+	if stat.isError {
+		m.queryErrors.WithLabelValues(string(stat.label)).Inc()
+		if stat.isTimeout {
+			m.queryTimeouts.WithLabelValues(string(stat.label)).Inc()
+		}
+	} else {
+		m.queryDuration.WithLabelValues(string(stat.label)).Observe(stat.value / 1e3)
+	}
+	if !stat.isPartial {
+		m.queries.Inc()
+	}
+}
+
+// serveMetrics starts serving the live metrics if a listen address is configured
+func (b *BenchmarkRunner) serveMetrics() {
+	if b.metrics == nil {
+		return
+	}
+	ln, err := metrics.Serve(b.MetricsListenAddr, b.metrics.registry)
+	if err != nil {
+		panic(fmt.Sprintf("could not serve metrics on %s: %v", b.MetricsListenAddr, err))
+	}
+	_, _ = fmt.Fprintf(os.Stderr, "serving live metrics on http://%s%s\n", ln.Addr(), metrics.Path)
+}
@@ -0,0 +1,42 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRunnerMetricsObserve(t *testing.T) {
+	m := newRunnerMetrics()
+	m.observe(GetStat().Init([]byte("q1"), 10))
+	m.observe(GetStat().Init([]byte("q1"), 20))
+	m.observe(GetPartialStat().Init([]byte("q1 part"), 5))
+	m.observe(newErrorStat([]byte("q2")))
+
+	if got := testutil.ToFloat64(m.queries); got != 3 {
+		t.Errorf("wrong number of queries: got %f want 3 (partial stats are not queries)", got)
+	}
+	if got := testutil.CollectAndCount(m.queryDuration); got != 2 {
+		t.Errorf("wrong number of labels in the latency histogram: got %d want 2 (failed queries have no latency)", got)
+	}
+	if got := testutil.ToFloat64(m.queryErrors.WithLabelValues("q2")); got != 1 {
+		t.Errorf("wrong number of errors: got %f want 1", got)
+	}
+
+	m.queryStarted()
+	if got := testutil.ToFloat64(m.inFlight); got != 1 {
+		t.Errorf("wrong number of in-flight queries: got %f want 1", got)
+	}
+	m.queryDone()
+	if got := testutil.ToFloat64(m.inFlight); got != 0 {
+		t.Errorf("wrong number of in-flight queries: got %f want 0", got)
+	}
+}
+
+func TestRunnerMetricsDisabled(t *testing.T) {
+	var m *runnerMetrics
+	m.watchQueue(func() int { return 0 })
+	m.queryStarted()
+	m.queryDone()
+	m.observe(GetStat())
+}
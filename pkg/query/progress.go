@@ -0,0 +1,179 @@
+package query
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// Valid values of --report-format
+const (
+	ReportFormatText  = "text"
+	ReportFormatCSV   = "csv"
+	ReportFormatJSONL = "jsonl"
+)
+
+// progressRecord is the state of the run, printed every print-interval queries
+type progressRecord struct {
+	Timestamp         int64                 `json:"timestamp"`
+	Queries           uint64                `json:"queries"`
+	Workers           uint                  `json:"workers"`
+	IntervalQueryRate float64               `json:"intervalQueryRate"`
+	OverallQueryRate  float64               `json:"overallQueryRate"`
+	Labels            map[string]LabelStats `json:"labels"`
+
+	statGroups map[string]*statGroup
+}
+
+// LabelStats are the latency statistics of a single label, in milliseconds
+type LabelStats struct {
+	Count  int64   `json:"count"`
+	Min    float64 `json:"min"`
+	Mean   float64 `json:"mean"`
+	P50    float64 `json:"p50"`
+	P95    float64 `json:"p95"`
+	P99    float64 `json:"p99"`
+	Max    float64 `json:"max"`
+	StdDev float64 `json:"stddev"`
+	// Errors is the number of failed queries, not included in Count and the latencies,
+	// Timeouts the number of those that timed out
+	Errors    int64   `json:"errors"`
+	ErrorRate float64 `json:"errorRate"`
+	Timeouts  int64   `json:"timeouts"`
+}
+
+func newLabelStats(s *statGroup) LabelStats {
+	return LabelStats{
+		Count:     s.count,
+		Min:       s.Min(),
+		Mean:      s.Mean(),
+		P50:       s.Median(),
+		P95:       float64(s.latencyHDRHistogram.ValueAtQuantile(95.0)) / hdrScaleFactor,
+		P99:       float64(s.latencyHDRHistogram.ValueAtQuantile(99.0)) / hdrScaleFactor,
+		Max:       s.Max(),
+		StdDev:    s.StdDev(),
+		Errors:    s.errors,
+		ErrorRate: s.errorRate(),
+		Timeouts:  s.timeouts,
+	}
+}
+
+// progressWriter writes the intermediate stats of a run
+type progressWriter interface {
+	writeHeader() error
+	write(r *progressRecord) error
+}
+
+// newProgressWriter returns the progressWriter for the report format, writing to out
+func newProgressWriter(format string, out io.Writer) (progressWriter, error) {
+	switch format {
+	case "", ReportFormatText:
+		return &textProgressWriter{out: out}, nil
+	case ReportFormatCSV:
+		return &csvProgressWriter{w: csv.NewWriter(out)}, nil
+	case ReportFormatJSONL:
+		return &jsonlProgressWriter{enc: json.NewEncoder(out)}, nil
+	default:
+		return nil, fmt.Errorf("invalid report format '%s', valid formats: %s, %s, %s", format, ReportFormatText, ReportFormatCSV, ReportFormatJSONL)
+	}
+}
+
+// textProgressWriter writes the human readable stats of every label
+type textProgressWriter struct {
+	out io.Writer
+}
+
+func (w *textProgressWriter) writeHeader() error {
+	return nil
+}
+
+func (w *textProgressWriter) write(r *progressRecord) error {
+	_, err := fmt.Fprintf(w.out, "After %d queries with %d workers:\nInterval query rate: %0.2f queries/sec\tOverall query rate: %0.2f queries/sec\n",
+		r.Queries,
+		r.Workers,
+		r.IntervalQueryRate,
+		r.OverallQueryRate,
+	)
+	if err != nil {
+		return err
+	}
+	err = writeStatGroupMap(w.out, r.statGroups, nil)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w.out, "\n")
+	return err
+}
+
+// csvProgressWriter writes one CSV line per label for every record
+type csvProgressWriter struct {
+	w *csv.Writer
+}
+
+func (w *csvProgressWriter) writeHeader() error {
+	return w.flush([][]string{{"timestamp", "queries", "workers", "interval_query_rate", "overall_query_rate",
+		"label", "count", "min_ms", "mean_ms", "p50_ms", "p95_ms", "p99_ms", "max_ms", "stddev_ms", "errors", "error_rate", "timeouts"}})
+}
+
+func (w *csvProgressWriter) write(r *progressRecord) error {
+	formatFloat := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	labels := make([]string, 0, len(r.Labels))
+	for label := range r.Labels {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	records := make([][]string, 0, len(labels))
+	for _, label := range labels {
+		p := r.Labels[label]
+		records = append(records, []string{
+			strconv.FormatInt(r.Timestamp, 10),
+			strconv.FormatUint(r.Queries, 10),
+			strconv.FormatUint(uint64(r.Workers), 10),
+			formatFloat(r.IntervalQueryRate),
+			formatFloat(r.OverallQueryRate),
+			label,
+			strconv.FormatInt(p.Count, 10),
+			formatFloat(p.Min),
+			formatFloat(p.Mean),
+			formatFloat(p.P50),
+			formatFloat(p.P95),
+			formatFloat(p.P99),
+			formatFloat(p.Max),
+			formatFloat(p.StdDev),
+			strconv.FormatInt(p.Errors, 10),
+			formatFloat(p.ErrorRate),
+			strconv.FormatInt(p.Timeouts, 10),
+		})
+	}
+	return w.flush(records)
+}
+
+// flush writes the records right away, so the file can be followed during the run
+func (w *csvProgressWriter) flush(records [][]string) error {
+	for _, record := range records {
+		if err := w.w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// jsonlProgressWriter writes every record as a JSON object on its own line
+type jsonlProgressWriter struct {
+	enc *json.Encoder
+}
+
+func (w *jsonlProgressWriter) writeHeader() error {
+	return nil
+}
+
+func (w *jsonlProgressWriter) write(r *progressRecord) error {
+	return w.enc.Encode(r)
+}
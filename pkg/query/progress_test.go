@@ -0,0 +1,79 @@
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func testProgressRecord() *progressRecord {
+	sg := newStatGroup(0)
+	sg.push(1)
+	sg.push(3)
+	statGroups := map[string]*statGroup{LabelAllQueries: sg, "q1": sg}
+	r := &progressRecord{
+		Timestamp:         100,
+		Queries:           2,
+		Workers:           1,
+		IntervalQueryRate: 4,
+		OverallQueryRate:  2,
+		Labels:            make(map[string]LabelStats),
+		statGroups:        statGroups,
+	}
+	for label, sg := range statGroups {
+		r.Labels[label] = newLabelStats(sg)
+	}
+	return r
+}
+
+func TestNewProgressWriterInvalidFormat(t *testing.T) {
+	if _, err := newProgressWriter("xml", &bytes.Buffer{}); err == nil {
+		t.Errorf("expected error for an invalid format")
+	}
+}
+
+func TestCSVProgressWriter(t *testing.T) {
+	var b bytes.Buffer
+	w, _ := newProgressWriter(ReportFormatCSV, &b)
+	if err := w.writeHeader(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := w.write(testProgressRecord()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{
+		"timestamp,queries,workers,interval_query_rate,overall_query_rate,label,count,min_ms,mean_ms,p50_ms,p95_ms,p99_ms,max_ms,stddev_ms,errors,error_rate,timeouts",
+		"100,2,1,4,2,all queries,2,1,2,1,3,3,3,1,0,0,0",
+		"100,2,1,4,2,q1,2,1,2,1,3,3,3,1,0,0,0",
+	}
+	if got := strings.TrimSpace(b.String()); got != strings.Join(want, "\n") {
+		t.Errorf("incorrect csv\ngot\n%s\nwant\n%s", got, strings.Join(want, "\n"))
+	}
+}
+
+func TestJSONLProgressWriter(t *testing.T) {
+	var b bytes.Buffer
+	w, _ := newProgressWriter(ReportFormatJSONL, &b)
+	if err := w.write(testProgressRecord()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got progressRecord
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if got.Queries != 2 || got.Labels["q1"].Count != 2 || got.Labels["q1"].Max != 3 {
+		t.Errorf("wrong record: %+v", got)
+	}
+}
+
+func TestTextProgressWriter(t *testing.T) {
+	var b bytes.Buffer
+	w, _ := newProgressWriter(ReportFormatText, &b)
+	if err := w.write(testProgressRecord()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(b.String(), "After 2 queries with 1 workers:\nInterval query rate: 4.00 queries/sec") {
+		t.Errorf("wrong text output:\n%s", b.String())
+	}
+}
@@ -0,0 +1,91 @@
+package query
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io"
+	"math/rand"
+)
+
+// queryReplay keeps the queries read from the input, gob-encoded, to send
+// them again in the following passes of a looping run.
+type queryReplay struct {
+	buf bytes.Buffer
+	enc *gob.Encoder
+	// types has the type definitions gob sends before the first value
+	types []byte
+	// queries has the encoded values of the queries, in the order they were read
+	queries [][]byte
+	// rand shuffles the queries of the passes, nil keeps the order
+	rand *rand.Rand
+}
+
+// newQueryReplay returns a new queryReplay, shuffling the queries of every
+// replay if shuffle is set
+func newQueryReplay(shuffle bool, seed int64) *queryReplay {
+	r := &queryReplay{}
+	r.enc = gob.NewEncoder(&r.buf)
+	if shuffle {
+		r.rand = rand.New(rand.NewSource(seed))
+	}
+	return r
+}
+
+// add records a query read from the input
+func (r *queryReplay) add(q Query) error {
+	r.buf.Reset()
+	if err := r.enc.Encode(q); err != nil {
+		return err
+	}
+	if r.types == nil {
+		// The first message also holds the type definitions, encoding the query
+		// again gives its value alone and tells them apart.
+		first := append([]byte(nil), r.buf.Bytes()...)
+		r.buf.Reset()
+		if err := r.enc.Encode(q); err != nil {
+			return err
+		}
+		r.types = first[:len(first)-r.buf.Len()]
+	}
+	r.queries = append(r.queries, append([]byte(nil), r.buf.Bytes()...))
+	return nil
+}
+
+// len returns the number of queries recorded
+func (r *queryReplay) len() int {
+	return len(r.queries)
+}
+
+// reader returns the gob stream of the next pass over the queries recorded,
+// in the order they were read or shuffled.
+func (r *queryReplay) reader() io.Reader {
+	order := make([]int, len(r.queries))
+	for i := range order {
+		order[i] = i
+	}
+	if r.rand != nil {
+		r.rand.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+	}
+	return &replayReader{replay: r, order: order, next: r.types}
+}
+
+// replayReader reads the type definitions then the queries of a replay in order
+type replayReader struct {
+	replay *queryReplay
+	order  []int
+	// next is what remains to be read of the current message
+	next []byte
+}
+
+func (rr *replayReader) Read(p []byte) (int, error) {
+	for len(rr.next) == 0 {
+		if len(rr.order) == 0 {
+			return 0, io.EOF
+		}
+		rr.next = rr.replay.queries[rr.order[0]]
+		rr.order = rr.order[1:]
+	}
+	n := copy(p, rr.next)
+	rr.next = rr.next[n:]
+	return n, nil
+}
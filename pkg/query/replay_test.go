@@ -0,0 +1,72 @@
+package query
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestQueryReplay(t *testing.T) {
+	cases := []struct {
+		desc    string
+		shuffle bool
+	}{
+		{desc: "in order"},
+		{desc: "shuffled", shuffle: true},
+	}
+	for _, c := range cases {
+		replay := newQueryReplay(c.shuffle, 42)
+		var want []string
+		for i := 0; i < 20; i++ {
+			q := &testQuery{ID: uint64(i), HumanLabel: []byte(fmt.Sprintf("q%d", i))}
+			if err := replay.add(q); err != nil {
+				t.Fatalf("%s: unexpected error: %v", c.desc, err)
+			}
+			want = append(want, string(q.HumanLabel))
+		}
+		if replay.len() != 20 {
+			t.Errorf("%s: wrong number of queries: got %d want 20", c.desc, replay.len())
+		}
+
+		// every pass decodes all the queries
+		var passes [][]string
+		for pass := 0; pass < 2; pass++ {
+			dec := gob.NewDecoder(replay.reader())
+			var got []string
+			for {
+				q := &testQuery{}
+				err := dec.Decode(q)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("%s: cannot decode the replay: %v", c.desc, err)
+				}
+				got = append(got, string(q.HumanLabel))
+			}
+			passes = append(passes, got)
+		}
+		for i, got := range passes {
+			if c.shuffle {
+				if reflect.DeepEqual(got, want) {
+					t.Errorf("%s: pass %d is not shuffled", c.desc, i)
+				}
+				got = append([]string(nil), got...)
+				sort.Strings(got)
+				sorted := append([]string(nil), want...)
+				sort.Strings(sorted)
+				if !reflect.DeepEqual(got, sorted) {
+					t.Errorf("%s: pass %d doesn't have all the queries: %v", c.desc, i, got)
+				}
+			} else if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: pass %d: got %v want %v", c.desc, i, got, want)
+			}
+		}
+		if c.shuffle && reflect.DeepEqual(passes[0], passes[1]) {
+			t.Errorf("%s: the passes are shuffled the same way", c.desc)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package query
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const defaultResultPrecision = 9
+
+// Result is the normalized result of a query, so it can be compared with the
+// result of the same query (same ID) on another database: the values are in a
+// canonical form and the rows are sorted.
+type Result struct {
+	ID    uint64          `json:"id"`
+	Label string          `json:"label"`
+	Rows  [][]interface{} `json:"rows"`
+}
+
+// NewResult normalizes the rows returned by q. Timestamps (time.Time or RFC3339
+// strings) become RFC3339 strings in UTC, numbers (numeric strings included) are
+// rounded to precision significant digits and the rows are sorted.
+func NewResult(q Query, rows [][]interface{}, precision int) *Result {
+	r := &Result{ID: q.GetID(), Label: string(q.HumanLabelName()), Rows: make([][]interface{}, len(rows))}
+	for i, row := range rows {
+		normalized := make([]interface{}, len(row))
+		for j, v := range row {
+			normalized[j] = normalizeValue(v, precision)
+		}
+		r.Rows[i] = normalized
+	}
+	r.sortRows()
+	return r
+}
+
+// sortRows sorts the rows by their JSON encoding, the order databases return
+// rows in is only comparable for ordered queries
+func (r *Result) sortRows() {
+	keys := make([]string, len(r.Rows))
+	for i, row := range r.Rows {
+		b, _ := json.Marshal(row)
+		keys[i] = string(b)
+	}
+	sort.Sort(&rowSorter{keys: keys, rows: r.Rows})
+}
+
+type rowSorter struct {
+	keys []string
+	rows [][]interface{}
+}
+
+func (s *rowSorter) Len() int           { return len(s.rows) }
+func (s *rowSorter) Less(i, j int) bool { return s.keys[i] < s.keys[j] }
+func (s *rowSorter) Swap(i, j int) {
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	s.rows[i], s.rows[j] = s.rows[j], s.rows[i]
+}
+
+// normalizeValue returns the canonical form of a single value of a result
+func normalizeValue(v interface{}, precision int) interface{} {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case *time.Time:
+		if v == nil {
+			return nil
+		}
+		return normalizeValue(*v, precision)
+	case []byte:
+		return normalizeValue(string(v), precision)
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return normalizeValue(t, precision)
+		}
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return roundFloat(f, precision)
+		}
+		return v
+	case json.Number:
+		return normalizeValue(string(v), precision)
+	case bool:
+		return v
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return roundFloat(rv.Float(), precision)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return roundFloat(float64(rv.Int()), precision)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return roundFloat(float64(rv.Uint()), precision)
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil
+		}
+		return normalizeValue(rv.Elem().Interface(), precision)
+	}
+	return fmt.Sprint(v)
+}
+
+// roundFloat rounds f to precision significant digits, NaN and infinities
+// can't be encoded in JSON and are returned as strings
+func roundFloat(f float64, precision int) interface{} {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	if precision <= 0 {
+		precision = defaultResultPrecision
+	}
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(f, 'g', precision, 64), 64)
+	return rounded
+}
+
+// ScanRows reads all the rows of a database/sql query result, without closing them
+func ScanRows(rows *sql.Rows) ([][]interface{}, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var result [][]interface{}
+	for rows.Next() {
+		values := make([]interface{}, len(cols))
+		for i := range values {
+			values[i] = new(interface{})
+		}
+		if err := rows.Scan(values...); err != nil {
+			return nil, err
+		}
+		row := make([]interface{}, len(cols))
+		for i := range values {
+			row[i] = *values[i].(*interface{})
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// resultsCapture writes the results of the queries to a file, one JSON object
+// per line. The workers write concurrently, so the lines are not sorted by ID.
+type resultsCapture struct {
+	mu        sync.Mutex
+	file      *os.File
+	w         *bufio.Writer
+	enc       *json.Encoder
+	precision int
+}
+
+func newResultsCapture(fileName string, precision int) (*resultsCapture, error) {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return nil, err
+	}
+	w := bufio.NewWriter(file)
+	return &resultsCapture{file: file, w: w, enc: json.NewEncoder(w), precision: precision}, nil
+}
+
+func (c *resultsCapture) write(q Query, rows [][]interface{}) error {
+	r := NewResult(q, rows, c.precision)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.enc.Encode(r)
+}
+
+func (c *resultsCapture) close() error {
+	if err := c.w.Flush(); err != nil {
+		return err
+	}
+	return c.file.Close()
+}
+
+// ReadResults reads the results captured with --capture-results, by query ID
+func ReadResults(r io.Reader) (map[uint64]*Result, error) {
+	results := make(map[uint64]*Result)
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	for {
+		result := &Result{}
+		err := dec.Decode(result)
+		if err == io.EOF {
+			return results, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := results[result.ID]; ok {
+			return nil, fmt.Errorf("duplicate result for query %d", result.ID)
+		}
+		results[result.ID] = result
+	}
+}
+
+// ResultDiff describes why the results of a query don't match
+type ResultDiff struct {
+	ID     uint64
+	Label  string
+	Reason string
+}
+
+// DiffResults compares the actual results with the expected ones, query by query.
+// With precision > 0 the numbers are rounded again before comparing, to compare
+// captures taken with a higher precision. Queries missing from actual are
+// differences, extra queries are ignored unless strict is set.
+func DiffResults(expected, actual map[uint64]*Result, precision int, strict bool) []ResultDiff {
+	ids := make([]uint64, 0, len(expected))
+	for id := range expected {
+		ids = append(ids, id)
+	}
+	if strict {
+		for id := range actual {
+			if _, ok := expected[id]; !ok {
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var diffs []ResultDiff
+	for _, id := range ids {
+		e, a := expected[id], actual[id]
+		switch {
+		case e == nil:
+			diffs = append(diffs, ResultDiff{ID: id, Label: a.Label, Reason: "not in the expected results"})
+		case a == nil:
+			diffs = append(diffs, ResultDiff{ID: id, Label: e.Label, Reason: "not in the actual results"})
+		case e.Label != a.Label:
+			diffs = append(diffs, ResultDiff{ID: id, Label: e.Label, Reason: fmt.Sprintf("different query, actual label is '%s'", a.Label)})
+		default:
+			if reason := diffRows(e.rounded(precision), a.rounded(precision)); reason != "" {
+				diffs = append(diffs, ResultDiff{ID: id, Label: e.Label, Reason: reason})
+			}
+		}
+	}
+	return diffs
+}
+
+// rounded returns the result with its values normalized again with precision,
+// or the result itself if precision is 0
+func (r *Result) rounded(precision int) *Result {
+	if precision <= 0 {
+		return r
+	}
+	rows := make([][]interface{}, len(r.Rows))
+	copy(rows, r.Rows)
+	rounded := &Result{ID: r.ID, Label: r.Label, Rows: rows}
+	for i, row := range rows {
+		normalized := make([]interface{}, len(row))
+		for j, v := range row {
+			normalized[j] = normalizeValue(v, precision)
+		}
+		rows[i] = normalized
+	}
+	rounded.sortRows()
+	return rounded
+}
+
+// diffRows returns why the rows differ, or an empty string if they are the same
+func diffRows(expected, actual *Result) string {
+	if len(expected.Rows) != len(actual.Rows) {
+		return fmt.Sprintf("%d rows, expected %d", len(actual.Rows), len(expected.Rows))
+	}
+	for i := range expected.Rows {
+		e, _ := json.Marshal(expected.Rows[i])
+		a, _ := json.Marshal(actual.Rows[i])
+		if string(e) != string(a) {
+			return fmt.Sprintf("row %d is %s, expected %s", i, a, e)
+		}
+	}
+	return ""
+}
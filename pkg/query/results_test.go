@@ -0,0 +1,165 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testResultQuery(id uint64, label string) Query {
+	q := NewTimescaleDB()
+	q.SetID(id)
+	q.HumanLabel = []byte(label)
+	return q
+}
+
+func TestNormalizeValue(t *testing.T) {
+	ts := time.Date(2016, 1, 1, 1, 0, 0, 0, time.FixedZone("CET", 3600))
+	f := 1.23456789
+	cases := []struct {
+		desc string
+		in   interface{}
+		want interface{}
+	}{
+		{desc: "nil", in: nil, want: nil},
+		{desc: "time in UTC", in: ts, want: "2016-01-01T00:00:00Z"},
+		{desc: "RFC3339 string", in: "2016-01-01T01:00:00+01:00", want: "2016-01-01T00:00:00Z"},
+		{desc: "rounded float", in: 2.0 / 3, want: 0.666666667},
+		{desc: "int", in: int64(42), want: 42.0},
+		{desc: "uint", in: uint32(42), want: 42.0},
+		{desc: "numeric string", in: "42.5", want: 42.5},
+		{desc: "json number", in: json.Number("7"), want: 7.0},
+		{desc: "bytes", in: []byte("host_1"), want: "host_1"},
+		{desc: "pointer", in: &f, want: 1.23456789},
+		{desc: "bool", in: true, want: true},
+		{desc: "NaN", in: nan(), want: "NaN"},
+	}
+	for _, c := range cases {
+		if got := normalizeValue(c.in, 9); got != c.want {
+			t.Errorf("%s: got %v (%T) want %v (%T)", c.desc, got, got, c.want, c.want)
+		}
+	}
+}
+
+func nan() float64 {
+	zero := 0.0
+	return zero / zero
+}
+
+func TestNewResultSortsRows(t *testing.T) {
+	rows := [][]interface{}{{"host_2", 2}, {"host_1", 1.0000000001}}
+	r := NewResult(testResultQuery(3, "q"), rows, 9)
+	got, _ := json.Marshal(r)
+	want := `{"id":3,"label":"q","rows":[["host_1",1],["host_2",2]]}`
+	if string(got) != want {
+		t.Errorf("got %s want %s", got, want)
+	}
+}
+
+func TestResultsCaptureRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsbs-results")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "results.jsonl")
+
+	c, err := newResultsCapture(fileName, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = c.write(testResultQuery(1, "q1"), [][]interface{}{{1.23456}}); err != nil {
+		t.Fatal(err)
+	}
+	if err = c.write(testResultQuery(2, "q2"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err = c.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	results, err := ReadResults(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("wrong number of results: got %d want 2", len(results))
+	}
+	if got := results[1].Rows[0][0]; got != json.Number("1.235") {
+		t.Errorf("value not rounded to the capture precision: got %v", got)
+	}
+	if results[2].Label != "q2" || len(results[2].Rows) != 0 {
+		t.Errorf("unexpected result: %+v", results[2])
+	}
+}
+
+func TestReadResultsDuplicate(t *testing.T) {
+	in := `{"id":1,"label":"q","rows":[]}
+{"id":1,"label":"q","rows":[]}
+`
+	if _, err := ReadResults(strings.NewReader(in)); err == nil {
+		t.Errorf("expected error for duplicate query IDs")
+	}
+}
+
+func TestDiffResults(t *testing.T) {
+	read := func(in string) map[uint64]*Result {
+		results, err := ReadResults(strings.NewReader(in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return results
+	}
+	expected := read(`{"id":1,"label":"q1","rows":[["a",1.0001]]}
+{"id":2,"label":"q2","rows":[["a",1],["b",2]]}
+{"id":3,"label":"q3","rows":[]}
+`)
+	actual := read(`{"id":1,"label":"q1","rows":[["a",1.0002]]}
+{"id":2,"label":"q2","rows":[["a",1]]}
+{"id":4,"label":"q4","rows":[]}
+`)
+	cases := []struct {
+		desc      string
+		precision int
+		strict    bool
+		want      []string
+	}{
+		{
+			desc: "as captured",
+			want: []string{"1:row 0", "2:1 rows, expected 2", "3:not in the actual results"},
+		},
+		{
+			desc:      "lower precision",
+			precision: 3,
+			want:      []string{"2:1 rows, expected 2", "3:not in the actual results"},
+		},
+		{
+			desc:      "strict",
+			precision: 3,
+			strict:    true,
+			want:      []string{"2:1 rows, expected 2", "3:not in the actual results", "4:not in the expected results"},
+		},
+	}
+	for _, c := range cases {
+		diffs := DiffResults(expected, actual, c.precision, c.strict)
+		if len(diffs) != len(c.want) {
+			t.Errorf("%s: got %d diffs want %d: %+v", c.desc, len(diffs), len(c.want), diffs)
+			continue
+		}
+		for i, d := range diffs {
+			if got := fmt.Sprintf("%d:%s", d.ID, d.Reason); !strings.HasPrefix(got, c.want[i]) {
+				t.Errorf("%s: diff %d got %s want %s", c.desc, i, got, c.want[i])
+			}
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package query
 
 import (
-	"encoding/gob"
 	"io"
 	"log"
 	"sync"
@@ -10,13 +9,19 @@ import (
 // scanner is used to read in Queries from a Reader where they are
 // Go-encoded and then distribute them to workers
 type scanner struct {
-	r     io.Reader
-	limit *uint64
+	r       io.Reader
+	format  string
+	limit   *uint64
+	stopped func() bool
+	// n is the number of queries sent so far, by all the scans
+	n uint64
+	// replay records the queries read, to loop over them, if set
+	replay *queryReplay
 }
 
 // newScanner returns a new scanner for a given Reader and its limit
 func newScanner(limit *uint64) *scanner {
-	return &scanner{limit: limit}
+	return &scanner{limit: limit, format: FormatGob}
 }
 
 // setReader sets the source, an io.Reader, that the scanner reads/decodes from
@@ -25,33 +30,64 @@ func (s *scanner) setReader(r io.Reader) *scanner {
 	return s
 }
 
-// scan reads encoded Queries and places them into a channel
-func (s *scanner) scan(pool *sync.Pool, c chan Query) {
-	decoder := gob.NewDecoder(s.r)
+// setFormat sets the format of the queries read, gob by default
+func (s *scanner) setFormat(format string) *scanner {
+	s.format = format
+	return s
+}
+
+// setStop sets the function telling the scanner to stop reading before the end of the input
+func (s *scanner) setStop(stopped func() bool) *scanner {
+	s.stopped = stopped
+	return s
+}
+
+// setReplay sets the replay recording the queries read, nil to stop recording
+func (s *scanner) setReplay(replay *queryReplay) *scanner {
+	s.replay = replay
+	return s
+}
+
+// scan reads encoded Queries and places them into a channel. It returns true
+// if it read all the queries of the source, false if it stopped before. The
+// IDs of the queries continue from the previous scan.
+func (s *scanner) scan(pool *sync.Pool, c chan Query) bool {
+	decoder, err := NewDecoder(s.format, s.r)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	n := uint64(0)
 	for {
-		if *s.limit > 0 && n >= *s.limit {
+		if *s.limit > 0 && s.n >= *s.limit {
 			// request queries limit reached, time to quit
-			break
+			return false
+		}
+		if s.stopped != nil && s.stopped() {
+			// run interrupted, the queries sent so far are still executed
+			return false
 		}
 
 		q := pool.Get().(Query)
 		err := decoder.Decode(q)
 		if err == io.EOF {
 			// EOF, all done
-			break
+			return true
 		}
 		if err != nil {
 			// Can't read, time to quit
 			log.Fatal(err)
 		}
+		if s.replay != nil {
+			if err = s.replay.add(q); err != nil {
+				log.Fatal(err)
+			}
+		}
 
 		// We have a query, send it to the runner
-		q.SetID(n)
+		q.SetID(s.n)
 		c <- q
 
 		// Queries counter
-		n++
+		s.n++
 	}
 }
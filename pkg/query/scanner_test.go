@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"encoding/gob"
 	"fmt"
+	"strings"
 	"sync"
 	"testing"
 )
@@ -58,7 +59,7 @@ func runScan(t *testing.T, b *bytes.Buffer, limit, numQueries uint64, pool *sync
 		for q := range queryChan {
 			err := chk(i, q)
 			if err != nil {
-				t.Fatalf(err.Error())
+				t.Error(err)
 			}
 			i++
 			got++
@@ -150,3 +151,54 @@ func TestScanTimescaleDB(t *testing.T) {
 		return nil
 	})
 }
+
+func TestScannerStop(t *testing.T) {
+	var b bytes.Buffer
+	err := encodeQueries(&b, 5, func(i uint64) Query {
+		return &testQuery{HumanLabel: []byte("testlabel")}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	limit := uint64(0)
+	queryChan := make(chan Query, 5)
+	stop := func() bool { return len(queryChan) == 2 }
+	newScanner(&limit).setReader(&b).setStop(stop).scan(&testQueryPool, queryChan)
+	close(queryChan)
+	got := 0
+	for range queryChan {
+		got++
+	}
+	if got != 2 {
+		t.Errorf("wrong number of queries after stopping: got %d want 2", got)
+	}
+}
+
+func TestScannerJSONL(t *testing.T) {
+	input := `{"HumanLabel":"q1","SqlQuery":"SELECT 1"}
+# added by hand
+{"HumanLabel":"q2","SqlQuery":"SELECT 2"}
+`
+	limit := uint64(0)
+	queryChan := make(chan Query, 2)
+	done := newScanner(&limit).setReader(strings.NewReader(input)).setFormat(FormatJSONL).scan(&TimescaleDBPool, queryChan)
+	close(queryChan)
+	if !done {
+		t.Errorf("the scanner did not read all the queries")
+	}
+	i := uint64(0)
+	for q := range queryChan {
+		tq := q.(*TimescaleDB)
+		if got, want := string(tq.SqlQuery), fmt.Sprintf("SELECT %d", i+1); got != want {
+			t.Errorf("wrong query: got %s want %s", got, want)
+		}
+		if tq.GetID() != i {
+			t.Errorf("wrong ID for query: got %d want %d", tq.GetID(), i)
+		}
+		i++
+	}
+	if i != 2 {
+		t.Errorf("wrong number of queries: got %d want 2", i)
+	}
+}
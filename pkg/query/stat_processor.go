@@ -5,10 +5,12 @@ import (
 	"bytes"
 	"fmt"
 	"github.com/HdrHistogram/hdrhistogram-go"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"regexp"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -19,17 +21,27 @@ type statProcessor interface {
 	getArgs() *statProcessorArgs
 	send(stats []*Stat)
 	sendWarm(stats []*Stat)
+	open(workers uint)
 	process(workers uint)
 	CloseAndWait()
 	GetTotalsMap() map[string]interface{}
+	labelStats() map[string]LabelStats
+	percentile(label string, p float64) (float64, bool)
 }
 
 type statProcessorArgs struct {
-	prewarmQueries   bool    // PrewarmQueries tells the StatProcessor whether we're running each query twice to prewarm the cache
-	limit            *uint64 // limit is the number of statistics to analyze before stopping
-	burnIn           uint64  // burnIn is the number of statistics to ignore before analyzing
-	printInterval    uint64  // printInterval is how often print intermediate stats (number of queries)
-	hdrLatenciesFile string  // hdrLatenciesFile is the filename to Write the High Dynamic Range (HDR) Histogram of Response Latencies to
+	prewarmQueries   bool           // PrewarmQueries tells the StatProcessor whether we're running each query twice to prewarm the cache
+	limit            *uint64        // limit is the number of statistics to analyze before stopping
+	burnIn           uint64         // burnIn is the number of statistics to ignore before analyzing
+	printInterval    uint64         // printInterval is how often print intermediate stats (number of queries)
+	hdrLatenciesFile string         // hdrLatenciesFile is the filename to Write the High Dynamic Range (HDR) Histogram of Response Latencies to
+	metrics          *runnerMetrics // metrics are the live metrics updated with every stat, nil if disabled
+	reportFormat     string         // reportFormat is the format of the intermediate stats: text, csv or jsonl
+	reportFile       string         // reportFile is the file to write the intermediate stats to instead of stderr
+	openLoop         bool           // openLoop tells the StatProcessor to report the response times of the queries, from their intended start
+	percentiles      []float64      // percentiles are the percentiles of the latencies reported per label
+	hdrLatenciesDir  string         // hdrLatenciesDir is the directory to write one HdrHistogram log file per label to
+	loop             bool           // loop tells the StatProcessor to also report the stats of every pass over the queries
 
 }
 
@@ -42,6 +54,33 @@ type defaultStatProcessor struct {
 	startTime   time.Time
 	endTime     time.Time
 	statMapping map[string]*statGroup
+	// responseMapping has the response times of the queries in open loop: their
+	// latency (service time) plus the time they waited to start
+	responseMapping map[string]*statGroup
+	// hdrMapping has the latencies of each label for cold and warm queries
+	// separately, for the HDR log files of a run with prewarmed queries
+	hdrMapping map[string]*statGroup
+	// passWindows has the stats of the passes of a looping run not reported yet
+	passWindows map[uint64]*passWindow
+	// passes has the stats of the passes reported
+	passes []PassStats
+}
+
+// passWindow collects the stats of one pass over the queries of a looping run
+type passWindow struct {
+	pass        uint64
+	queries     uint64
+	start       time.Time
+	end         time.Time
+	statMapping map[string]*statGroup
+}
+
+// PassStats are the statistics of one pass over the queries of a looping run
+type PassStats struct {
+	Pass    uint64                `json:"pass"`
+	Queries uint64                `json:"queries"`
+	Seconds float64               `json:"seconds"`
+	Labels  map[string]LabelStats `json:"labels"`
 }
 
 func newStatProcessor(args *statProcessorArgs) statProcessor {
@@ -76,12 +115,17 @@ func (sp *defaultStatProcessor) sendWarm(stats []*Stat) {
 	sp.send(stats)
 }
 
+// open creates the channel the stats are sent to. It must be called before
+// the stats are sent and process is started.
+func (sp *defaultStatProcessor) open(workers uint) {
+	sp.c = make(chan *Stat, workers)
+	sp.wg.Add(1)
+}
+
 // process collects latency results, aggregating them into summary
 // statistics. Optionally, they are printed to stderr at regular intervals.
 func (sp *defaultStatProcessor) process(workers uint) {
-	sp.c = make(chan *Stat, workers)
-	sp.wg.Add(1)
-	const allQueriesLabel = labelAllQueries
+	const allQueriesLabel = LabelAllQueries
 	sp.statMapping = map[string]*statGroup{
 		allQueriesLabel: newStatGroup(*sp.args.limit),
 	}
@@ -90,6 +134,20 @@ func (sp *defaultStatProcessor) process(workers uint) {
 		sp.statMapping[labelColdQueries] = newStatGroup(*sp.args.limit)
 		sp.statMapping[labelWarmQueries] = newStatGroup(*sp.args.limit)
 	}
+	if sp.args.openLoop {
+		sp.responseMapping = map[string]*statGroup{
+			allQueriesLabel: newStatGroup(*sp.args.limit),
+		}
+	}
+	if len(sp.args.hdrLatenciesDir) > 0 && sp.args.prewarmQueries {
+		sp.hdrMapping = map[string]*statGroup{}
+	}
+	if sp.args.loop {
+		sp.passWindows = map[uint64]*passWindow{}
+	}
+
+	progress, closeProgress := sp.newProgressWriter()
+	defer closeProgress()
 
 	i := uint64(0)
 	sp.startTime = time.Now()
@@ -98,6 +156,7 @@ func (sp *defaultStatProcessor) process(workers uint) {
 
 	for stat := range sp.c {
 		atomic.AddUint64(&sp.opsCount, 1)
+		sp.args.metrics.observe(stat)
 		if i < sp.args.burnIn {
 			i++
 			statPool.Put(stat)
@@ -108,24 +167,23 @@ func (sp *defaultStatProcessor) process(workers uint) {
 				log.Fatal(err)
 			}
 		}
-		if _, ok := sp.statMapping[string(stat.label)]; !ok {
-			sp.statMapping[string(stat.label)] = newStatGroup(*sp.args.limit)
+		for _, sg := range sp.statGroupsOf(sp.statMapping, stat) {
+			sg.pushStat(stat)
 		}
-
-		sp.statMapping[string(stat.label)].push(stat.value)
-
-		if !stat.isPartial {
-			sp.statMapping[allQueriesLabel].push(stat.value)
-
-			// Only needed when differentiating between cold & warm
-			if sp.args.prewarmQueries {
-				if stat.isWarm {
-					sp.statMapping[labelWarmQueries].push(stat.value)
-				} else {
-					sp.statMapping[labelColdQueries].push(stat.value)
-				}
+		// the response time of a query includes its delay, not the one of its parts
+		if sp.args.openLoop && !stat.isPartial && !stat.isError {
+			for _, sg := range sp.statGroupsOf(sp.responseMapping, stat) {
+				sg.push(stat.value + stat.delay)
 			}
+		}
+		if sp.hdrMapping != nil && !stat.isError {
+			sp.hdrGroupOf(stat).push(stat.value)
+		}
+		if sp.passWindows != nil && stat.pass > 0 {
+			sp.pushPassStat(stat)
+		}
 
+		if !stat.isPartial {
 			// If we're prewarming queries (i.e., running them twice in a row),
 			// only increment the counter for the first (cold) query. Otherwise,
 			// increment for every query.
@@ -141,29 +199,26 @@ func (sp *defaultStatProcessor) process(workers uint) {
 			now := time.Now()
 			sinceStart := now.Sub(sp.startTime)
 			took := now.Sub(prevTime)
-			intervalQueryRate := float64(sp.opsCount-prevRequestCount) / float64(took.Seconds())
-			overallQueryRate := float64(sp.opsCount) / float64(sinceStart.Seconds())
-			_, err := fmt.Fprintf(os.Stderr, "After %d queries with %d workers:\nInterval query rate: %0.2f queries/sec\tOverall query rate: %0.2f queries/sec\n",
-				i-sp.args.burnIn,
-				workers,
-				intervalQueryRate,
-				overallQueryRate,
-			)
-			if err != nil {
-				log.Fatal(err)
+			r := &progressRecord{
+				Timestamp:         now.Unix(),
+				Queries:           i - sp.args.burnIn,
+				Workers:           workers,
+				IntervalQueryRate: float64(sp.opsCount-prevRequestCount) / float64(took.Seconds()),
+				OverallQueryRate:  float64(sp.opsCount) / float64(sinceStart.Seconds()),
+				Labels:            make(map[string]LabelStats, len(sp.statMapping)),
+				statGroups:        sp.statMapping,
 			}
-			err = writeStatGroupMap(os.Stderr, sp.statMapping)
-			if err != nil {
-				log.Fatal(err)
+			for label, sg := range sp.statMapping {
+				r.Labels[label] = newLabelStats(sg)
 			}
-			_, err = fmt.Fprintf(os.Stderr, "\n")
-			if err != nil {
+			if err := progress.write(r); err != nil {
 				log.Fatal(err)
 			}
 			prevRequestCount = sp.opsCount
 			prevTime = now
 		}
 	}
+	sp.flushPassWindows(0)
 	sinceStart := time.Now().Sub(sp.startTime)
 	overallQueryRate := float64(sp.opsCount) / float64(sinceStart.Seconds())
 	// the final stats output goes to stdout:
@@ -171,10 +226,25 @@ func (sp *defaultStatProcessor) process(workers uint) {
 	if err != nil {
 		log.Fatal(err)
 	}
-	err = writeStatGroupMap(os.Stdout, sp.statMapping)
+	if all := sp.statMapping[allQueriesLabel]; all.errors > 0 {
+		_, err = fmt.Printf("%d queries failed (error rate %0.2f%%), %d of them timed out\n", all.errors, all.errorRate()*100, all.timeouts)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	err = writeStatGroupMap(os.Stdout, sp.statMapping, sp.args.percentiles)
 	if err != nil {
 		log.Fatal(err)
 	}
+	if sp.args.openLoop {
+		_, err = fmt.Printf("Response times (service time plus the wait from the intended start time):\n")
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err = writeStatGroupMap(os.Stdout, sp.responseMapping, sp.args.percentiles); err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	if len(sp.args.hdrLatenciesFile) > 0 {
 		_, _ = fmt.Printf("Saving High Dynamic Range (HDR) Histogram of Response Latencies to %s\n", sp.args.hdrLatenciesFile)
@@ -190,10 +260,172 @@ func (sp *defaultStatProcessor) process(workers uint) {
 		}
 
 	}
+	if len(sp.args.hdrLatenciesDir) > 0 {
+		if err = sp.writeHDRLogs(time.Now()); err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	sp.wg.Done()
 }
 
+// statGroupsOf returns the groups of mapping the stat is counted in: the one of
+// its label (created if needed) and, if it isn't partial, the one of all the
+// queries and the cold or warm one.
+func (sp *defaultStatProcessor) statGroupsOf(mapping map[string]*statGroup, stat *Stat) []*statGroup {
+	sg, ok := mapping[string(stat.label)]
+	if !ok {
+		sg = newStatGroup(*sp.args.limit)
+		mapping[string(stat.label)] = sg
+	}
+	if stat.isPartial {
+		return []*statGroup{sg}
+	}
+	groups := []*statGroup{sg, mapping[LabelAllQueries]}
+	// Only needed when differentiating between cold & warm
+	if sp.args.prewarmQueries {
+		label := labelColdQueries
+		if stat.isWarm {
+			label = labelWarmQueries
+		}
+		if _, ok := mapping[label]; !ok {
+			mapping[label] = newStatGroup(*sp.args.limit)
+		}
+		groups = append(groups, mapping[label])
+	}
+	return groups
+}
+
+// pushPassStat adds a stat to the window of its pass. The windows of the passes
+// before the previous one are reported: all their queries are done by then, but
+// for the slowest ones, which are only in the overall stats.
+func (sp *defaultStatProcessor) pushPassStat(stat *Stat) {
+	w, ok := sp.passWindows[stat.pass]
+	if !ok {
+		if n := len(sp.passes); n > 0 && sp.passes[n-1].Pass >= stat.pass {
+			// the window of the pass is already reported
+			return
+		}
+		w = &passWindow{
+			pass:  stat.pass,
+			start: time.Now(),
+			statMapping: map[string]*statGroup{
+				LabelAllQueries: newStatGroup(*sp.args.limit),
+			},
+		}
+		sp.passWindows[stat.pass] = w
+		if stat.pass > 1 {
+			sp.flushPassWindows(stat.pass - 1)
+		}
+	}
+	for _, sg := range sp.statGroupsOf(w.statMapping, stat) {
+		sg.pushStat(stat)
+	}
+	if !stat.isPartial && (!sp.args.prewarmQueries || !stat.isWarm) {
+		w.queries++
+	}
+	w.end = time.Now()
+}
+
+// flushPassWindows reports the windows of the passes before the given one, or
+// all of them if it is 0, in order: they are printed to stdout and kept for the
+// results file.
+func (sp *defaultStatProcessor) flushPassWindows(before uint64) {
+	passes := make([]uint64, 0, len(sp.passWindows))
+	for pass := range sp.passWindows {
+		if before == 0 || pass < before {
+			passes = append(passes, pass)
+		}
+	}
+	sort.Slice(passes, func(i, j int) bool { return passes[i] < passes[j] })
+	for _, pass := range passes {
+		w := sp.passWindows[pass]
+		delete(sp.passWindows, pass)
+		took := w.end.Sub(w.start)
+		_, err := fmt.Printf("Pass %d: %d queries in %0.2fsec (query rate %0.2f queries/sec):\n", w.pass, w.queries, took.Seconds(), float64(w.queries)/took.Seconds())
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err = writeStatGroupMap(os.Stdout, w.statMapping, sp.args.percentiles); err != nil {
+			log.Fatal(err)
+		}
+		labels := make(map[string]LabelStats, len(w.statMapping))
+		for label, sg := range w.statMapping {
+			labels[label] = newLabelStats(sg)
+		}
+		sp.passes = append(sp.passes, PassStats{
+			Pass:    w.pass,
+			Queries: w.queries,
+			Seconds: took.Seconds(),
+			Labels:  labels,
+		})
+	}
+}
+
+// hdrGroupOf returns the group of the HDR log file of the label of a stat and
+// of it being cold or warm, created if needed.
+func (sp *defaultStatProcessor) hdrGroupOf(stat *Stat) *statGroup {
+	key := stripRegex(string(stat.label)) + "_cold"
+	if stat.isWarm {
+		key = stripRegex(string(stat.label)) + "_warm"
+	}
+	sg, ok := sp.hdrMapping[key]
+	if !ok {
+		sg = newStatGroup(*sp.args.limit)
+		sp.hdrMapping[key] = sg
+	}
+	return sg
+}
+
+// writeHDRLogs writes one HdrHistogram log file per label to the HDR latencies
+// directory. When queries are prewarmed, the cold and warm queries of a label
+// have separate files.
+func (sp *defaultStatProcessor) writeHDRLogs(end time.Time) error {
+	if err := os.MkdirAll(sp.args.hdrLatenciesDir, 0755); err != nil {
+		return err
+	}
+	groups := make(map[string]*statGroup, len(sp.statMapping)+len(sp.hdrMapping))
+	for label, sg := range sp.statMapping {
+		// the groups of the labels mix cold and warm queries when prewarming
+		if !sp.args.prewarmQueries || label == LabelAllQueries || label == labelColdQueries || label == labelWarmQueries {
+			groups[stripRegex(label)] = sg
+		}
+	}
+	for key, sg := range sp.hdrMapping {
+		groups[key] = sg
+	}
+	_, _ = fmt.Printf("Saving the HdrHistogram logs of the %d labels to %s\n", len(groups), sp.args.hdrLatenciesDir)
+	for tag, sg := range groups {
+		if _, err := writeHDRLogFile(sp.args.hdrLatenciesDir, tag, sg, sp.startTime, end); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newProgressWriter opens the destination of the intermediate stats and writes the header.
+// The returned function closes the destination.
+func (sp *defaultStatProcessor) newProgressWriter() (progressWriter, func()) {
+	var out io.Writer = os.Stderr
+	closeFn := func() {}
+	if len(sp.args.reportFile) > 0 {
+		f, err := os.Create(sp.args.reportFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		out = f
+		closeFn = func() { _ = f.Close() }
+	}
+	w, err := newProgressWriter(sp.args.reportFormat, out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = w.writeHeader(); err != nil {
+		log.Fatal(err)
+	}
+	return w, closeFn
+}
+
 func generateQuantileMap(hist *hdrhistogram.Histogram) (int64, map[string]float64) {
 	ops := hist.TotalCount()
 	q0 := 0.0
@@ -238,9 +470,57 @@ func (sp *defaultStatProcessor) GetTotalsMap() map[string]interface{} {
 		quantiles[stripRegex(label)] = all
 	}
 	totals["overallQuantiles"] = quantiles
+	// the configured percentiles, in milliseconds
+	percentiles := make(map[string]interface{})
+	for label, statGroup := range sp.statMapping {
+		percentiles[stripRegex(label)] = statGroup.percentileMap(sp.args.percentiles)
+	}
+	totals["overallPercentiles"] = percentiles
+	if sp.args.openLoop {
+		responseQuantiles := make(map[string]interface{})
+		for label, statGroup := range sp.responseMapping {
+			_, all := generateQuantileMap(statGroup.latencyHDRHistogram)
+			responseQuantiles[stripRegex(label)] = all
+		}
+		totals["overallResponseQuantiles"] = responseQuantiles
+	}
+	// failed queries (timeouts included), not in the rates and quantiles
+	errors := make(map[string]interface{})
+	errorRates := make(map[string]interface{})
+	timeouts := make(map[string]interface{})
+	for label, statGroup := range sp.statMapping {
+		errors[stripRegex(label)] = statGroup.errors
+		errorRates[stripRegex(label)] = statGroup.errorRate()
+		timeouts[stripRegex(label)] = statGroup.timeouts
+	}
+	totals["errors"] = errors
+	totals["errorRates"] = errorRates
+	totals["timeouts"] = timeouts
+	if sp.args.loop {
+		totals["passes"] = sp.passes
+	}
 	return totals
 }
 
+// labelStats returns the latency statistics of every label, once the stats are processed
+func (sp *defaultStatProcessor) labelStats() map[string]LabelStats {
+	stats := make(map[string]LabelStats, len(sp.statMapping))
+	for label, sg := range sp.statMapping {
+		stats[label] = newLabelStats(sg)
+	}
+	return stats
+}
+
+// percentile returns the latency percentile p (between 0 and 100) of the
+// queries of a label, in milliseconds, once the stats are processed
+func (sp *defaultStatProcessor) percentile(label string, p float64) (float64, bool) {
+	sg, ok := sp.statMapping[label]
+	if !ok {
+		return 0, false
+	}
+	return sg.Percentile(p), true
+}
+
 func stripRegex(in string) string {
 	reg, _ := regexp.Compile("[^a-zA-Z0-9]+")
 	return reg.ReplaceAllString(in, "_")
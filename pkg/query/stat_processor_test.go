@@ -1,8 +1,14 @@
 package query
 
 import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
 )
 
 func TestStatProcessorSend(t *testing.T) {
@@ -77,3 +83,92 @@ func TestStatProcessorSendWarm(t *testing.T) {
 		t.Errorf("empty stat array changed channel length: got %d want %d", got, wantLen)
 	}
 }
+
+func TestStatProcessorOpenLoop(t *testing.T) {
+	limit := uint64(0)
+	sp := newStatProcessor(&statProcessorArgs{limit: &limit, openLoop: true}).(*defaultStatProcessor)
+	// the stats are processed once they are all sent
+	sp.open(3)
+	s := GetStat().Init([]byte("q1"), 10)
+	s.delay = 90
+	sp.send([]*Stat{s, GetPartialStat().Init([]byte("q1-part"), 5), newErrorStat([]byte("q1"))})
+	close(sp.c)
+	sp.process(1)
+
+	if got := sp.statMapping["q1"].Max(); got != 10 {
+		t.Errorf("wrong service time: got %f want 10", got)
+	}
+	response := sp.responseMapping["q1"]
+	if response == nil || response.count != 1 || response.sum != 100 {
+		t.Errorf("wrong response time: %+v", response)
+	}
+	if _, ok := sp.responseMapping["q1-part"]; ok {
+		t.Errorf("partial stats have no response time")
+	}
+	if _, ok := sp.GetTotalsMap()["overallResponseQuantiles"]; !ok {
+		t.Errorf("response quantiles missing from the totals")
+	}
+}
+
+func TestStatProcessorHDRLogs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hdr-logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	limit := uint64(0)
+	sp := newStatProcessor(&statProcessorArgs{
+		limit:           &limit,
+		prewarmQueries:  true,
+		percentiles:     []float64{50, 99},
+		hdrLatenciesDir: filepath.Join(dir, "logs"),
+	}).(*defaultStatProcessor)
+	// the stats are processed once they are all sent
+	sp.open(3)
+	sp.send([]*Stat{GetStat().Init([]byte("q 1"), 100), newErrorStat([]byte("q 1"))})
+	sp.sendWarm([]*Stat{GetStat().Init([]byte("q 1"), 2)})
+	close(sp.c)
+	sp.process(1)
+
+	want := map[string]int64{
+		"all_queries":  2,
+		"cold_queries": 1,
+		"warm_queries": 1,
+		"q_1_cold":     1,
+		"q_1_warm":     1,
+	}
+	files, err := ioutil.ReadDir(filepath.Join(dir, "logs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(want) {
+		t.Errorf("wrong number of files: got %d want %d", len(files), len(want))
+	}
+	for tag, count := range want {
+		f, err := os.Open(filepath.Join(dir, "logs", tag+hdrLogExtension))
+		if err != nil {
+			t.Errorf("missing log of %s: %v", tag, err)
+			continue
+		}
+		h, err := hdrhistogram.NewHistogramLogReader(f).NextIntervalHistogram()
+		f.Close()
+		if err != nil || h == nil {
+			t.Errorf("cannot read the log of %s: %v", tag, err)
+			continue
+		}
+		if h.TotalCount() != count {
+			t.Errorf("wrong count in the log of %s: got %d want %d", tag, h.TotalCount(), count)
+		}
+		if h.Tag() != tag {
+			t.Errorf("wrong tag in the log of %s: %s", tag, h.Tag())
+		}
+	}
+
+	percentiles, ok := sp.GetTotalsMap()["overallPercentiles"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("percentiles missing from the totals")
+	}
+	if got := percentiles["q_1"].(map[string]float64)["p99"]; math.Abs(got-100) > 0.1 {
+		t.Errorf("wrong p99 of q_1: got %f want 100", got)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/HdrHistogram/hdrhistogram-go"
@@ -20,6 +22,16 @@ type Stat struct {
 	value     float64
 	isWarm    bool
 	isPartial bool
+	// isError is set for queries that failed, they have no latency
+	isError bool
+	// isTimeout is set for failed queries cancelled by the query timeout
+	isTimeout bool
+	// delay is the time the query started after its intended start time in open
+	// loop, the response time of the query is value + delay
+	delay float64
+	// pass is the pass over the queries the query was sent in by a looping
+	// run, starting at 1, 0 otherwise
+	pass uint64
 }
 
 var statPool = &sync.Pool{
@@ -43,12 +55,28 @@ func GetPartialStat() *Stat {
 	return s
 }
 
+// newErrorStat returns a Stat recording that a query of the label failed
+func newErrorStat(label []byte) *Stat {
+	s := GetStat().Init(label, 0)
+	s.isError = true
+	return s
+}
+
+// newTimeoutStat returns a Stat recording that a query of the label timed out
+func newTimeoutStat(label []byte) *Stat {
+	s := newErrorStat(label)
+	s.isTimeout = true
+	return s
+}
+
 // Init safely initializes a Stat while minimizing heap allocations.
 func (s *Stat) Init(label []byte, value float64) *Stat {
 	s.label = s.label[:0] // clear
 	s.label = append(s.label, label...)
 	s.value = value
 	s.isWarm = false
+	s.delay = 0
+	s.pass = 0
 	return s
 }
 
@@ -57,6 +85,10 @@ func (s *Stat) reset() *Stat {
 	s.value = 0.0
 	s.isWarm = false
 	s.isPartial = false
+	s.isError = false
+	s.isTimeout = false
+	s.delay = 0
+	s.pass = 0
 	return s
 }
 
@@ -65,6 +97,10 @@ type statGroup struct {
 	latencyHDRHistogram *hdrhistogram.Histogram
 	sum                 float64
 	count               int64
+	// errors is the number of failed queries, which are not in the latencies,
+	// timeouts the number of those that timed out
+	errors   int64
+	timeouts int64
 }
 
 // newStatGroup returns a new StatGroup with an initial size
@@ -90,9 +126,31 @@ func (s *statGroup) push(n float64) {
 	s.count++
 }
 
-// string makes a simple description of a statGroup.
-func (s *statGroup) string() string {
-	return fmt.Sprintf("min: %8.2fms, med: %8.2fms, mean: %8.2fms, max: %7.2fms, stddev: %8.2fms, sum: %5.1fsec, count: %d",
+// pushStat updates a StatGroup with the latency of a stat, failed queries
+// are only counted.
+func (s *statGroup) pushStat(stat *Stat) {
+	if stat.isError {
+		s.errors++
+		if stat.isTimeout {
+			s.timeouts++
+		}
+		return
+	}
+	s.push(stat.value)
+}
+
+// errorRate returns the share of the queries that failed, between 0 and 1
+func (s *statGroup) errorRate() float64 {
+	if s.errors == 0 {
+		return 0
+	}
+	return float64(s.errors) / float64(s.count+s.errors)
+}
+
+// string makes a simple description of a statGroup, with the latency at the
+// given percentiles (between 0 and 100).
+func (s *statGroup) string(percentiles []float64) string {
+	desc := fmt.Sprintf("min: %8.2fms, med: %8.2fms, mean: %8.2fms, max: %7.2fms, stddev: %8.2fms, sum: %5.1fsec, count: %d",
 		s.Min(),
 		s.Median(),
 		s.Mean(),
@@ -100,13 +158,65 @@ func (s *statGroup) string() string {
 		s.StdDev(),
 		s.sum/hdrScaleFactor,
 		s.count)
+	if s.errors > 0 {
+		desc += fmt.Sprintf(", errors: %d (%0.2f%%)", s.errors, s.errorRate()*100)
+	}
+	if s.timeouts > 0 {
+		desc += fmt.Sprintf(", timeouts: %d", s.timeouts)
+	}
+	for _, p := range percentiles {
+		desc += fmt.Sprintf(", %s: %8.2fms", percentileName(p), s.Percentile(p))
+	}
+	return desc
 }
 
-func (s *statGroup) write(w io.Writer) error {
-	_, err := fmt.Fprintln(w, s.string())
+func (s *statGroup) write(w io.Writer, percentiles []float64) error {
+	_, err := fmt.Fprintln(w, s.string(percentiles))
 	return err
 }
 
+// Percentile returns the latency under which are p percent of the values of
+// the StatGroup, in milliseconds
+func (s *statGroup) Percentile(p float64) float64 {
+	return float64(s.latencyHDRHistogram.ValueAtQuantile(p)) / hdrScaleFactor
+}
+
+// percentileMap returns the latency at each of the percentiles, in milliseconds,
+// keyed by the name of the percentile
+func (s *statGroup) percentileMap(percentiles []float64) map[string]float64 {
+	m := make(map[string]float64, len(percentiles))
+	for _, p := range percentiles {
+		m[percentileName(p)] = s.Percentile(p)
+	}
+	return m
+}
+
+// percentileName returns the name of a percentile, e.g. p99.9
+func percentileName(p float64) string {
+	return "p" + strconv.FormatFloat(p, 'f', -1, 64)
+}
+
+// parsePercentiles parses a comma separated list of percentiles, each greater
+// than 0 and up to 100, e.g. "90,99,99.9"
+func parsePercentiles(list string) ([]float64, error) {
+	var percentiles []float64
+	for _, field := range strings.Split(list, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		p, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid percentile '%s': %v", field, err)
+		}
+		if p <= 0 || p > 100 {
+			return nil, fmt.Errorf("invalid percentile '%s': must be greater than 0 and up to 100", field)
+		}
+		percentiles = append(percentiles, p)
+	}
+	return percentiles, nil
+}
+
 // Median returns the Median value of the StatGroup in milliseconds
 func (s *statGroup) Median() float64 {
 	return float64(s.latencyHDRHistogram.ValueAtQuantile(50.0)) / hdrScaleFactor
@@ -133,8 +243,8 @@ func (s *statGroup) StdDev() float64 {
 }
 
 // writeStatGroupMap writes a map of StatGroups in an ordered fashion by
-// key that they are stored by
-func writeStatGroupMap(w io.Writer, statGroups map[string]*statGroup) error {
+// key that they are stored by, with the latency at the given percentiles
+func writeStatGroupMap(w io.Writer, statGroups map[string]*statGroup, percentiles []float64) error {
 	maxKeyLength := 0
 	keys := make([]string, 0, len(statGroups))
 	for k := range statGroups {
@@ -156,7 +266,7 @@ func writeStatGroupMap(w io.Writer, statGroups map[string]*statGroup) error {
 			return err
 		}
 
-		err = v.write(w)
+		err = v.write(w, percentiles)
 		if err != nil {
 			return err
 		}
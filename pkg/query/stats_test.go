@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"math"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -264,7 +266,7 @@ func (w *errWriter) Write(p []byte) (int, error) {
 func TestWrite(t *testing.T) {
 	var buf bytes.Buffer
 	sg := newStatGroup(0)
-	err := sg.write(&buf)
+	err := sg.write(&buf, nil)
 	if err != nil {
 		t.Errorf("unexpected error for write: %v", err)
 	}
@@ -275,7 +277,7 @@ func TestWrite(t *testing.T) {
 	}
 
 	// Test error case
-	err = sg.write(&errWriter{})
+	err = sg.write(&errWriter{}, nil)
 	if err == nil {
 		t.Errorf("expected error but did not get one")
 	}
@@ -340,7 +342,7 @@ func TestWriteStatGroupMap(t *testing.T) {
 		} else {
 			w = bytes.NewBuffer([]byte{})
 		}
-		err := writeStatGroupMap(w, m)
+		err := writeStatGroupMap(w, m, nil)
 		if shouldErr {
 			ew := w.(*errWriter)
 			if err == nil {
@@ -405,3 +407,70 @@ func TestWriteStatGroupMap(t *testing.T) {
 		}
 	}
 }
+
+func TestStatGroupPushStatError(t *testing.T) {
+	sg := newStatGroup(0)
+	sg.pushStat(GetStat().Init([]byte("q1"), 10))
+	sg.pushStat(newErrorStat([]byte("q1")))
+	sg.pushStat(newTimeoutStat([]byte("q1")))
+	if sg.count != 1 || sg.errors != 2 || sg.timeouts != 1 {
+		t.Errorf("wrong counts: got count %d errors %d timeouts %d, want 1, 2 and 1", sg.count, sg.errors, sg.timeouts)
+	}
+	if sg.Max() != 10 {
+		t.Errorf("failed query included in the latencies: max %f", sg.Max())
+	}
+	if got := sg.errorRate(); got != 2.0/3 {
+		t.Errorf("wrong error rate: got %f want 0.67", got)
+	}
+	if !strings.HasSuffix(sg.string(nil), "errors: 2 (66.67%), timeouts: 1") {
+		t.Errorf("errors missing from the description: %s", sg.string(nil))
+	}
+}
+
+func TestParsePercentiles(t *testing.T) {
+	cases := []struct {
+		list      string
+		want      []float64
+		shouldErr bool
+	}{
+		{list: "", want: nil},
+		{list: "90,95,99,99.9", want: []float64{90, 95, 99, 99.9}},
+		{list: " 50 , 100,", want: []float64{50, 100}},
+		{list: "0", shouldErr: true},
+		{list: "100.1", shouldErr: true},
+		{list: "p99", shouldErr: true},
+	}
+	for _, c := range cases {
+		got, err := parsePercentiles(c.list)
+		if c.shouldErr {
+			if err == nil {
+				t.Errorf("%q: expected an error", c.list)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", c.list, err)
+		} else if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%q: got %v want %v", c.list, got, c.want)
+		}
+	}
+}
+
+func TestStatGroupPercentiles(t *testing.T) {
+	sg := newStatGroup(0)
+	for i := 1; i <= 1000; i++ {
+		sg.push(float64(i))
+	}
+	percentiles := []float64{50, 99.9}
+	want := map[string]float64{"p50": 500, "p99.9": 999}
+	got := sg.percentileMap(percentiles)
+	for name, w := range want {
+		if math.Abs(got[name]-w) > 1 {
+			t.Errorf("wrong %s: got %f want %f", name, got[name], w)
+		}
+	}
+	desc := sg.string(percentiles)
+	if !strings.Contains(desc, "p50:   500.") || !strings.Contains(desc, "p99.9:   999.") {
+		t.Errorf("percentiles missing from the description: %s", desc)
+	}
+}
@@ -0,0 +1,144 @@
+package query
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SweepLevel are the results of the queries run with one number of workers of
+// a workers sweep
+type SweepLevel struct {
+	Workers        uint    `json:"workers"`
+	DurationMillis int64   `json:"durationMillis"`
+	Queries        int64   `json:"queries"`
+	QueryRate      float64 `json:"queryRate"`
+	// Labels has the latencies of every label, Percentiles the configured
+	// percentiles of them, in milliseconds
+	Labels      map[string]LabelStats  `json:"labels"`
+	Percentiles map[string]interface{} `json:"percentiles"`
+}
+
+// parseWorkersSweep parses a comma separated list of numbers of workers, empty
+// if the list is
+func parseWorkersSweep(s string) ([]uint, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var sweep []uint
+	for _, field := range strings.Split(s, ",") {
+		workers, err := strconv.ParseUint(strings.TrimSpace(field), 10, 32)
+		if err != nil || workers == 0 {
+			return nil, fmt.Errorf("invalid number of workers '%s' in workers-sweep, must be a positive integer", field)
+		}
+		sweep = append(sweep, uint(workers))
+	}
+	return sweep, nil
+}
+
+// runSweep runs the queries with every number of workers of the sweep, one
+// level after the other. The queries are read once and replayed at every
+// level, by new processors. It returns the results of the levels run and the
+// wall clock start and end times of the sweep.
+func (b *BenchmarkRunner) runSweep(queryPool *sync.Pool, processorCreateFn ProcessorCreate, sweep []uint) ([]SweepLevel, time.Time, time.Time) {
+	start := time.Now()
+	b.sweepQueries = b.readSweepQueries(queryPool)
+	_, _ = fmt.Printf("Read %d queries for the workers sweep %s\n", b.sweepQueries.len(), b.WorkersSweep)
+
+	args := *b.sp.getArgs()
+	levels := make([]SweepLevel, 0, len(sweep))
+	for _, workers := range sweep {
+		if b.wasInterrupted() {
+			break
+		}
+		_, _ = fmt.Printf("\nRunning the queries with %d workers\n", workers)
+		b.sp = newStatProcessor(sweepArgs(args, workers))
+		b.scanner = newScanner(&b.Limit)
+		b.passMu.Lock()
+		b.passStarts = nil
+		b.passMu.Unlock()
+
+		levelStart, levelEnd := b.runWorkers(queryPool, processorCreateFn, workers)
+		levels = append(levels, b.sweepLevel(workers, levelEnd.Sub(levelStart)))
+	}
+	printSweepSummary(levels)
+	return levels, start, time.Now()
+}
+
+// readSweepQueries reads the queries of the input, up to the limit, and
+// returns them to be replayed at every level of the sweep
+func (b *BenchmarkRunner) readSweepQueries(queryPool *sync.Pool) *queryReplay {
+	replay := newQueryReplay(false, 0)
+	ch := make(chan Query)
+	done := make(chan struct{})
+	go func() {
+		for q := range ch {
+			// the replays decode the queries into released ones
+			q.Release()
+		}
+		close(done)
+	}()
+	newScanner(&b.Limit).setReader(b.GetBufferedReader()).setFormat(b.inputFormat()).
+		setStop(b.wasInterrupted).setReplay(replay).scan(queryPool, ch)
+	close(ch)
+	<-done
+	if replay.len() == 0 {
+		log.Fatal("no queries to run the workers sweep with")
+	}
+	return replay
+}
+
+// sweepArgs returns the stat processor arguments of a level of a sweep: its
+// HDR latencies and report files are suffixed with the number of workers, so
+// the levels don't overwrite each other's
+func sweepArgs(args statProcessorArgs, workers uint) *statProcessorArgs {
+	suffix := fmt.Sprintf("workers-%d", workers)
+	if args.hdrLatenciesFile != "" {
+		args.hdrLatenciesFile = suffixFileName(args.hdrLatenciesFile, suffix)
+	}
+	if args.reportFile != "" {
+		args.reportFile = suffixFileName(args.reportFile, suffix)
+	}
+	if args.hdrLatenciesDir != "" {
+		args.hdrLatenciesDir = filepath.Join(args.hdrLatenciesDir, suffix)
+	}
+	return &args
+}
+
+// suffixFileName adds a suffix to a file name, before its extension
+func suffixFileName(fileName, suffix string) string {
+	ext := filepath.Ext(fileName)
+	return strings.TrimSuffix(fileName, ext) + "-" + suffix + ext
+}
+
+// sweepLevel returns the results of the level of the sweep just run
+func (b *BenchmarkRunner) sweepLevel(workers uint, took time.Duration) SweepLevel {
+	labels := b.sp.labelStats()
+	all := labels[LabelAllQueries]
+	level := SweepLevel{
+		Workers:        workers,
+		DurationMillis: took.Milliseconds(),
+		Queries:        all.Count,
+		Labels:         labels,
+	}
+	if took > 0 {
+		level.QueryRate = float64(all.Count) / took.Seconds()
+	}
+	level.Percentiles, _ = b.sp.GetTotalsMap()["overallPercentiles"].(map[string]interface{})
+	return level
+}
+
+// printSweepSummary prints the query rate and latencies of all the queries at
+// every level of the sweep, to draw the latency-throughput curve
+func printSweepSummary(levels []SweepLevel) {
+	_, _ = fmt.Printf("\nWorkers sweep summary (all queries):\n")
+	_, _ = fmt.Printf("workers,queries,queries/sec,p50 ms,p95 ms,p99 ms,max ms\n")
+	for _, l := range levels {
+		all := l.Labels[LabelAllQueries]
+		_, _ = fmt.Printf("%d,%d,%0.2f,%0.2f,%0.2f,%0.2f,%0.2f\n", l.Workers, l.Queries, l.QueryRate, all.P50, all.P95, all.P99, all.Max)
+	}
+}
@@ -0,0 +1,194 @@
+package query
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestParseWorkersSweep(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    []uint
+		wantErr bool
+	}{
+		{in: "", want: nil},
+		{in: "1,2,4, 8", want: []uint{1, 2, 4, 8}},
+		{in: "16", want: []uint{16}},
+		{in: "1,0", wantErr: true},
+		{in: "1,,2", wantErr: true},
+		{in: "1,-2", wantErr: true},
+		{in: "two", wantErr: true},
+	}
+	for _, c := range cases {
+		got, err := parseWorkersSweep(c.in)
+		if (err != nil) != c.wantErr {
+			t.Errorf("%q: got error %v, want error %t", c.in, err, c.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%q: got %v want %v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestSweepArgs(t *testing.T) {
+	args := statProcessorArgs{hdrLatenciesFile: "/tmp/latencies.hgrm", reportFile: "/tmp/report", hdrLatenciesDir: "/tmp/hdr", burnIn: 3}
+	got := sweepArgs(args, 4)
+	if got.hdrLatenciesFile != "/tmp/latencies-workers-4.hgrm" || got.reportFile != "/tmp/report-workers-4" ||
+		got.hdrLatenciesDir != filepath.Join("/tmp/hdr", "workers-4") || got.burnIn != 3 {
+		t.Errorf("wrong args of the level: %+v", got)
+	}
+	if args.hdrLatenciesFile != "/tmp/latencies.hgrm" {
+		t.Errorf("the args of the run changed: %+v", args)
+	}
+	if got = sweepArgs(statProcessorArgs{}, 4); got.hdrLatenciesFile != "" || got.reportFile != "" || got.hdrLatenciesDir != "" {
+		t.Errorf("disabled files are enabled: %+v", got)
+	}
+}
+
+// countingProcessor counts the processors created and closed and the queries
+// processed by all of them
+type countingProcessor struct {
+	mu      *sync.Mutex
+	workers map[int]bool
+	queries *int
+	closed  *int
+}
+
+func (p *countingProcessor) Init(workerNum int) {
+	p.mu.Lock()
+	p.workers[workerNum] = true
+	p.mu.Unlock()
+}
+
+func (p *countingProcessor) ProcessQuery(_ context.Context, q Query, _ bool) ([]*Stat, error) {
+	p.mu.Lock()
+	*p.queries++
+	p.mu.Unlock()
+	return []*Stat{GetStat().Init(q.HumanLabelName(), 1)}, nil
+}
+
+func (p *countingProcessor) Close() {
+	p.mu.Lock()
+	*p.closed++
+	p.mu.Unlock()
+}
+
+func TestBenchmarkRunnerRunWorkersSweep(t *testing.T) {
+	var buf bytes.Buffer
+	err := encodeQueries(&buf, 5, func(i uint64) Query {
+		return &testQuery{HumanLabel: []byte(fmt.Sprintf("q%d", i%2))}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "sweep")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	runner := NewBenchmarkRunner(BenchmarkRunnerConfig{
+		Workers:      1,
+		Limit:        4,
+		WorkersSweep: "1,2,3",
+		ResultsFile:  filepath.Join(dir, "results.json"),
+	})
+	runner.br = bufio.NewReader(&buf)
+	var mu sync.Mutex
+	created := 0
+	queries := 0
+	closed := 0
+	runner.Run(&testQueryPool, func() Processor {
+		created++
+		return &countingProcessor{mu: &mu, workers: map[int]bool{}, queries: &queries, closed: &closed}
+	})
+
+	if created != 6 {
+		t.Errorf("wrong number of processors: got %d want 6", created)
+	}
+	// the processors of every level are closed once the level is run
+	if closed != 6 {
+		t.Errorf("wrong number of processors closed: got %d want 6", closed)
+	}
+	// every level runs the 4 queries of the limit
+	if queries != 12 {
+		t.Errorf("wrong number of queries: got %d want 12", queries)
+	}
+
+	data, err := ioutil.ReadFile(runner.ResultsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result struct {
+		Totals struct {
+			WorkersSweep []SweepLevel `json:"workersSweep"`
+		}
+	}
+	if err = json.Unmarshal(data, &result); err != nil {
+		t.Fatal(err)
+	}
+	levels := result.Totals.WorkersSweep
+	if len(levels) != 3 {
+		t.Fatalf("wrong number of levels: %+v", levels)
+	}
+	for i, l := range levels {
+		if l.Workers != uint(i+1) || l.Queries != 4 || l.Labels["q0"].Count != 2 || l.Labels["q1"].Count != 2 {
+			t.Errorf("wrong level %d: %+v", i, l)
+		}
+		if _, ok := l.Percentiles["q0"]; !ok {
+			t.Errorf("level %d has no percentiles: %+v", i, l.Percentiles)
+		}
+	}
+}
+
+func TestBenchmarkRunnerReadSweepQueriesReleasesQueries(t *testing.T) {
+	var buf bytes.Buffer
+	err := encodeQueries(&buf, 3, func(i uint64) Query {
+		q := NewHTTP()
+		q.Body = []byte("payload")
+		q.StartTimestamp = 5
+		return q
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var read []*HTTP
+	pool := &sync.Pool{New: func() interface{} {
+		q := &HTTP{}
+		read = append(read, q)
+		return q
+	}}
+	runner := NewBenchmarkRunner(BenchmarkRunnerConfig{Workers: 1})
+	runner.br = bufio.NewReader(&buf)
+	if n := runner.readSweepQueries(pool).len(); n != 3 {
+		t.Fatalf("wrong number of queries read: got %d want 3", n)
+	}
+	// the replays decode the queries into released ones, which don't keep the
+	// fields missing in the input
+	for i, q := range read {
+		if len(q.Body) != 0 || q.StartTimestamp != 0 {
+			t.Errorf("query %d not released: got body %q and start %d", i, q.Body, q.StartTimestamp)
+		}
+	}
+}
+
+func TestBenchmarkRunnerRunPanicOnWorkersSweepWithCapture(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("the code did not panic")
+		}
+	}()
+	runner := NewBenchmarkRunner(BenchmarkRunnerConfig{Workers: 1, WorkersSweep: "1,2", CaptureResultsFile: "/tmp/results.jsonl"})
+	runner.Run(nil, nil)
+}
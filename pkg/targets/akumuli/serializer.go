@@ -7,6 +7,7 @@ import (
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/data/serialize"
 	"io"
+	"math"
 )
 
 const (
@@ -31,6 +32,14 @@ func NewAkumuliSerializer() *Serializer {
 	return s
 }
 
+// Shard makes the serializer number its series from a range of ids of its
+// own, so the ids of the shards do not collide. Each shard defines its series
+// before its points, so the interleaved outputs are valid.
+func (s *Serializer) Shard(shard, shards int) []byte {
+	s.index = uint32(shard) * (math.MaxUint32 / uint32(shards))
+	return nil
+}
+
 // Serialize writes Point data to the given writer, conforming to the
 // AKUMULI RESP protocol.  Serializer adds extra data to guide data loader.
 // This function writes output that contains binary and text data in RESP format.
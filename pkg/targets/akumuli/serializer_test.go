@@ -2,6 +2,7 @@ package akumuli
 
 import (
 	"bytes"
+	"encoding/binary"
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/data/serialize"
 	"strings"
@@ -78,3 +79,26 @@ func TestAkumuliSerializerSerialize(t *testing.T) {
 		}
 	}
 }
+
+func TestAkumuliSerializerShard(t *testing.T) {
+	ids := make(map[uint32]bool)
+	for shard := 0; shard < 3; shard++ {
+		serializer := NewAkumuliSerializer()
+		if header := serializer.Shard(shard, 3); len(header) != 0 {
+			t.Errorf("unexpected header %q", header)
+		}
+		var buf bytes.Buffer
+		for i := 0; i < 2; i++ {
+			if err := serializer.Serialize(serialize.TestPointDefault(), &buf); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		// the series is defined with the id of the shard, then the
+		// deferred point is written with it
+		id := binary.LittleEndian.Uint32(buf.Bytes()[:4])
+		if ids[id] {
+			t.Errorf("shard %d reuses the series id %d", shard, id)
+		}
+		ids[id] = true
+	}
+}
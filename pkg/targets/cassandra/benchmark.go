@@ -1,7 +1,6 @@
 package cassandra
 
 import (
-	"bufio"
 	"errors"
 	"fmt"
 	"github.com/gocql/gocql"
@@ -40,7 +39,8 @@ func NewBenchmark(dbSpecificConfig *SpecificConfig, dsConfig *source.DataSourceC
 }
 
 func (b *benchmark) GetDataSource() targets.DataSource {
-	return &fileDataSource{scanner: bufio.NewScanner(load.GetBufferedReader(b.dataSourceFileName))}
+	file, scanner := load.OpenDataFile(b.dataSourceFileName)
+	return &fileDataSource{file: file, scanner: scanner}
 }
 
 func (b *benchmark) GetBatchFactory() targets.BatchFactory {
@@ -3,6 +3,7 @@ package cassandra
 import (
 	"bufio"
 	"fmt"
+	"github.com/timescale/tsbs/load"
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
 	"github.com/timescale/tsbs/pkg/targets"
@@ -12,6 +13,7 @@ import (
 )
 
 type fileDataSource struct {
+	file    *load.DataFile
 	scanner *bufio.Scanner
 }
 
@@ -72,3 +74,18 @@ type factory struct{}
 func (f *factory) New() targets.Batch {
 	return ePool.Get().(*eventsBatch)
 }
+
+// Offset returns the byte offset of the next item in the data file
+func (d *fileDataSource) Offset() int64 {
+	return d.file.Offset()
+}
+
+// SeekTo makes the item at offset in the data file the next one
+func (d *fileDataSource) SeekTo(offset int64) error {
+	scanner, err := d.file.SeekTo(offset)
+	if err != nil {
+		return err
+	}
+	d.scanner = scanner
+	return nil
+}
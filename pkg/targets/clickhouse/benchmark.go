@@ -1,11 +1,9 @@
 package clickhouse
 
 import (
-	"bufio"
 	"fmt"
 	"log"
 
-	"github.com/timescale/tsbs/load"
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/targets"
 )
@@ -92,9 +90,7 @@ const tagsPrefix = "tags"
 
 func NewBenchmark(file string, hashWorkers bool, conf *ClickhouseConfig) targets.Benchmark {
 	return &benchmark{
-		ds: &fileDataSource{
-			scanner: bufio.NewScanner(load.GetBufferedReader(file)),
-		},
+		ds:          newFileDataSource(file),
 		hashWorkers: hashWorkers,
 		conf:        conf,
 	}
@@ -191,7 +191,7 @@ func TestHeaders(t *testing.T) {
 
 	for _, c := range cases {
 		br := bufio.NewReader(bytes.NewReader([]byte(c.input)))
-		dataSource := &fileDataSource{bufio.NewScanner(br), nil}
+		dataSource := &fileDataSource{scanner: bufio.NewScanner(br)}
 		if c.shouldFatal {
 			isCalled := false
 			fatal = func(fmt string, args ...interface{}) {
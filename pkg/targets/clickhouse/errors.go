@@ -0,0 +1,46 @@
+package clickhouse
+
+import (
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+
+	"github.com/kshvakov/clickhouse"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// retryableExceptionCodes are the codes of the ClickHouse exceptions after which
+// inserting the same rows again may succeed
+var retryableExceptionCodes = map[int32]bool{
+	159: true, // TIMEOUT_EXCEEDED
+	202: true, // TOO_MANY_SIMULTANEOUS_QUERIES
+	209: true, // SOCKET_TIMEOUT
+	210: true, // NETWORK_ERROR
+	241: true, // MEMORY_LIMIT_EXCEEDED
+	242: true, // TABLE_IS_READ_ONLY
+	252: true, // TOO_MANY_PARTS
+	319: true, // UNKNOWN_STATUS_OF_INSERT
+}
+
+// isRetryableError tells whether inserting the same rows again may succeed after err:
+// network errors and the exceptions of an overloaded or unavailable server.
+// Schema or syntax errors will fail the same way every time.
+func isRetryableError(err error) bool {
+	var exception *clickhouse.Exception
+	if errors.As(err, &exception) {
+		return retryableExceptionCodes[exception.Code]
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, driver.ErrBadConn)
+}
+
+// classifyError marks err as retryable for the load runner when inserting the
+// rows again may succeed. Broken connections are discarded by the pool.
+func classifyError(err error) error {
+	if isRetryableError(err) {
+		return targets.NewRetryableError(err)
+	}
+	return err
+}
@@ -0,0 +1,33 @@
+package clickhouse
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/kshvakov/clickhouse"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+func TestClassifyError(t *testing.T) {
+	cases := []struct {
+		desc string
+		err  error
+		want bool
+	}{
+		{desc: "too many parts", err: &clickhouse.Exception{Code: 252}, want: true},
+		{desc: "memory limit", err: &clickhouse.Exception{Code: 241}, want: true},
+		{desc: "unknown table", err: &clickhouse.Exception{Code: 60}},
+		{desc: "syntax error", err: &clickhouse.Exception{Code: 62}},
+		{desc: "wrapped", err: fmt.Errorf("insert: %w", &clickhouse.Exception{Code: 209}), want: true},
+		{desc: "network", err: &net.OpError{Op: "write", Err: fmt.Errorf("broken pipe")}, want: true},
+		{desc: "bad conn", err: driver.ErrBadConn, want: true},
+		{desc: "other", err: fmt.Errorf("something else")},
+	}
+	for _, c := range cases {
+		if got := targets.IsRetryable(classifyError(c.err)); got != c.want {
+			t.Errorf("%s: retryable: got %v want %v", c.desc, got, c.want)
+		}
+	}
+}
@@ -4,12 +4,19 @@ import (
 	"bufio"
 	"strings"
 
+	"github.com/timescale/tsbs/load"
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
 )
 
+func newFileDataSource(fileName string) *fileDataSource {
+	file, scanner := load.OpenDataFile(fileName)
+	return &fileDataSource{file: file, scanner: scanner}
+}
+
 // scan.PointDecoder interface implementation
 type fileDataSource struct {
+	file    *load.DataFile
 	scanner *bufio.Scanner
 	//cached headers (should be read only at start of file)
 	headers *common.GeneratedDataHeaders
@@ -160,3 +167,18 @@ func extractTagNamesAndTypes(tags []string) ([]string, []string) {
 
 	return tagNames, tagTypes
 }
+
+// Offset returns the byte offset of the next item in the data file
+func (d *fileDataSource) Offset() int64 {
+	return d.file.Offset()
+}
+
+// SeekTo makes the item at offset in the data file the next one
+func (d *fileDataSource) SeekTo(offset int64) error {
+	scanner, err := d.file.SeekTo(offset)
+	if err != nil {
+		return err
+	}
+	d.scanner = scanner
+	return nil
+}
@@ -1,10 +1,15 @@
 package clickhouse
 
 import (
+	"errors"
+	"strings"
+	"sync"
+
 	"github.com/blagojts/viper"
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/pkg/data/serialize"
 	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/query"
 	"github.com/timescale/tsbs/pkg/targets"
 	"github.com/timescale/tsbs/pkg/targets/constants"
 	"github.com/timescale/tsbs/pkg/targets/timescaledb"
@@ -16,8 +21,36 @@ func NewTarget() targets.ImplementedTarget {
 
 type clickhouseTarget struct{}
 
-func (c clickhouseTarget) Benchmark(string, *source.DataSourceConfig, *viper.Viper) (targets.Benchmark, error) {
-	panic("implement me")
+func (c clickhouseTarget) Benchmark(targetDB string, dataSourceConfig *source.DataSourceConfig, v *viper.Viper) (targets.Benchmark, error) {
+	if dataSourceConfig.Type != source.FileDataSourceType {
+		return nil, errors.New("only FILE data source type is supported for ClickHouse")
+	}
+	conf := &ClickhouseConfig{
+		Host:       v.GetString("host"),
+		User:       v.GetString("user"),
+		Password:   v.GetString("password"),
+		LogBatches: v.GetBool("log-batches"),
+		Debug:      v.GetInt("debug"),
+		DbName:     targetDB,
+	}
+	// the loader only partitions the points over several workers with hash-workers set
+	return NewBenchmark(dataSourceConfig.File.Location, true, conf), nil
+}
+
+func (c clickhouseTarget) QueryPool() *sync.Pool {
+	return &query.ClickHousePool
+}
+
+func (c clickhouseTarget) QueryProcessor(qc query.BenchmarkRunnerConfig, v *viper.Viper) (query.ProcessorCreate, error) {
+	opts := &QueryOptions{
+		Hosts:          strings.Split(v.GetString("host"), ","),
+		User:           v.GetString("user"),
+		Password:       v.GetString("password"),
+		DBName:         qc.DBName,
+		Debug:          qc.Debug > 0,
+		PrintResponses: qc.PrintResponses,
+	}
+	return func() query.Processor { return NewQueryProcessor(opts) }, nil
 }
 
 func (c clickhouseTarget) Serializer() serialize.PointSerializer {
@@ -0,0 +1,117 @@
+package clickhouse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// QueryOptions configure the processor executing ClickHouse queries
+type QueryOptions struct {
+	// Hosts are assigned to the workers round robin, to shard reads on a multi-node setup
+	Hosts          []string
+	User           string
+	Password       string
+	DBName         string
+	Debug          bool
+	PrintResponses bool
+	// CaptureResult receives the rows returned by every query run cold, if not nil
+	CaptureResult func(q query.Query, rows [][]interface{})
+}
+
+// connectString returns the connection string of a worker. Each worker is
+// assigned a sequence number, used to evenly distribute the hosts to the workers.
+func (o *QueryOptions) connectString(workerNumber int) string {
+	conf := &ClickhouseConfig{
+		Host:     o.Hosts[workerNumber%len(o.Hosts)],
+		User:     o.User,
+		Password: o.Password,
+		DbName:   o.DBName,
+	}
+	return getConnectString(conf, true)
+}
+
+type queryProcessor struct {
+	db   *sqlx.DB
+	opts *QueryOptions
+}
+
+// NewQueryProcessor returns a query.Processor executing ClickHouse queries
+func NewQueryProcessor(opts *QueryOptions) query.Processor {
+	return &queryProcessor{opts: opts}
+}
+
+func (p *queryProcessor) Init(workerNumber int) {
+	p.db = sqlx.MustConnect(dbType, p.opts.connectString(workerNumber))
+}
+
+// Close closes the connections opened by the worker
+func (p *queryProcessor) Close() {
+	p.db.Close()
+}
+
+func (p *queryProcessor) ProcessQuery(ctx context.Context, q query.Query, isWarm bool) ([]*query.Stat, error) {
+	chQuery := q.(*query.ClickHouse)
+
+	start := time.Now()
+	sql := string(chQuery.SqlQuery)
+	rows, err := p.db.QueryxContext(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.opts.Debug {
+		fmt.Println(sql)
+	}
+	if capture := p.opts.CaptureResult != nil && !isWarm; capture || p.opts.PrintResponses {
+		cols, err := rows.Columns()
+		if err != nil {
+			return nil, err
+		}
+		result, err := query.ScanRows(rows.Rows)
+		if err != nil {
+			return nil, err
+		}
+		if p.opts.PrintResponses {
+			prettyPrintResponse(chQuery, cols, result)
+		}
+		if capture {
+			p.opts.CaptureResult(q, result)
+		}
+	}
+
+	rows.Close()
+	took := float64(time.Since(start).Nanoseconds()) / 1e6
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), took)
+
+	return []*query.Stat{stat}, err
+}
+
+// prettyPrintResponse prints a Query and its response in JSON format with two
+// keys: 'query' which has a value of the SQL used to generate the second key
+// 'results' which is an array of each row in the return set.
+func prettyPrintResponse(q *query.ClickHouse, cols []string, rows [][]interface{}) {
+	resp := make(map[string]interface{})
+	resp["query"] = string(q.SqlQuery)
+	results := []map[string]interface{}{}
+	for _, values := range rows {
+		r := make(map[string]interface{})
+		for i, column := range cols {
+			r[column] = values[i]
+		}
+		results = append(results, r)
+	}
+	resp["results"] = results
+
+	line, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(line) + "\n")
+}
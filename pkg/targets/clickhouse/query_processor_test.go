@@ -0,0 +1,41 @@
+package clickhouse
+
+import (
+	"testing"
+
+	"github.com/blagojts/viper"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+func TestQueryOptionsConnectString(t *testing.T) {
+	opts := &QueryOptions{Hosts: []string{"host1", "host2"}, User: "default", Password: "pw", DBName: "benchmark"}
+	want := []string{
+		"tcp://host1:9000?username=default&password=pw&database=benchmark",
+		"tcp://host2:9000?username=default&password=pw&database=benchmark",
+		"tcp://host1:9000?username=default&password=pw&database=benchmark",
+	}
+	for worker, w := range want {
+		if got := opts.connectString(worker); got != w {
+			t.Errorf("worker %d: incorrect connect string: got %s want %s", worker, got, w)
+		}
+	}
+}
+
+func TestTargetQueryProcessor(t *testing.T) {
+	target, ok := NewTarget().(targets.QueryTarget)
+	if !ok {
+		t.Fatal("clickhouse target should be able to run queries")
+	}
+	v := viper.New()
+	v.Set("host", "host1,host2")
+	v.Set("user", "joe")
+	createProcessor, err := target.QueryProcessor(query.BenchmarkRunnerConfig{DBName: "bench", Debug: 1}, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := createProcessor().(*queryProcessor)
+	if len(p.opts.Hosts) != 2 || p.opts.User != "joe" || p.opts.DBName != "bench" || !p.opts.Debug {
+		t.Errorf("query options not taken from the target config: %+v", p.opts)
+	}
+}
@@ -1,5 +1,7 @@
 package targets
 
+import "errors"
+
 // Processor is a type that processes the work for a loading worker
 type Processor interface {
 	// Init does per-worker setup needed before receiving data
@@ -14,3 +16,46 @@ type ProcessorCloser interface {
 	// Close cleans up after a Processor
 	Close(doLoad bool)
 }
+
+// ProcessorWithError is a Processor that reports failed inserts instead of
+// aborting the whole load. When it is implemented the load runner calls
+// ProcessBatchWithError instead of ProcessBatch and decides itself whether
+// the batch is retried, skipped or the load is stopped.
+type ProcessorWithError interface {
+	Processor
+	// ProcessBatchWithError handles a single batch of data. The returned counts
+	// are for the data actually written by this call, even if err is not nil.
+	// If the error is retryable (see NewRetryableError) the part of the batch
+	// that was not written must be left in the batch so it can be sent again.
+	ProcessBatchWithError(b Batch, doLoad bool) (metricCount, rowCount uint64, err error)
+}
+
+// RetryableError marks an error returned by a ProcessorWithError as transient
+// (e.g., a dropped connection or an overloaded server), meaning that the same
+// batch may succeed when it is processed again.
+type RetryableError struct {
+	Err error
+}
+
+// NewRetryableError wraps err so that IsRetryable reports true for it
+func NewRetryableError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &RetryableError{Err: err}
+}
+
+func (e *RetryableError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
+// IsRetryable checks whether err (or any error it wraps) is a RetryableError
+func IsRetryable(err error) bool {
+	var retryable *RetryableError
+	return errors.As(err, &retryable)
+}
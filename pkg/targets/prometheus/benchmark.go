@@ -88,18 +88,28 @@ func (pp *Processor) Init(_ int, _, _ bool) {}
 
 // ProcessBatch ..
 func (pp *Processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64) {
+	nrSamples, _, err := pp.ProcessBatchWithError(b, doLoad)
+	if err != nil {
+		panic(err)
+	}
+	return nrSamples, nrSamples
+}
+
+// ProcessBatchWithError sends the batch to the adapter. On error the batch
+// is not returned to the pool so it can be sent again.
+func (pp *Processor) ProcessBatchWithError(b targets.Batch, doLoad bool) (uint64, uint64, error) {
 	promBatch := b.(*Batch)
 	nrSamples := uint64(promBatch.Len())
 	if doLoad {
 		err := pp.client.Post(promBatch.series)
 		if err != nil {
-			panic(err)
+			return 0, 0, err
 		}
 	}
 	// reset batch
 	promBatch.series = promBatch.series[:0]
 	pp.batchPool.Put(promBatch)
-	return nrSamples, nrSamples
+	return nrSamples, nrSamples, nil
 }
 
 // PrometheusBatchFactory implements Factory interface
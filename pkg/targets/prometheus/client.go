@@ -13,6 +13,7 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/snappy"
 	"github.com/timescale/promscale/pkg/prompb"
+	"github.com/timescale/tsbs/pkg/targets"
 )
 
 // Client is a wrapper around http.Client
@@ -59,7 +60,8 @@ var snappyPool = sync.Pool{
 	},
 }
 
-// Post sends POST request to Prometheus adapter
+// Post sends POST request to Prometheus adapter. Network errors and
+// responses with a 5xx or 429 status are returned as retryable errors.
 func (c *Client) Post(series []prompb.TimeSeries) error {
 	wr := &prompb.WriteRequest{
 		Timeseries: series,
@@ -85,7 +87,7 @@ func (c *Client) Post(series []prompb.TimeSeries) error {
 	httpResp, err := c.httpClient.Do(httpReq)
 	snappyPool.Put(compressed)
 	if err != nil {
-		return err
+		return targets.NewRetryableError(err)
 	}
 	defer func() {
 		io.Copy(ioutil.Discard, httpResp.Body)
@@ -93,7 +95,11 @@ func (c *Client) Post(series []prompb.TimeSeries) error {
 	}()
 
 	if httpResp.StatusCode/100 != 2 {
-		return fmt.Errorf("Prometheus adapter returned status: %s", httpResp.Status)
+		err = fmt.Errorf("Prometheus adapter returned status: %s", httpResp.Status)
+		if httpResp.StatusCode/100 == 5 || httpResp.StatusCode == http.StatusTooManyRequests {
+			return targets.NewRetryableError(err)
+		}
+		return err
 	}
 	return nil
 }
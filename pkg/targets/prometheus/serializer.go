@@ -29,6 +29,14 @@ type Serializer struct {
 	headerWritten bool
 }
 
+// Shard makes the serializer leave the version header out, it is returned to
+// be written once before the points of all the shards.
+func (ps *Serializer) Shard(shard, shards int) []byte {
+	ps.headerWritten = true
+	var versionBuf [binary.MaxVarintLen32]byte
+	return versionBuf[:binary.PutUvarint(versionBuf[:], serializerVersion)]
+}
+
 func (ps *Serializer) writeHeader(w io.Writer) (int, error) {
 	if ps.headerWritten {
 		return 0, nil
@@ -174,3 +174,29 @@ func TestConvertToPromSeries(t *testing.T) {
 		})
 	}
 }
+
+func TestPrometheusSerializerShard(t *testing.T) {
+	var buffer bytes.Buffer
+	for shard := 0; shard < 2; shard++ {
+		ser := Serializer{}
+		header := ser.Shard(shard, 2)
+		if shard == 0 {
+			buffer.Write(header)
+		}
+		if err := ser.Serialize(serialize.TestPointDefault(), &buffer); err != nil {
+			t.Fatalf("error while serializing point: %v", err)
+		}
+	}
+	promIter, err := NewPrometheusIterator(bufio.NewReader(&buffer))
+	if err != nil {
+		t.Fatalf("error while creating iterator: %v", err)
+	}
+	count := 0
+	for promIter.HasNext() {
+		if _, err := promIter.Next(); err != nil {
+			t.Fatalf("error getting next: %v", err)
+		}
+		count++
+	}
+	assertEqual(2, count, t, "Wrong number of series")
+}
@@ -0,0 +1,19 @@
+package targets
+
+import (
+	"sync"
+
+	"github.com/blagojts/viper"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// QueryTarget is implemented by the targets that can also execute the queries
+// generated for them, so they can be queried while data is loaded into them
+type QueryTarget interface {
+	// QueryPool returns the pool of the queries read from a query file of the target
+	QueryPool() *sync.Pool
+	// QueryProcessor returns the function creating the query Processor of every worker.
+	// The processors connect to the database c.DBName with the target specific
+	// configuration in v, the same one used to load data.
+	QueryProcessor(c query.BenchmarkRunnerConfig, v *viper.Viper) (query.ProcessorCreate, error)
+}
@@ -75,3 +75,14 @@ type DataSource interface {
 	NextItem() data.LoadedPoint
 	Headers() *common.GeneratedDataHeaders
 }
+
+// SeekableDataSource is a DataSource reading a data file that knows the byte
+// offset of its next item and can seek to such an offset, so a resumed load
+// doesn't have to read the items loaded before again.
+type SeekableDataSource interface {
+	DataSource
+	// Offset returns the byte offset of the next item, or -1 if it's unknown
+	Offset() int64
+	// SeekTo makes the item at offset the next one
+	SeekTo(offset int64) error
+}
@@ -0,0 +1,73 @@
+package timescaledb
+
+import (
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// SQLSTATE codes and classes of the errors after which inserting the same rows
+// again may succeed
+const (
+	sqlStateConnectionClass      = "08"
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+	sqlStateResourcesClass       = "53"
+	sqlStateShutdownClass        = "57P"
+)
+
+// sqlState returns the SQLSTATE code of an error reported by the server through
+// pgx or lib/pq, or an empty string for any other error
+func sqlState(err error) string {
+	var pgErr interface{ SQLState() string }
+	if errors.As(err, &pgErr) {
+		return pgErr.SQLState()
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return string(pqErr.Code)
+	}
+	return ""
+}
+
+// isConnectionError tells whether err means that the connection to the
+// database is broken: a network error or a connection exception or shutdown
+// reported by the server.
+func isConnectionError(err error) bool {
+	if code := sqlState(err); code != "" {
+		return strings.HasPrefix(code, sqlStateConnectionClass) || strings.HasPrefix(code, sqlStateShutdownClass)
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, driver.ErrBadConn)
+}
+
+// isRetryableError tells whether inserting the same rows again may succeed after err.
+// Constraint, syntax or schema errors will fail the same way every time.
+func isRetryableError(err error) bool {
+	if isConnectionError(err) {
+		return true
+	}
+	code := sqlState(err)
+	return code == sqlStateSerializationFailure || code == sqlStateDeadlockDetected ||
+		strings.HasPrefix(code, sqlStateResourcesClass)
+}
+
+// classifyError marks err as retryable for the load runner when inserting the
+// rows again may succeed. A broken pgx connection is dropped, so the next
+// attempt connects again.
+func (p *processor) classifyError(err error) error {
+	if isConnectionError(err) || (p._pgxConn != nil && p._pgxConn.IsClosed()) {
+		p.dropPgxConn()
+		return targets.NewRetryableError(err)
+	}
+	if isRetryableError(err) {
+		return targets.NewRetryableError(err)
+	}
+	return err
+}
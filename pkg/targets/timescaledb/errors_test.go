@@ -0,0 +1,54 @@
+package timescaledb
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// testPgError reports a SQLSTATE the way pgx errors do
+type testPgError string
+
+func (e testPgError) Error() string    { return "pg error " + string(e) }
+func (e testPgError) SQLState() string { return string(e) }
+
+func TestIsRetryableError(t *testing.T) {
+	cases := []struct {
+		desc           string
+		err            error
+		wantConnection bool
+		wantRetryable  bool
+	}{
+		{desc: "connection failure", err: testPgError("08006"), wantConnection: true, wantRetryable: true},
+		{desc: "admin shutdown", err: testPgError("57P01"), wantConnection: true, wantRetryable: true},
+		{desc: "serialization failure", err: testPgError("40001"), wantRetryable: true},
+		{desc: "deadlock", err: testPgError("40P01"), wantRetryable: true},
+		{desc: "disk full", err: testPgError("53100"), wantRetryable: true},
+		{desc: "unique violation", err: testPgError("23505")},
+		{desc: "syntax error", err: testPgError("42601")},
+		{desc: "undefined table", err: &pq.Error{Code: "42P01"}},
+		{desc: "too many connections", err: &pq.Error{Code: "53300"}, wantRetryable: true},
+		{desc: "wrapped", err: fmt.Errorf("copy: %w", testPgError("08003")), wantConnection: true, wantRetryable: true},
+		{desc: "network", err: &net.OpError{Op: "read", Err: fmt.Errorf("connection reset")}, wantConnection: true, wantRetryable: true},
+		{desc: "eof", err: io.ErrUnexpectedEOF, wantConnection: true, wantRetryable: true},
+		{desc: "bad conn", err: driver.ErrBadConn, wantConnection: true, wantRetryable: true},
+		{desc: "other", err: fmt.Errorf("something else")},
+	}
+	for _, c := range cases {
+		if got := isConnectionError(c.err); got != c.wantConnection {
+			t.Errorf("%s: connection error: got %v want %v", c.desc, got, c.wantConnection)
+		}
+		if got := isRetryableError(c.err); got != c.wantRetryable {
+			t.Errorf("%s: retryable: got %v want %v", c.desc, got, c.wantRetryable)
+		}
+		p := &processor{}
+		if got := targets.IsRetryable(p.classifyError(c.err)); got != c.wantRetryable {
+			t.Errorf("%s: classified as retryable: got %v want %v", c.desc, got, c.wantRetryable)
+		}
+	}
+}
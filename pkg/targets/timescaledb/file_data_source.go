@@ -11,11 +11,12 @@ import (
 )
 
 func newFileDataSource(fileName string) targets.DataSource {
-	br := load.GetBufferedReader(fileName)
-	return &fileDataSource{scanner: bufio.NewScanner(br)}
+	file, scanner := load.OpenDataFile(fileName)
+	return &fileDataSource{file: file, scanner: scanner}
 }
 
 type fileDataSource struct {
+	file    *load.DataFile
 	scanner *bufio.Scanner
 	headers *common.GeneratedDataHeaders
 }
@@ -57,7 +58,7 @@ func (d *fileDataSource) Headers() *common.GeneratedDataHeaders {
 
 	tagsarr := strings.Split(tags, ",")
 	if tagsarr[0] != tagsKey {
-		fatal("input header in wrong format. got '%s', expected 'tags'", tags[0])
+		fatal("input header in wrong format. got '%s', expected 'tags'", tagsarr[0])
 	}
 	tagNames, tagTypes := extractTagNamesAndTypes(tagsarr[1:])
 	fieldKeys := make(map[string][]string)
@@ -113,3 +114,18 @@ func (d *fileDataSource) NextItem() data.LoadedPoint {
 		row:        newPoint,
 	})
 }
+
+// Offset returns the byte offset of the next item in the data file
+func (d *fileDataSource) Offset() int64 {
+	return d.file.Offset()
+}
+
+// SeekTo makes the item at offset in the data file the next one
+func (d *fileDataSource) SeekTo(offset int64) error {
+	scanner, err := d.file.SeekTo(offset)
+	if err != nil {
+		return err
+	}
+	d.scanner = scanner
+	return nil
+}
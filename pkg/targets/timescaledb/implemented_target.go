@@ -1,12 +1,15 @@
 package timescaledb
 
 import (
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/blagojts/viper"
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/pkg/data/serialize"
 	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/query"
 	"github.com/timescale/tsbs/pkg/targets"
 	"github.com/timescale/tsbs/pkg/targets/constants"
 )
@@ -36,6 +39,29 @@ func (t *timescaleTarget) Benchmark(
 	return NewBenchmark(targetDB, &loadingOptions, dataSourceConfig)
 }
 
+func (t *timescaleTarget) QueryPool() *sync.Pool {
+	return &query.TimescaleDBPool
+}
+
+func (t *timescaleTarget) QueryProcessor(c query.BenchmarkRunnerConfig, v *viper.Viper) (query.ProcessorCreate, error) {
+	var loadingOptions LoadingOptions
+	if err := v.Unmarshal(&loadingOptions); err != nil {
+		return nil, err
+	}
+	opts := &QueryOptions{
+		PostgresConnect: loadingOptions.PostgresConnect,
+		Hosts:           strings.Split(loadingOptions.Host, ","),
+		User:            loadingOptions.User,
+		Pass:            loadingOptions.Pass,
+		Port:            loadingOptions.Port,
+		DBName:          c.DBName,
+		ForceTextFormat: loadingOptions.ForceTextFormat,
+		Debug:           c.Debug > 0,
+		PrintResponses:  c.PrintResponses,
+	}
+	return func() query.Processor { return NewQueryProcessor(opts) }, nil
+}
+
 func (t *timescaleTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
 	flagSet.String(flagPrefix+"postgres", "sslmode=disable", "PostgreSQL connection string")
 	flagSet.String(flagPrefix+"host", "localhost", "Hostname of TimescaleDB (PostgreSQL) instance")
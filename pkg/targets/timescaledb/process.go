@@ -7,7 +7,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
-	"os"
 	"strconv"
 	"strings"
 	"sync"
@@ -56,7 +55,7 @@ func subsystemTagsToJSON(tags []string) map[string]interface{} {
 	return jsonToReturn
 }
 
-func (p *processor) insertTags(db *sql.DB, tagRows [][]string) map[string]int64 {
+func (p *processor) insertTags(db *sql.DB, tagRows [][]string) (map[string]int64, error) {
 	tagCols := tableCols[tagsKey]
 	cols := tagCols
 	values := make([]string, 0)
@@ -82,15 +81,18 @@ func (p *processor) insertTags(db *sql.DB, tagRows [][]string) map[string]int64
 			values = append(values, row)
 		}
 	}
-	tx := MustBegin(db)
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
 	defer tx.Commit()
 	res, err := tx.Query(fmt.Sprintf(insertTagsSQL, strings.Join(cols, ","), strings.Join(values, ",")))
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 
 	ret := p.sqlTagsToCacheLine(res, err, tagCols)
-	return ret
+	return ret, nil
 }
 
 func (p *processor) sqlTagsToCacheLine(res *sql.Rows, err error, tagCols []string) map[string]int64 {
@@ -183,7 +185,9 @@ func (p *processor) splitTagsAndMetrics(rows []*insertData, dataCols int) ([][]s
 	return tagRows, dataRows, numMetrics
 }
 
-func (p *processor) processCSI(hypertable string, rows []*insertData) uint64 {
+// processCSI inserts the rows into the hypertable. Nothing of the rows is committed
+// when an error occurs, so transient errors are returned as retryable.
+func (p *processor) processCSI(hypertable string, rows []*insertData) (uint64, error) {
 	colLen := len(tableCols[hypertable]) + numExtraCols
 	if p.opts.InTableTag {
 		colLen++
@@ -201,7 +205,11 @@ func (p *processor) processCSI(hypertable string, rows []*insertData) uint64 {
 	p._csi.mutex.RUnlock()
 	if len(newTags) > 0 {
 		p._csi.mutex.Lock()
-		res := p.insertTags(p._db, newTags)
+		res, err := p.insertTags(p._db, newTags)
+		if err != nil {
+			p._csi.mutex.Unlock()
+			return 0, p.classifyError(err)
+		}
 		for k, v := range res {
 			p._csi.m[k] = v
 		}
@@ -223,68 +231,94 @@ func (p *processor) processCSI(hypertable string, rows []*insertData) uint64 {
 	cols = append(cols, tableCols[hypertable]...)
 
 	if p.opts.ForceTextFormat {
-		tx := MustBegin(p._db)
-		stmt, err := tx.Prepare(pq.CopyIn(hypertable, cols...))
-		if err != nil {
-			panic(err)
+		if err := p.copyWithPQ(hypertable, cols, dataRows); err != nil {
+			return 0, p.classifyError(err)
 		}
-
-		for _, r := range dataRows {
-			stmt.Exec(r...)
+	} else if !p.opts.UseInsert {
+		if p._pgxConn == nil {
+			if err := p.acquirePgxConn(); err != nil {
+				return 0, p.classifyError(err)
+			}
 		}
-		_, err = stmt.Exec()
+		rows := pgx.CopyFromRows(dataRows)
+		inserted, err := p._pgxConn.CopyFrom(context.Background(), pgx.Identifier{hypertable}, cols, rows)
 		if err != nil {
-			panic(err)
+			return 0, p.classifyError(err)
 		}
 
-		err = stmt.Close()
-		if err != nil {
-			panic(err)
+		if inserted != int64(len(dataRows)) {
+			// not retryable, the rows of a partial copy would be inserted twice
+			return 0, fmt.Errorf("failed to insert all the data: expected %d rows, inserted %d", len(dataRows), inserted)
+		}
+	} else {
+		if err := p.batchInsert(hypertable, cols, dataRows); err != nil {
+			return 0, p.classifyError(err)
 		}
+	}
 
-		err = tx.Commit()
-		if err != nil {
-			panic(err)
+	return numMetrics, nil
+}
+
+// copyWithPQ copies the data rows into the hypertable using COPY in text format
+func (p *processor) copyWithPQ(hypertable string, cols []string, dataRows [][]interface{}) error {
+	tx, err := p._db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(pq.CopyIn(hypertable, cols...))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	for _, r := range dataRows {
+		if _, err = stmt.Exec(r...); err != nil {
+			tx.Rollback()
+			return err
 		}
-	} else {
-		if !p.opts.UseInsert {
-			rows := pgx.CopyFromRows(dataRows)
-			inserted, err := p._pgxConn.CopyFrom(context.Background(), pgx.Identifier{hypertable}, cols, rows)
+	}
+	_, err = stmt.Exec()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
 
-			if err != nil {
-				panic(err)
-			}
+	err = stmt.Close()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
 
-			if inserted != int64(len(dataRows)) {
-				fmt.Fprintf(os.Stderr, "Failed to insert all the data! Expected: %d, Got: %d", len(dataRows), inserted)
-				os.Exit(1)
-			}
-		} else {
-			tx := MustBegin(p._db)
-			var stmt *sql.Stmt
-			var err error
+	return tx.Commit()
+}
 
-			stmtString := genBatchInsertStmt(hypertable, cols, len(dataRows))
-			stmt, err = tx.Prepare(stmtString)
+// batchInsert inserts the data rows into the hypertable with a single multi-row INSERT
+func (p *processor) batchInsert(hypertable string, cols []string, dataRows [][]interface{}) error {
+	tx, err := p._db.Begin()
+	if err != nil {
+		return err
+	}
 
-			_, err = stmt.Exec(flatten(dataRows)...)
-			if err != nil {
-				panic(err)
-			}
+	stmtString := genBatchInsertStmt(hypertable, cols, len(dataRows))
+	stmt, err := tx.Prepare(stmtString)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
 
-			err = stmt.Close()
-			if err != nil {
-				panic(err)
-			}
+	_, err = stmt.Exec(flatten(dataRows)...)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
 
-			err = tx.Commit()
-			if err != nil {
-				panic(err)
-			}
-		}
+	err = stmt.Close()
+	if err != nil {
+		tx.Rollback()
+		return err
 	}
 
-	return numMetrics
+	return tx.Commit()
 }
 
 func newProcessor(opts *LoadingOptions, driver, dbName string) *processor {
@@ -346,15 +380,36 @@ func (p *processor) Init(_ int, doLoad, hashWorkers bool) {
 		p._csi = globalSyncCSI
 	}
 	if !p.opts.ForceTextFormat {
-		conn, err := stdlib.AcquireConn(p._db)
-		if err != nil {
+		if err := p.acquirePgxConn(); err != nil {
 			panic(err)
 		}
-		p._pgxConn = conn
 	}
 	p.loadExistingTagsInCache(p._db)
 }
 
+// acquirePgxConn takes the connection used for COPY out of the pool
+func (p *processor) acquirePgxConn() error {
+	conn, err := stdlib.AcquireConn(p._db)
+	if err != nil {
+		return err
+	}
+	p._pgxConn = conn
+	return nil
+}
+
+// dropPgxConn closes a broken connection used for COPY, the pool discards it
+// when it is released
+func (p *processor) dropPgxConn() {
+	if p._pgxConn == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	p._pgxConn.Close(ctx)
+	stdlib.ReleaseConn(p._db, p._pgxConn)
+	p._pgxConn = nil
+}
+
 func (p *processor) loadExistingTagsInCache(db *sql.DB) {
 	p._csi.mutex.Lock()
 	res, err := db.Query(getTagsSQL)
@@ -382,14 +437,28 @@ func (p *processor) Close(doLoad bool) {
 }
 
 func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64) {
+	metricCnt, rowCnt, err := p.ProcessBatchWithError(b, doLoad)
+	if err != nil {
+		panic(err)
+	}
+	return metricCnt, rowCnt
+}
+
+// ProcessBatchWithError inserts the batch one hypertable at a time. Hypertables that
+// were inserted are removed from the batch, so a failed batch can be retried
+// without duplicating already inserted rows.
+func (p *processor) ProcessBatchWithError(b targets.Batch, doLoad bool) (uint64, uint64, error) {
 	batches := b.(*hypertableArr)
 	rowCnt := 0
 	metricCnt := uint64(0)
 	for hypertable, rows := range batches.m {
-		rowCnt += len(rows)
 		if doLoad {
 			start := time.Now()
-			metricCnt += p.processCSI(hypertable, rows)
+			numMetrics, err := p.processCSI(hypertable, rows)
+			if err != nil {
+				return metricCnt, uint64(rowCnt), err
+			}
+			metricCnt += numMetrics
 
 			if p.opts.LogBatches {
 				now := time.Now()
@@ -398,11 +467,13 @@ func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64)
 				fmt.Printf("BATCH: batchsize %d row rate %f/sec (took %v)\n", batchSize, float64(batchSize)/float64(took.Seconds()), took)
 			}
 		}
+		rowCnt += len(rows)
+		delete(batches.m, hypertable)
+		batches.cnt -= uint(len(rows))
 	}
-	batches.m = map[string][]*insertData{}
-	batches.cnt = 0
-	return metricCnt, uint64(rowCnt)
+	return metricCnt, uint64(rowCnt), nil
 }
+
 func convertValsToSQLBasedOnType(values []string, types []string) []string {
 	return convertValsToBasedOnType(values, types, "'", "NULL")
 }
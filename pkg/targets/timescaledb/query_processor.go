@@ -0,0 +1,150 @@
+package timescaledb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// QueryOptions configure the processor executing TimescaleDB queries
+type QueryOptions struct {
+	// PostgresConnect are additional connection parameters, host, dbname and user in it are ignored
+	PostgresConnect string
+	// Hosts are assigned to the workers round robin, to shard reads on a multi-node setup
+	Hosts           []string
+	User            string
+	Pass            string
+	Port            string
+	DBName          string
+	ShowExplain     bool
+	ForceTextFormat bool
+	Debug           bool
+	PrintResponses  bool
+	// CaptureResult receives the rows returned by every query run cold, if not nil
+	CaptureResult func(q query.Query, rows [][]interface{})
+}
+
+// connectString returns the connection string of a worker. Each worker is
+// assigned a sequence number, used to evenly distribute the hosts to the workers.
+func (o *QueryOptions) connectString(workerNumber int) string {
+	opts := LoadingOptions{
+		PostgresConnect: o.PostgresConnect,
+		Host:            o.Hosts[workerNumber%len(o.Hosts)],
+		User:            o.User,
+		Pass:            o.Pass,
+		Port:            o.Port,
+		ForceTextFormat: o.ForceTextFormat,
+	}
+	return opts.GetConnectString(o.DBName)
+}
+
+type queryProcessor struct {
+	db   *sql.DB
+	opts *QueryOptions
+}
+
+// NewQueryProcessor returns a query.Processor executing TimescaleDB queries
+func NewQueryProcessor(opts *QueryOptions) query.Processor {
+	return &queryProcessor{opts: opts}
+}
+
+func (p *queryProcessor) Init(workerNumber int) {
+	db, err := sql.Open(getDriver(p.opts.ForceTextFormat), p.opts.connectString(workerNumber))
+	if err != nil {
+		panic(err)
+	}
+	p.db = db
+}
+
+// Close closes the connections opened by the worker
+func (p *queryProcessor) Close() {
+	p.db.Close()
+}
+
+func (p *queryProcessor) ProcessQuery(ctx context.Context, q query.Query, isWarm bool) ([]*query.Stat, error) {
+	// No need to run again for EXPLAIN
+	if isWarm && p.opts.ShowExplain {
+		return nil, nil
+	}
+	tq := q.(*query.TimescaleDB)
+
+	start := time.Now()
+	qry := string(tq.SqlQuery)
+	if p.opts.ShowExplain {
+		qry = "EXPLAIN ANALYZE " + qry
+	}
+	rows, err := p.db.QueryContext(ctx, qry)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.opts.Debug {
+		fmt.Println(qry)
+	}
+	if p.opts.ShowExplain {
+		text := ""
+		for rows.Next() {
+			var s string
+			if err2 := rows.Scan(&s); err2 != nil {
+				panic(err2)
+			}
+			text += s + "\n"
+		}
+		fmt.Printf("%s\n\n%s\n-----\n\n", qry, text)
+	} else if capture := p.opts.CaptureResult != nil && !isWarm; capture || p.opts.PrintResponses {
+		cols, err := rows.Columns()
+		if err != nil {
+			return nil, err
+		}
+		result, err := query.ScanRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		if p.opts.PrintResponses {
+			prettyPrintResponse(tq, cols, result)
+		}
+		if capture {
+			p.opts.CaptureResult(q, result)
+		}
+	}
+	// Fetching all the rows to confirm that the query is fully completed.
+	for rows.Next() {
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	took := float64(time.Since(start).Nanoseconds()) / 1e6
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), took)
+
+	return []*query.Stat{stat}, err
+}
+
+// prettyPrintResponse prints a Query and its response in JSON format with two
+// keys: 'query' which has a value of the SQL used to generate the second key
+// 'results' which is an array of each row in the return set.
+func prettyPrintResponse(q *query.TimescaleDB, cols []string, rows [][]interface{}) {
+	resp := make(map[string]interface{})
+	resp["query"] = string(q.SqlQuery)
+	results := []map[string]interface{}{}
+	for _, values := range rows {
+		row := make(map[string]interface{})
+		for i, column := range cols {
+			row[column] = values[i]
+		}
+		results = append(results, row)
+	}
+	resp["results"] = results
+
+	line, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(line) + "\n")
+}
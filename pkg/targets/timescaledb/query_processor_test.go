@@ -0,0 +1,47 @@
+package timescaledb
+
+import (
+	"testing"
+
+	"github.com/blagojts/viper"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+func TestQueryOptionsConnectString(t *testing.T) {
+	opts := &QueryOptions{
+		PostgresConnect: "host=foo sslmode=disable",
+		Hosts:           []string{"host1", "host2"},
+		User:            "postgres",
+		Port:            "5432",
+		DBName:          "benchmark",
+	}
+	want := []string{
+		"host=host1 dbname=benchmark user=postgres sslmode=disable port=5432",
+		"host=host2 dbname=benchmark user=postgres sslmode=disable port=5432",
+		"host=host1 dbname=benchmark user=postgres sslmode=disable port=5432",
+	}
+	for worker, w := range want {
+		if got := opts.connectString(worker); got != w {
+			t.Errorf("worker %d: incorrect connect string: got %s want %s", worker, got, w)
+		}
+	}
+}
+
+func TestTargetQueryProcessor(t *testing.T) {
+	target, ok := NewTarget().(targets.QueryTarget)
+	if !ok {
+		t.Fatal("timescaledb target should be able to run queries")
+	}
+	v := viper.New()
+	v.Set("host", "host1,host2")
+	v.Set("user", "joe")
+	createProcessor, err := target.QueryProcessor(query.BenchmarkRunnerConfig{DBName: "bench", Debug: 1}, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := createProcessor().(*queryProcessor)
+	if len(p.opts.Hosts) != 2 || p.opts.User != "joe" || p.opts.DBName != "bench" || !p.opts.Debug {
+		t.Errorf("query options not taken from the target config: %+v", p.opts)
+	}
+}
@@ -1,7 +1,6 @@
 package timestream
 
 import (
-	"bufio"
 	"fmt"
 	"github.com/aws/aws-sdk-go/service/timestreamwrite"
 	"github.com/pkg/errors"
@@ -89,9 +88,10 @@ func (b benchmark) GetDBCreator() targets.DBCreator {
 
 func initDataSource(config *source.DataSourceConfig, useCurrentTs bool) (targets.DataSource, error) {
 	if config.Type == source.FileDataSourceType {
-		br := load.GetBufferedReader(config.File.Location)
+		file, scanner := load.OpenDataFile(config.File.Location)
 		return &fileDataSource{
-			scanner:      bufio.NewScanner(br),
+			file:         file,
+			scanner:      scanner,
 			useCurrentTs: useCurrentTs,
 		}, nil
 	} else if config.Type == source.SimulatorDataSourceType {
@@ -3,6 +3,7 @@ package timestream
 import (
 	"bufio"
 	"fmt"
+	"github.com/timescale/tsbs/load"
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
 	"log"
@@ -17,6 +18,7 @@ const (
 
 type fileDataSource struct {
 	_headers     *common.GeneratedDataHeaders
+	file         *load.DataFile
 	scanner      *bufio.Scanner
 	useCurrentTs bool
 }
@@ -171,3 +173,18 @@ func fieldsLineToFieldValues(fieldsLine string) (time string, fieldValues []*str
 
 	return metrics[0], fieldValues
 }
+
+// Offset returns the byte offset of the next item in the data file
+func (f *fileDataSource) Offset() int64 {
+	return f.file.Offset()
+}
+
+// SeekTo makes the item at offset in the data file the next one
+func (f *fileDataSource) SeekTo(offset int64) error {
+	scanner, err := f.file.SeekTo(offset)
+	if err != nil {
+		return err
+	}
+	f.scanner = scanner
+	return nil
+}
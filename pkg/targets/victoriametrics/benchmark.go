@@ -1,7 +1,6 @@
 package victoriametrics
 
 import (
-	"bufio"
 	"bytes"
 	"errors"
 	"github.com/blagojts/viper"
@@ -13,6 +12,9 @@ import (
 
 type SpecificConfig struct {
 	ServerURLs []string `yaml:"urls" mapstructure:"urls"`
+	// QueryURLs are the ones the queries of a mixed workload are sent to,
+	// by default the ServerURLs without the /write path of a single node
+	QueryURLs []string `yaml:"query-urls" mapstructure:"query-urls"`
 }
 
 func parseSpecificConfig(v *viper.Viper) (*SpecificConfig, error) {
@@ -34,10 +36,11 @@ func NewBenchmark(vmSpecificConfig *SpecificConfig, dataSourceConfig *source.Dat
 		return nil, errors.New("only FILE data source type is supported for VictoriaMetrics")
 	}
 
-	br := load.GetBufferedReader(dataSourceConfig.File.Location)
+	file, scanner := load.OpenDataFile(dataSourceConfig.File.Location)
 	return &benchmark{
 		dataSource: &fileDataSource{
-			scanner: bufio.NewScanner(br),
+			file:    file,
+			scanner: scanner,
 		},
 		serverURLs: vmSpecificConfig.ServerURLs,
 	}, nil
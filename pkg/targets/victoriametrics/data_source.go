@@ -2,12 +2,14 @@ package victoriametrics
 
 import (
 	"bufio"
+	"github.com/timescale/tsbs/load"
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
 	"log"
 )
 
 type fileDataSource struct {
+	file    *load.DataFile
 	scanner *bufio.Scanner
 }
 
@@ -28,3 +30,18 @@ func (f fileDataSource) Headers() *common.GeneratedDataHeaders {
 type decoder struct {
 	scanner *bufio.Scanner
 }
+
+// Offset returns the byte offset of the next item in the data file
+func (f *fileDataSource) Offset() int64 {
+	return f.file.Offset()
+}
+
+// SeekTo makes the item at offset in the data file the next one
+func (f *fileDataSource) SeekTo(offset int64) error {
+	scanner, err := f.file.SeekTo(offset)
+	if err != nil {
+		return err
+	}
+	f.scanner = scanner
+	return nil
+}
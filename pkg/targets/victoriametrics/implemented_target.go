@@ -1,10 +1,13 @@
 package victoriametrics
 
 import (
+	"sync"
+
 	"github.com/blagojts/viper"
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/pkg/data/serialize"
 	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/query"
 	"github.com/timescale/tsbs/pkg/targets"
 	"github.com/timescale/tsbs/pkg/targets/constants"
 	"github.com/timescale/tsbs/pkg/targets/influx"
@@ -26,6 +29,26 @@ func (vm vmTarget) Benchmark(_ string, dataSourceConfig *source.DataSourceConfig
 	return NewBenchmark(vmSpecificConfig, dataSourceConfig)
 }
 
+func (vm vmTarget) QueryPool() *sync.Pool {
+	return &query.HTTPPool
+}
+
+func (vm vmTarget) QueryProcessor(c query.BenchmarkRunnerConfig, v *viper.Viper) (query.ProcessorCreate, error) {
+	vmSpecificConfig, err := parseSpecificConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	urls := vmSpecificConfig.QueryURLs
+	if len(urls) == 0 {
+		urls = queryURLs(vmSpecificConfig.ServerURLs)
+	}
+	opts := &QueryOptions{
+		URLs:           urls,
+		PrintResponses: c.PrintResponses,
+	}
+	return func() query.Processor { return NewQueryProcessor(opts) }, nil
+}
+
 func (vm vmTarget) Serializer() serialize.PointSerializer {
 	return &influx.Serializer{}
 }
@@ -36,6 +59,11 @@ func (vm vmTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet
 		"http://localhost:8428/write",
 		"Comma-separated list of VictoriaMetrics ingestion URLs(single-node or VMInsert)",
 	)
+	flagSet.String(
+		flagPrefix+"query-urls",
+		"",
+		"Comma-separated list of VictoriaMetrics URLs(single-node or VMSelect) to query in a mixed workload (default urls without /write)",
+	)
 }
 
 func (vm vmTarget) TargetName() string {
@@ -2,10 +2,13 @@ package victoriametrics
 
 import (
 	"bytes"
-	"github.com/timescale/tsbs/pkg/targets"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"time"
+
+	"github.com/timescale/tsbs/pkg/targets"
 )
 
 type processor struct {
@@ -17,32 +20,64 @@ func (p *processor) Init(workerNum int, doLoad, hashWorkers bool) {
 	p.url = p.vmURLs[workerNum%len(p.vmURLs)]
 }
 
+// ProcessBatch sends the batch until VictoriaMetrics accepts it, as it is used
+// when no retries or error budget are configured.
 func (p *processor) ProcessBatch(b targets.Batch, doLoad bool) (metricCount, rowCount uint64) {
 	batch := b.(*batch)
 	if !doLoad {
 		return batch.metrics, batch.rows
 	}
-	mc, rc := p.do(batch)
-	return mc, rc
-}
-
-func (p *processor) do(b *batch) (uint64, uint64) {
 	for {
-		r := bytes.NewReader(b.buf.Bytes())
-		req, err := http.NewRequest("POST", p.url, r)
-		if err != nil {
-			log.Fatalf("error while creating new request: %s", err)
-		}
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			log.Fatalf("error while executing request: %s", err)
+		mc, rc, err := p.do(batch)
+		if err == nil {
+			return mc, rc
 		}
-		resp.Body.Close()
-		if resp.StatusCode == http.StatusNoContent {
-			b.buf.Reset()
-			return b.metrics, b.rows
+		var statusErr *statusError
+		if !errors.As(err, &statusErr) {
+			log.Fatal(err)
 		}
-		log.Printf("server returned HTTP status %d. Retrying", resp.StatusCode)
+		log.Printf("%s. Retrying", err)
 		time.Sleep(time.Millisecond * 10)
 	}
 }
+
+func (p *processor) ProcessBatchWithError(b targets.Batch, doLoad bool) (metricCount, rowCount uint64, err error) {
+	batch := b.(*batch)
+	if !doLoad {
+		return batch.metrics, batch.rows, nil
+	}
+	return p.do(batch)
+}
+
+// do sends the batch to VictoriaMetrics. Network errors and responses with
+// a 5xx or 429 status are reported as retryable, the batch is kept intact for them.
+func (p *processor) do(b *batch) (uint64, uint64, error) {
+	r := bytes.NewReader(b.buf.Bytes())
+	req, err := http.NewRequest("POST", p.url, r)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error while creating new request: %s", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, 0, targets.NewRetryableError(fmt.Errorf("error while executing request: %s", err))
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusNoContent {
+		b.buf.Reset()
+		return b.metrics, b.rows, nil
+	}
+	err = &statusError{code: resp.StatusCode}
+	if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+		return 0, 0, targets.NewRetryableError(err)
+	}
+	return 0, 0, err
+}
+
+// statusError is the error of a request VictoriaMetrics did not accept
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("server returned HTTP status %d", e.code)
+}
@@ -0,0 +1,108 @@
+package victoriametrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// QueryOptions configure the processor executing VictoriaMetrics queries
+type QueryOptions struct {
+	// URLs are assigned to the workers round robin (single-node or VMSelect)
+	URLs           []string
+	PrintResponses bool
+	// CaptureResult receives the rows returned by every query run cold, if not nil
+	CaptureResult func(q query.Query, rows [][]interface{})
+}
+
+// queryURLs returns the URLs to query a single-node VictoriaMetrics with from
+// the URLs data is written to
+func queryURLs(writeURLs []string) []string {
+	urls := make([]string, len(writeURLs))
+	for i, u := range writeURLs {
+		urls[i] = strings.TrimSuffix(strings.TrimSuffix(u, "/"), "/write")
+	}
+	return urls
+}
+
+type queryProcessor struct {
+	url  string
+	opts *QueryOptions
+}
+
+// NewQueryProcessor returns a query.Processor executing VictoriaMetrics queries
+func NewQueryProcessor(opts *QueryOptions) query.Processor {
+	return &queryProcessor{opts: opts}
+}
+
+func (p *queryProcessor) Init(workerNum int) {
+	p.url = p.opts.URLs[workerNum%len(p.opts.URLs)]
+}
+
+// Close closes the idle connections left by the worker
+func (p *queryProcessor) Close() {
+	http.DefaultClient.CloseIdleConnections()
+}
+
+func (p *queryProcessor) ProcessQuery(ctx context.Context, q query.Query, isWarm bool) ([]*query.Stat, error) {
+	hq := q.(*query.HTTP)
+	lag, body, err := p.do(ctx, hq)
+	if err != nil {
+		return nil, err
+	}
+	if p.opts.CaptureResult != nil && !isWarm {
+		rows, err := responseRows(body)
+		if err != nil {
+			return nil, err
+		}
+		p.opts.CaptureResult(q, rows)
+	}
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), lag)
+	return []*query.Stat{stat}, nil
+}
+
+func (p *queryProcessor) do(ctx context.Context, q *query.HTTP) (float64, []byte, error) {
+	// populate a request with data from the Query:
+	req, err := http.NewRequestWithContext(ctx, string(q.Method), p.url+string(q.Path), nil)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error while creating request: %s", err)
+	}
+
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("query execution error: %s", err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error while reading response body: %s", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, nil, fmt.Errorf("non-200 statuscode received: %d; Body: %s", resp.StatusCode, string(body))
+	}
+	lag := float64(time.Since(start).Nanoseconds()) / 1e6 // milliseconds
+
+	// Pretty print JSON responses, if applicable:
+	if p.opts.PrintResponses {
+		var pretty bytes.Buffer
+		prefix := fmt.Sprintf("ID %d: ", q.GetID())
+		if err := json.Indent(&pretty, body, prefix, "  "); err != nil {
+			return lag, nil, err
+		}
+		_, err = fmt.Fprintf(os.Stderr, "%s%s\n", prefix, pretty.Bytes())
+		if err != nil {
+			return lag, nil, err
+		}
+	}
+	return lag, body, nil
+}
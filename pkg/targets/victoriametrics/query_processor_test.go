@@ -0,0 +1,50 @@
+package victoriametrics
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/blagojts/viper"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+func TestTargetQueryProcessor(t *testing.T) {
+	target, ok := NewTarget().(targets.QueryTarget)
+	if !ok {
+		t.Fatal("victoriametrics target should be able to run queries")
+	}
+	cases := []struct {
+		desc      string
+		urls      string
+		queryURLs string
+		want      []string
+	}{
+		{
+			desc: "single node write urls",
+			urls: "http://host1:8428/write,http://host2:8428/write/",
+			want: []string{"http://host1:8428", "http://host2:8428"},
+		},
+		{
+			desc:      "vmselect urls",
+			urls:      "http://vminsert:8480/insert/0/influx/write",
+			queryURLs: "http://vmselect:8481/select/0/prometheus",
+			want:      []string{"http://vmselect:8481/select/0/prometheus"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			v := viper.New()
+			v.Set("urls", c.urls)
+			v.Set("query-urls", c.queryURLs)
+			createProcessor, err := target.QueryProcessor(query.BenchmarkRunnerConfig{DBName: "bench"}, v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := createProcessor().(*queryProcessor)
+			if !reflect.DeepEqual(p.opts.URLs, c.want) {
+				t.Errorf("wrong query urls: got %v want %v", p.opts.URLs, c.want)
+			}
+		})
+	}
+}
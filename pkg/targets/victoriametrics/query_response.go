@@ -0,0 +1,63 @@
+package victoriametrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// response is a response of the Prometheus querying API
+type response struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		Result []struct {
+			Metric map[string]string `json:"metric"`
+			// Values are the samples of a range query, Value the one of an instant query
+			Values [][]interface{} `json:"values"`
+			Value  []interface{}   `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// responseRows returns a row per sample in the response body, starting with
+// the label values of its series sorted by label name, then the time and the value
+func responseRows(body []byte) ([][]interface{}, error) {
+	var resp response
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("invalid response: %v", err)
+	}
+	if resp.Status != "success" {
+		return nil, fmt.Errorf("query error: %s", resp.Error)
+	}
+	var rows [][]interface{}
+	for _, series := range resp.Data.Result {
+		names := make([]string, 0, len(series.Metric))
+		for name := range series.Metric {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		samples := series.Values
+		if series.Value != nil {
+			samples = append(samples, series.Value)
+		}
+		for _, sample := range samples {
+			if len(sample) != 2 {
+				return nil, fmt.Errorf("invalid sample %v", sample)
+			}
+			row := make([]interface{}, 0, len(names)+2)
+			for _, name := range names {
+				row = append(row, series.Metric[name])
+			}
+			ts, ok := sample[0].(float64)
+			if !ok {
+				return nil, fmt.Errorf("invalid sample time %v", sample[0])
+			}
+			sec, frac := math.Modf(ts)
+			rows = append(rows, append(row, time.Unix(int64(sec), int64(frac*1e9)), sample[1]))
+		}
+	}
+	return rows, nil
+}